@@ -6,10 +6,12 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/victor8titov/rest-api-notes/internal/adaptor"
+	"github.com/victor8titov/rest-api-notes/internal/config"
 	"github.com/victor8titov/rest-api-notes/internal/service/http"
 	"go.uber.org/zap"
 )
@@ -17,32 +19,89 @@ import (
 func main() {
 	ctx := context.Background()
 
-	diContainer, err := adaptor.NewDIContainer()
+	cfg, args, err := config.Load(os.Args[1:])
 	if err != nil {
-		log.Fatal("server", err)
+		log.Fatal("config: ", err)
 	}
-	defer diContainer.Close()
 
-	httpService := http.NewService(diContainer)
-	log.Println("started server")
-	err = httpService.ListenAndServe(3000)
+	diContainer, err := adaptor.NewDIContainer(cfg)
 	if err != nil {
-		log.Fatal("server", zap.Error(err))
+		log.Fatal("server", err)
+	}
+
+	if len(args) > 0 && args[0] == "migrate" {
+		err = runMigrate(ctx, diContainer, args[1:])
+		diContainer.Close()
+		if err != nil {
+			log.Fatal("migrate: ", err)
+		}
+		return
+	}
+
+	if cfg.AutoMigrate && cfg.Database.Driver == config.DriverPostgres {
+		err = runMigrate(ctx, diContainer, []string{"up"})
+		if err != nil {
+			diContainer.Close()
+			log.Fatal("migrate: ", err)
+		}
+	}
+
+	// Фоновые задачи должны завершиться до того, как Shutdown закроет пул БД.
+	purgeCtx, cancelPurge := context.WithCancel(ctx)
+	var purgers sync.WaitGroup
+	runPurger := func(run func(context.Context)) {
+		purgers.Add(1)
+		go func() {
+			defer purgers.Done()
+			run(purgeCtx)
+		}()
+	}
+	stopPurge := func() {
+		cancelPurge()
+		purgers.Wait()
+	}
+	if cfg.Trash.Retention.Duration > 0 {
+		runPurger(diContainer.GetTrashPurger(purgeCtx).Run)
 	}
+	runPurger(diContainer.GetIdempotencyPurger(purgeCtx).Run)
+
+	httpService := http.NewService(diContainer, cfg.HTTP)
 
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 
-	<-signals
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Println("started server")
+		serveErr <- httpService.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
+		stopPurge()
+		diContainer.Close()
+		log.Fatal("server", zap.Error(err))
+	case <-signals:
+	}
+
 	log.Println("stopping service")
+	httpService.SetNotReady()
+	time.Sleep(cfg.HTTP.ShutdownDelay.Duration)
 
-	const stopTimeout = 30 * time.Second
-	ctx, cancel := context.WithTimeout(ctx, stopTimeout)
+	ctx, cancel := context.WithTimeout(ctx, cfg.HTTP.ShutdownTimeout.Duration)
+	defer cancel()
 	go func() {
 		<-signals
 		log.Println("force stopping service")
 		cancel()
 	}()
 
+	stopPurge()
+	err = httpService.Shutdown(ctx)
+	if err != nil {
+		log.Println("stopped with error:", err)
+		return
+	}
+
 	log.Println("stopped")
 }
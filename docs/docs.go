@@ -22,54 +22,58 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/note": {
-            "get": {
-                "description": "Getting list with pagination.",
+        "/auth/login": {
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Getting list of notes.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get access token.",
                 "parameters": [
                     {
-                        "description": "params for pagination",
-                        "name": "pagination",
+                        "description": "email and password",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.RequestListNotes"
+                            "$ref": "#/definitions/users.LoginArgs"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "ok",
+                        "description": "Access token",
                         "schema": {
-                            "$ref": "#/definitions/note.ListNotes"
+                            "$ref": "#/definitions/user.Token"
                         }
                     },
                     "400": {
                         "description": "invalid request params",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
-                    "404": {
-                        "description": "not found",
+                    "401": {
+                        "description": "invalid credentials",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "failed during inner process",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/register": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -77,114 +81,267 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create note.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register new user.",
                 "parameters": [
                     {
-                        "description": "fields for new note",
-                        "name": "note",
+                        "description": "email and password, at least 8 characters",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/notes.CreateArgs"
+                            "$ref": "#/definitions/users.RegisterArgs"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Ok",
+                    "201": {
+                        "description": "Created user",
                         "schema": {
-                            "$ref": "#/definitions/note.Note"
+                            "$ref": "#/definitions/user.User"
                         }
                     },
                     "400": {
                         "description": "invalid request params",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
-                    "404": {
-                        "description": "not found",
+                    "409": {
+                        "description": "email is taken",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "failed during inner process",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             }
         },
-        "/note/{noteID}": {
+        "/note": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Getting list with offset or cursor pagination. Pass nextCursor or prevCursor from the previous page as cursor.\nPinned notes go first with any sort order, archived notes are listed only with archived=true.\nTag filters accept repeated parameters or comma separated values.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get note by ID.",
+                "summary": "Getting list of notes.",
                 "parameters": [
                     {
+                        "enum": [
+                            "label",
+                            "created_at",
+                            "updated_at"
+                        ],
                         "type": "string",
-                        "description": "ID of note that you want getting",
-                        "name": "noteID",
-                        "in": "path",
-                        "required": true
+                        "default": "label",
+                        "description": "sort field",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "sort direction",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "page size, capped by pagination.maxLimit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "number of notes to skip, can not be combined with cursor",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the neighbour page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count total number of notes, default true without cursor",
+                        "name": "withTotal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "notes having all of the tags",
+                        "name": "tagsAll",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "notes having any of the tags",
+                        "name": "tagsAny",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "notes having none of the tags",
+                        "name": "tagsNone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "notes shared with the current user instead of own notes",
+                        "name": "shared",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "notes updated at or after the time, RFC 3339",
+                        "name": "modifiedSince",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "notes created before the time, RFC 3339",
+                        "name": "createdBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "notes of the notebook",
+                        "name": "notebookId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "with notebookId, include notes of nested notebooks",
+                        "name": "recursive",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only pinned or only not pinned notes",
+                        "name": "pinned",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "archived notes instead of the rest",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only favorite or only not favorite notes",
+                        "name": "favorite",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Ok",
+                        "description": "ok",
                         "schema": {
-                            "$ref": "#/definitions/note.Note"
+                            "$ref": "#/definitions/note.ListNotes"
                         }
                     },
                     "400": {
                         "description": "invalid request params",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "404": {
-                        "description": "not found",
+                        "description": "notebook not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "failed during inner process",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Update note.",
+                "summary": "Create note.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID of note that you want updating",
-                        "name": "noteID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "fields for updating note",
-                        "name": "fields",
+                        "description": "fields for new note",
+                        "name": "note",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.RequestUpdateNote"
+                            "$ref": "#/definitions/notes.CreateArgs"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Updated note",
+                        "description": "Ok",
                         "schema": {
                             "$ref": "#/definitions/note.Note"
                         }
@@ -192,32 +349,73 @@ const docTemplate = `{
                     "400": {
                         "description": "invalid request params",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "404": {
-                        "description": "not found",
+                        "description": "notebook not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "note already exists or request with this key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "body with idempotency key is too large",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "idempotency key reused with another request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "failed during inner process",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             },
             "delete": {
-                "summary": "Delete note by ID.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves several notes to the trash at once, unknown IDs are skipped. Each note needs the version from its ETag; if any of them was modified, nothing is deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Delete notes by IDs.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID of note that you want to delete",
-                        "name": "noteID",
-                        "in": "path",
-                        "required": true
+                        "description": "notes that you want to delete with their versions",
+                        "name": "notes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.DeleteRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -230,94 +428,2971 @@ const docTemplate = `{
                     "400": {
                         "description": "invalid request params",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
-                    "404": {
-                        "description": "not found",
+                    "401": {
+                        "description": "unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope or note permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "note was modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
                         }
                     },
                     "500": {
                         "description": "failed during inner process",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/http.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "http.RequestListNotes": {
-            "type": "object",
-            "properties": {
-                "direction": {
-                    "type": "integer"
-                },
-                "limit": {
-                    "type": "integer"
-                },
-                "offset": {
-                    "type": "integer"
-                },
-                "sortBy": {
-                    "type": "string"
-                }
-            }
         },
-        "http.RequestUpdateNote": {
-            "type": "object",
-            "properties": {
-                "body": {
-                    "type": "string"
-                },
-                "label": {
-                    "type": "string"
-                },
-                "tags": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        "/note/batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
                     }
-                }
-            }
-        },
-        "note.ListNotes": {
-            "type": "object",
-            "properties": {
-                "notes": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/note.Note"
+                ],
+                "description": "Runs create, update, delete, tag-add and tag-remove operations in one transaction. By default nothing is saved if any operation fails; with continueOnError the successful operations are saved. Every operation gets its own status and error in results.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Apply several note operations at once.",
+                "parameters": [
+                    {
+                        "description": "Operations applied in order",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.BatchNotesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Outcome of every operation",
+                        "schema": {
+                            "$ref": "#/definitions/http.BatchNotesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "empty batch, too many operations or invalid body",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "request with this idempotency key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "body with idempotency key is too large",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "idempotency key reused with another request",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
                     }
-                },
-                "total": {
-                    "type": "integer"
                 }
             }
         },
-        "note.Note": {
+        "/note/move": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves several own notes at once, notebookId null moves them to the root. Unknown IDs and notes already in the notebook are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Move notes to a notebook.",
+                "parameters": [
+                    {
+                        "description": "IDs of notes and the target notebook",
+                        "name": "move",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.MoveNotesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/http.MoveNotesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope or note permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "notebook not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/note/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Searching by label and body, results are ordered by relevance. Snippet is HTML: note text is escaped, matches are wrapped in \u003cb\u003e.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Full-text search of notes.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "search query, supports quoted phrases, OR and -exclusion",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "page size, capped by pagination.maxLimit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/note.SearchNotes"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/note/{noteID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the note version in ETag and the time of the last change in Last-Modified.\nWith matching If-None-Match responds 304 without body.\nWith render=html the body is rendered from Markdown (CommonMark and GFM) to sanitized HTML with a table of contents.\nAccept: text/html returns only the rendered HTML.",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "summary": "Get note by ID.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note that you want getting",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "html"
+                        ],
+                        "type": "string",
+                        "description": "render body to HTML",
+                        "name": "render",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the cached note",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok, html and toc only with render=html",
+                        "schema": {
+                            "$ref": "#/definitions/note.RenderedNote"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requires If-Match with the ETag from the last read, * skips the version check.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update note.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note that you want updating",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the note",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "fields for updating note",
+                        "name": "fields",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RequestUpdateNote"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated note",
+                        "schema": {
+                            "$ref": "#/definitions/note.Note"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope or note permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "note was modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves the note to the trash. Requires If-Match with the ETag from the last read, * skips the version check.",
+                "summary": "Delete note by ID.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note that you want to delete",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the note",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope or note permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "note was modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes only the given fields. Accepts a merge patch (RFC 7396) or a JSON Patch (RFC 6902) over {label, body, tags}. Requires If-Match, * skips the version check.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Patch note.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note that you want patching",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the note",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "merge patch or array of JSON Patch operations",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RequestPatchNote"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Patched note",
+                        "schema": {
+                            "$ref": "#/definitions/note.Note"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid patch or patched note",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope or note permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "note was modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "JSON Patch can not be applied",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/note/{noteID}/link": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get public link to note.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/http.NoteLink"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "not the owner of the note",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only the owner can create a link. A new link replaces the previous one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create public read-only link to note.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "optional expiry",
+                        "name": "link",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/notes.CreateLinkArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/http.NoteLink"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "not the owner of the note",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "Revoke public link to note.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "not the owner of the note",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/note/{noteID}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Every create and update of the note stores a revision, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List note revisions.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/note.Revision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/note/{noteID}/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compares label, tags and body line by line. Empty body if revisions are equal.",
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Unified diff between two revisions.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "old revision",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "new revision",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "revisions are too large to compare",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/note/{noteID}/revisions/{rev}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Copies the old revision into the note and stores it as a new revision.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Restore note from revision.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision to restore",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/note.Note"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope or note permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/note/{noteID}/shares": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List users the note is shared with.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/note.Share"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "not the owner of the note",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only the owner can share a note. Sharing again with the same user changes the permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Share note with another user.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "email of the user and permission",
+                        "name": "share",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/notes.ShareArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/note.Share"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "not the owner of the note",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/note/{noteID}/shares/{userID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The owner can revoke any share, a user can remove only their own access.",
+                "summary": "Revoke access to note.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user to revoke access from",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "not the owner of the note",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/note/{noteID}/{flag}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only the owner can change flags. The version of the note grows, the revision history does not. Requires If-Match with the ETag from the last read, * skips the version check.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Pin, archive or add note to favorites.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pinned",
+                            "archived",
+                            "favorite"
+                        ],
+                        "type": "string",
+                        "description": "flag of note",
+                        "name": "flag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the note",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/note.Note"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope or not the owner",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "note was modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only the owner can change flags. The version of the note grows, the revision history does not. Requires If-Match with the ETag from the last read, * skips the version check.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Unpin, unarchive or remove note from favorites.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pinned",
+                            "archived",
+                            "favorite"
+                        ],
+                        "type": "string",
+                        "description": "flag of note",
+                        "name": "flag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the note",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/note.Note"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope or not the owner",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "note was modified",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/notebooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "All notebooks of the user ordered by name, the tree is built by parentId.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notebooks"
+                ],
+                "summary": "Getting list of notebooks.",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/note.Notebook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notebooks"
+                ],
+                "summary": "Create notebook.",
+                "parameters": [
+                    {
+                        "description": "name and optional parent",
+                        "name": "notebook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RequestNotebook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/note.Notebook"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "parent notebook not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/notebooks/{notebookID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notebooks"
+                ],
+                "summary": "Get notebook.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of notebook",
+                        "name": "notebookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/note.Notebook"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "notebook not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "parentId null moves the notebook to the root. A notebook can not be moved inside itself.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notebooks"
+                ],
+                "summary": "Rename or move notebook.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of notebook",
+                        "name": "notebookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new name and parent",
+                        "name": "notebook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RequestNotebook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/note.Notebook"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params or cycle",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "notebook not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "notes=move (default) moves nested notebooks and notes to the parent of the deleted notebook.\nnotes=trash deletes nested notebooks too and moves all their notes to the trash.",
+                "tags": [
+                    "notebooks"
+                ],
+                "summary": "Delete notebook.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of notebook",
+                        "name": "notebookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "move",
+                            "trash"
+                        ],
+                        "type": "string",
+                        "default": "move",
+                        "description": "what to do with the content",
+                        "name": "notes",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "notebook not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/{token}": {
+            "get": {
+                "description": "No authentication. Returns HTML for browsers (Accept: text/html) and JSON otherwise.",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Open note by public link.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "link token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "html"
+                        ],
+                        "type": "string",
+                        "description": "response format, overrides Accept",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/http.PublicNote"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "link not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List of all tags with number of notes.",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/note.Tag"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{tag}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "Remove tag from all notes.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tag name",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success deleting"
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{tag}/rename": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "If a note already has the new tag, the old one is removed without duplicating.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Rename tag in all notes.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "current tag name",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new tag name",
+                        "name": "fields",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RequestRenameTag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "renamed tag and number of changed notes",
+                        "schema": {
+                            "$ref": "#/definitions/note.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "List API tokens.",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/user.APIToken"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requires scope tokens:manage, which API tokens never have. The token is returned only once. Scopes default to the scopes of the current token and cannot exceed them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Create API token.",
+                "parameters": [
+                    {
+                        "description": "name, scopes and optional expiry",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/tokens.CreateArgs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created token",
+                        "schema": {
+                            "$ref": "#/definitions/user.IssuedAPIToken"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope or scope not granted",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens/{tokenID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Revoke API token.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of token",
+                        "name": "tokenID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Accepts the same parameters as the list of notes except shared. Notes are purged after trash.retention.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Getting list of notes in the trash.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "label",
+                            "created_at",
+                            "updated_at"
+                        ],
+                        "type": "string",
+                        "default": "label",
+                        "description": "sort field",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "sort direction",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "page size, capped by pagination.maxLimit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "number of notes to skip, can not be combined with cursor",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the neighbour page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count total number of notes, default true without cursor",
+                        "name": "withTotal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "notes having all of the tags",
+                        "name": "tagsAll",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "notes having any of the tags",
+                        "name": "tagsAny",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "notes having none of the tags",
+                        "name": "tagsNone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "notes updated at or after the time, RFC 3339",
+                        "name": "modifiedSince",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "notes created before the time, RFC 3339",
+                        "name": "createdBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "notes of the notebook",
+                        "name": "notebookId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "with notebookId, include notes of nested notebooks",
+                        "name": "recursive",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only pinned or only not pinned notes",
+                        "name": "pinned",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only archived or only not archived notes",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only favorite or only not favorite notes",
+                        "name": "favorite",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/note.ListNotes"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "notebook not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/{noteID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only notes in the trash can be purged. The note can not be restored afterwards.",
+                "summary": "Delete note permanently.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note in the trash",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "note is not in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/{noteID}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Shares, public link and revision history come back together with the note.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Restore note from the trash.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of note in the trash",
+                        "name": "noteID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/note.Note"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request params",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "note is not in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "failed during inner process",
+                        "schema": {
+                            "$ref": "#/definitions/http.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apperr.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.BatchNotesRequest": {
+            "type": "object",
+            "properties": {
+                "continueOnError": {
+                    "description": "ContinueOnError сохранить удавшиеся операции, даже если другие не удались.",
+                    "type": "boolean"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notes.BatchOperation"
+                    }
+                }
+            }
+        },
+        "http.BatchNotesResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "description": "Committed изменения сохранены. Без continueOnError false означает, что\nне сохранено ничего.",
+                    "type": "boolean"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.BatchResultResponse"
+                    }
+                }
+            }
+        },
+        "http.BatchResultResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/http.Problem"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "note": {
+                    "$ref": "#/definitions/note.Note"
+                },
+                "op": {
+                    "$ref": "#/definitions/notes.BatchOp"
+                },
+                "status": {
+                    "description": "Status HTTP-статус, который получила бы операция отдельным запросом.",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.DeleteRequest": {
+            "type": "object",
+            "properties": {
+                "notes": {
+                    "description": "Notes заметки с версиями из их ETag, версия обязательна, как If-Match.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notes.DeleteTarget"
+                    }
+                }
+            }
+        },
+        "http.MoveNotesRequest": {
+            "type": "object",
+            "properties": {
+                "noteId": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "notebookId": {
+                    "description": "NotebookID блокнот назначения, null переносит заметки в корень.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.MoveNotesResponse": {
+            "type": "object",
+            "properties": {
+                "moved": {
+                    "description": "Moved число заметок, которые сменили блокнот.",
+                    "type": "integer"
+                }
+            }
+        },
+        "http.NoteLink": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "noteId": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "invalidParams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperr.FieldError"
+                    }
+                },
+                "requestId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.PublicNote": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "http.RequestNotebook": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "parentId": {
+                    "description": "ParentID родительский блокнот, без него блокнот лежит в корне.",
+                    "type": "string"
+                }
+            }
+        },
+        "http.RequestPatchNote": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "http.RequestRenameTag": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.RequestUpdateNote": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "note.FoundNote": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "description": "Archived архивные заметки по умолчанию не попадают в список.",
+                    "type": "boolean"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt время переноса в корзину, только у удалённых заметок.",
+                    "type": "string"
+                },
+                "favorite": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "notebookId": {
+                    "description": "NotebookID блокнот заметки, nil - заметка лежит в корне.",
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "string"
+                },
+                "pinned": {
+                    "description": "Pinned закреплённые заметки идут в списке первыми при любой сортировке.",
+                    "type": "boolean"
+                },
+                "rank": {
+                    "description": "Rank релевантность, чем больше, тем выше в выдаче.",
+                    "type": "number"
+                },
+                "snippet": {
+                    "description": "Snippet фрагмент текста в виде HTML: текст экранирован, совпадения выделены тегом \u003cb\u003e.",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "description": "UpdatedAt время последнего изменения, у новой заметки равно CreatedAt.",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version растёт на единицу при каждом изменении, новая заметка получает 1.",
+                    "type": "integer"
+                }
+            }
+        },
+        "note.Heading": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID якорь заголовка в HTML, на него ссылается #ID.",
+                    "type": "string"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "note.ListNotes": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "description": "NextCursor и PrevCursor курсоры соседних страниц, пустые на краях списка.",
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/note.Note"
+                    }
+                },
+                "prevCursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total общее число заметок, только если его запросили.",
+                    "type": "integer"
+                }
+            }
+        },
+        "note.Note": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "description": "Archived архивные заметки по умолчанию не попадают в список.",
+                    "type": "boolean"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt время переноса в корзину, только у удалённых заметок.",
+                    "type": "string"
+                },
+                "favorite": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "notebookId": {
+                    "description": "NotebookID блокнот заметки, nil - заметка лежит в корне.",
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "string"
+                },
+                "pinned": {
+                    "description": "Pinned закреплённые заметки идут в списке первыми при любой сортировке.",
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "description": "UpdatedAt время последнего изменения, у новой заметки равно CreatedAt.",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version растёт на единицу при каждом изменении, новая заметка получает 1.",
+                    "type": "integer"
+                }
+            }
+        },
+        "note.Notebook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "string"
+                },
+                "parentId": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "note.Permission": {
+            "type": "string",
+            "enum": [
+                "read",
+                "write",
+                "owner"
+            ],
+            "x-enum-varnames": [
+                "PermissionRead",
+                "PermissionWrite",
+                "PermissionOwner"
+            ]
+        },
+        "note.RenderedNote": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "description": "Archived архивные заметки по умолчанию не попадают в список.",
+                    "type": "boolean"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt время переноса в корзину, только у удалённых заметок.",
+                    "type": "string"
+                },
+                "favorite": {
+                    "type": "boolean"
+                },
+                "html": {
+                    "description": "HTML текст заметки после очистки от опасной разметки.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "notebookId": {
+                    "description": "NotebookID блокнот заметки, nil - заметка лежит в корне.",
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "string"
+                },
+                "pinned": {
+                    "description": "Pinned закреплённые заметки идут в списке первыми при любой сортировке.",
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "toc": {
+                    "description": "TOC оглавление: заголовки текста по порядку.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/note.Heading"
+                    }
+                },
+                "updated_at": {
+                    "description": "UpdatedAt время последнего изменения, у новой заметки равно CreatedAt.",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version растёт на единицу при каждом изменении, новая заметка получает 1.",
+                    "type": "integer"
+                }
+            }
+        },
+        "note.Revision": {
             "type": "object",
             "properties": {
+                "authorEmail": {
+                    "description": "AuthorEmail заполняется при выдаче списка ревизий.",
+                    "type": "string"
+                },
+                "authorId": {
+                    "type": "string"
+                },
                 "body": {
                     "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
+                "label": {
+                    "type": "string"
+                },
+                "noteId": {
+                    "type": "string"
+                },
+                "rev": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "note.SearchNotes": {
+            "type": "object",
+            "properties": {
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/note.FoundNote"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "note.Share": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "noteId": {
+                    "type": "string"
+                },
+                "permission": {
+                    "$ref": "#/definitions/note.Permission"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "note.Tag": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "notes.BatchOp": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "tag-add",
+                "tag-remove"
+            ],
+            "x-enum-varnames": [
+                "BatchOpCreate",
+                "BatchOpUpdate",
+                "BatchOpDelete",
+                "BatchOpAddTags",
+                "BatchOpRemoveTags"
+            ]
+        },
+        "notes.BatchOperation": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
                 "label": {
                     "type": "string"
                 },
+                "notebookId": {
+                    "type": "string"
+                },
+                "op": {
+                    "$ref": "#/definitions/notes.BatchOp"
+                },
                 "tags": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
+                },
+                "version": {
+                    "description": "Version ожидаемая версия заметки для update и delete, 0 - без проверки.",
+                    "type": "integer"
                 }
             }
         },
@@ -330,6 +3405,10 @@ const docTemplate = `{
                 "label": {
                     "type": "string"
                 },
+                "notebookId": {
+                    "description": "NotebookID блокнот владельца, nil - заметка создаётся в корне.",
+                    "type": "string"
+                },
                 "tags": {
                     "type": "array",
                     "items": {
@@ -337,6 +3416,177 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "notes.CreateLinkArgs": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt без срока ссылка действует до отзыва.",
+                    "type": "string"
+                }
+            }
+        },
+        "notes.DeleteTarget": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version версия, которую видел клиент, 0 - без проверки.",
+                    "type": "integer"
+                }
+            }
+        },
+        "notes.ShareArgs": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email пользователя, которому выдаётся доступ.",
+                    "type": "string"
+                },
+                "permission": {
+                    "$ref": "#/definitions/note.Permission"
+                }
+            }
+        },
+        "tokens.CreateArgs": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt без срока токен действует до отзыва.",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Scopes по умолчанию все scopes текущего токена, которые можно выдать API-токену.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "user.APIToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "user.IssuedAPIToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.Token": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tokenType": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "users.LoginArgs": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "users.RegisterArgs": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Access token from /auth/login as \"Bearer \u003ctoken\u003e\".",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
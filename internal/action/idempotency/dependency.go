@@ -0,0 +1,56 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+)
+
+// Store хранит ключи идемпотентности. Ключ уникален в пределах пользователя.
+type Store interface {
+	// Reserve сохраняет запись без ответа. Если у пользователя есть
+	// неистёкшая запись с тем же ключом, возвращает ErrKeyExists, истёкшая
+	// запись заменяется.
+	Reserve(ctx context.Context, record Record) error
+	// Get возвращает NotFound, если записи нет.
+	Get(ctx context.Context, userID uuid.UUID, key string) (Record, error)
+	// Complete сохраняет ответ на запрос с этим ключом.
+	Complete(ctx context.Context, userID uuid.UUID, key string, response Response) error
+	// Release удаляет запись без ответа, чтобы запрос можно было повторить.
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+	// PurgeExpired удаляет записи всех пользователей, истёкшие к before.
+	PurgeExpired(ctx context.Context, before time.Time) (uint, error)
+}
+
+// Record запрос, выполненный с ключом идемпотентности.
+type Record struct {
+	UserID uuid.UUID
+	Key    string
+	// RequestHash отпечаток метода, пути и тела запроса.
+	RequestHash []byte
+	// Response nil, пока запрос выполняется.
+	Response  *Response
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Response сохранённый ответ, который повторяется при повторе запроса.
+type Response struct {
+	Status int
+	// Header заголовки ответа, которые нужно повторить.
+	Header map[string]string
+	Body   []byte
+}
+
+var (
+	NotFound     = apperr.NotFound("idempotency_key_not_found", "idempotency key not found")
+	ErrKeyExists = apperr.Conflict("idempotency_key_exists", "idempotency key is already used")
+	// ErrKeyReused ключ уже использован с другим запросом.
+	ErrKeyReused = apperr.New(apperr.KindUnprocessable, "idempotency_key_reused", "idempotency key was already used with another request")
+	// ErrInProgress первый запрос с этим ключом ещё выполняется.
+	ErrInProgress = apperr.Conflict("idempotency_key_in_progress", "request with this idempotency key is still in progress")
+	ErrInvalidKey = apperr.Validation("invalid_idempotency_key", "invalid idempotency key",
+		apperr.FieldError{Field: "Idempotency-Key", Message: "must be 1-255 printable ASCII characters"})
+)
@@ -0,0 +1,122 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"go.uber.org/zap"
+)
+
+// Keeper связывает ключ идемпотентности с первым ответом на запрос.
+type Keeper struct {
+	store Store
+	// ttl сколько хранится ответ после первого запроса.
+	ttl time.Duration
+	log *zap.Logger
+}
+
+func NewKeeper(store Store, ttl time.Duration, log *zap.Logger) *Keeper {
+	return &Keeper{store: store, ttl: ttl, log: log}
+}
+
+// Begin резервирует ключ за запросом. Если запрос с этим ключом уже
+// выполнен, возвращает его ответ, и выполнять запрос снова не нужно.
+// Тот же ключ с другим запросом даёт ErrKeyReused.
+func (k *Keeper) Begin(ctx context.Context, userID uuid.UUID, key string, requestHash []byte) (*Response, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	now := time.Now()
+	err := k.store.Reserve(ctx, Record{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(k.ttl),
+	})
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, ErrKeyExists) {
+		return nil, errors.WithMessage(err, "reserve idempotency key")
+	}
+
+	record, err := k.store.Get(ctx, userID, key)
+	if errors.Is(err, NotFound) {
+		// Первый запрос только что освободил ключ.
+		return nil, ErrInProgress
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "get idempotency key")
+	}
+	if !bytes.Equal(record.RequestHash, requestHash) {
+		return nil, ErrKeyReused
+	}
+	if record.Response == nil {
+		return nil, ErrInProgress
+	}
+
+	k.log.Debug("Replaying response", zap.String("key", key), zap.Int("status", record.Response.Status))
+
+	return record.Response, nil
+}
+
+// Complete сохраняет ответ для повторов запроса.
+func (k *Keeper) Complete(ctx context.Context, userID uuid.UUID, key string, response Response) error {
+	return errors.WithMessage(k.store.Complete(ctx, userID, key, response), "complete idempotency key")
+}
+
+// Release освобождает ключ запроса, который не удалось выполнить.
+func (k *Keeper) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	return errors.WithMessage(k.store.Release(ctx, userID, key), "release idempotency key")
+}
+
+func validKey(key string) bool {
+	if len(key) == 0 || len(key) > 255 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Purger фоновое удаление истёкших ключей.
+type Purger struct {
+	store    Store
+	interval time.Duration
+	log      *zap.Logger
+}
+
+func NewPurger(store Store, interval time.Duration, log *zap.Logger) *Purger {
+	return &Purger{store: store, interval: interval, log: log}
+}
+
+// Run удаляет истёкшие ключи сразу и затем каждые interval, пока ctx не отменён.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := p.store.PurgeExpired(ctx, time.Now())
+		switch {
+		case err != nil:
+			p.log.Error("Failed to purge idempotency keys", zap.Error(err))
+		case count > 0:
+			p.log.Info("Purged expired idempotency keys", zap.Uint("count", count))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package storetest проверки idempotency.Store: резервирование ключа, сохранение
+// ответа, освобождение ключа и удаление просроченных.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency"
+)
+
+// Factory пустое хранилище ключей, owners - пользователи, которым принадлежат ключи.
+type Factory func(t *testing.T, owners ...uuid.UUID) idempotency.Store
+
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store idempotency.Store)
+	}{
+		{"ReserveAndGet", testReserveAndGet},
+		{"ReserveTwice", testReserveTwice},
+		{"Complete", testComplete},
+		{"Release", testRelease},
+		{"PurgeExpired", testPurgeExpired},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t, owner, stranger))
+		})
+	}
+}
+
+var (
+	owner    = uuid.FromStringOrNil("01890000-0000-7000-8000-000000000001")
+	stranger = uuid.FromStringOrNil("01890000-0000-7000-8000-000000000002")
+)
+
+// base время с точностью до микросекунд, как хранит postgres.
+var base = time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+
+func newRecord(userID uuid.UUID, key string, createdAt time.Time) idempotency.Record {
+	return idempotency.Record{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: []byte("hash of " + key),
+		CreatedAt:   createdAt,
+		ExpiresAt:   createdAt.Add(time.Hour),
+	}
+}
+
+func reserve(t *testing.T, store idempotency.Store, list ...idempotency.Record) {
+	t.Helper()
+
+	for _, record := range list {
+		if err := store.Reserve(context.Background(), record); err != nil {
+			t.Fatalf("Reserve %v: %v", record.Key, err)
+		}
+	}
+}
+
+func assertRecord(t *testing.T, got, want idempotency.Record) {
+	t.Helper()
+
+	if got.UserID != want.UserID || got.Key != want.Key || string(got.RequestHash) != string(want.RequestHash) {
+		t.Errorf("record = %v %q %q, want %v %q %q", got.UserID, got.Key, got.RequestHash, want.UserID, want.Key, want.RequestHash)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || !got.ExpiresAt.Equal(want.ExpiresAt) {
+		t.Errorf("CreatedAt, ExpiresAt = %v, %v, want %v, %v", got.CreatedAt, got.ExpiresAt, want.CreatedAt, want.ExpiresAt)
+	}
+	if got.Response != nil {
+		t.Errorf("Response = %+v, want nil", got.Response)
+	}
+}
+
+func testReserveAndGet(t *testing.T, store idempotency.Store) {
+	ctx := context.Background()
+	record := newRecord(owner, "key", base)
+	reserve(t, store, record, newRecord(stranger, "key", base))
+
+	got, err := store.Get(ctx, owner, "key")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	assertRecord(t, got, record)
+
+	if _, err := store.Get(ctx, owner, "unknown"); !errors.Is(err, idempotency.NotFound) {
+		t.Errorf("Get unknown: err = %v, want idempotency.NotFound", err)
+	}
+}
+
+func testReserveTwice(t *testing.T, store idempotency.Store) {
+	ctx := context.Background()
+	first := newRecord(owner, "key", base)
+	reserve(t, store, first)
+	if err := store.Complete(ctx, owner, "key", idempotency.Response{Status: 201}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	second := newRecord(owner, "key", base.Add(time.Minute))
+	second.RequestHash = []byte("another hash")
+	if err := store.Reserve(ctx, second); !errors.Is(err, idempotency.ErrKeyExists) {
+		t.Fatalf("Reserve live key: err = %v, want idempotency.ErrKeyExists", err)
+	}
+
+	// Истёкшая запись заменяется новой без ответа.
+	third := newRecord(owner, "key", first.ExpiresAt)
+	third.RequestHash = []byte("third hash")
+	reserve(t, store, third)
+
+	got, err := store.Get(ctx, owner, "key")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	assertRecord(t, got, third)
+}
+
+func testComplete(t *testing.T, store idempotency.Store) {
+	ctx := context.Background()
+	reserve(t, store, newRecord(owner, "key", base))
+
+	response := idempotency.Response{
+		Status: 201,
+		Header: map[string]string{"Content-Type": "application/json", "Location": "/api/v1/note/1"},
+		Body:   []byte(`{"id":"1"}`),
+	}
+	if err := store.Complete(ctx, owner, "key", response); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	got, err := store.Get(ctx, owner, "key")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Response == nil {
+		t.Fatalf("Response = nil, want stored response")
+	}
+	if got.Response.Status != response.Status || string(got.Response.Body) != string(response.Body) ||
+		len(got.Response.Header) != 2 || got.Response.Header["Location"] != "/api/v1/note/1" {
+		t.Errorf("Response = %+v, want %+v", *got.Response, response)
+	}
+
+	if err := store.Complete(ctx, stranger, "key", response); !errors.Is(err, idempotency.NotFound) {
+		t.Errorf("Complete by stranger: err = %v, want idempotency.NotFound", err)
+	}
+}
+
+func testRelease(t *testing.T, store idempotency.Store) {
+	ctx := context.Background()
+	reserve(t, store, newRecord(owner, "pending", base), newRecord(owner, "done", base))
+	if err := store.Complete(ctx, owner, "done", idempotency.Response{Status: 200}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	if err := store.Release(ctx, owner, "pending"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, err := store.Get(ctx, owner, "pending"); !errors.Is(err, idempotency.NotFound) {
+		t.Errorf("Get after Release: err = %v, want idempotency.NotFound", err)
+	}
+
+	// Запись с ответом не освобождается.
+	if err := store.Release(ctx, owner, "done"); !errors.Is(err, idempotency.NotFound) {
+		t.Errorf("Release completed key: err = %v, want idempotency.NotFound", err)
+	}
+	if _, err := store.Get(ctx, owner, "done"); err != nil {
+		t.Errorf("Get completed key after Release: %v", err)
+	}
+}
+
+func testPurgeExpired(t *testing.T, store idempotency.Store) {
+	ctx := context.Background()
+	reserve(t, store, newRecord(owner, "old", base), newRecord(stranger, "old", base), newRecord(owner, "fresh", base.Add(time.Hour)))
+
+	count, err := store.PurgeExpired(ctx, base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeExpired: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("PurgeExpired = %v, want 2", count)
+	}
+	if _, err := store.Get(ctx, owner, "old"); !errors.Is(err, idempotency.NotFound) {
+		t.Errorf("Get purged: err = %v, want idempotency.NotFound", err)
+	}
+	if _, err := store.Get(ctx, owner, "fresh"); err != nil {
+		t.Errorf("Get fresh: %v", err)
+	}
+}
@@ -0,0 +1,370 @@
+package notes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidBatch = apperr.Validation("invalid_batch", "invalid batch")
+	// ErrBatchAborted операция не применена, потому что в пакете без
+	// continueOnError не удалась другая операция.
+	ErrBatchAborted = apperr.Conflict("batch_aborted", "operation was rolled back because another operation of the batch failed")
+)
+
+// BatchOp вид операции пакета.
+type BatchOp string
+
+const (
+	BatchOpCreate     BatchOp = "create"
+	BatchOpUpdate     BatchOp = "update"
+	BatchOpDelete     BatchOp = "delete"
+	BatchOpAddTags    BatchOp = "tag-add"
+	BatchOpRemoveTags BatchOp = "tag-remove"
+)
+
+// BatchOperation одна операция пакета. ID не задаётся только для create.
+type BatchOperation struct {
+	Op         BatchOp    `json:"op"`
+	ID         uuid.UUID  `json:"id,omitempty"`
+	Label      string     `json:"label,omitempty"`
+	Body       string     `json:"body,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	NotebookID *uuid.UUID `json:"notebookId,omitempty"`
+	// Version ожидаемая версия заметки для update и delete, 0 - без проверки.
+	Version uint `json:"version,omitempty"`
+}
+
+type BatchArgs struct {
+	UserID     uuid.UUID        `json:"-"`
+	Operations []BatchOperation `json:"operations"`
+	// ContinueOnError применяет удавшиеся операции, даже если другие не удались.
+	// Без него пакет применяется целиком или не применяется вовсе.
+	ContinueOnError bool `json:"continueOnError"`
+}
+
+// BatchWrite изменение, которое хранилище выполняет в транзакции пакета.
+// Задано ровно одно поле.
+type BatchWrite struct {
+	Create *note.Note
+	Patch  *PatchArgs
+	Delete *BatchDelete
+}
+
+// BatchDelete перенос заметки в корзину с проверкой версии, как DeleteOne.
+type BatchDelete struct {
+	OwnerID   uuid.UUID
+	ID        uuid.UUID
+	Version   uint
+	DeletedAt time.Time
+}
+
+// BatchResult итог одной операции.
+type BatchResult struct {
+	Op BatchOp
+	ID uuid.UUID
+	// Note заметка после операции, nil для delete и при ошибке.
+	Note *note.Note
+	Err  error
+}
+
+type BatchOutcome struct {
+	// Committed изменения сохранены. С ContinueOnError всегда true.
+	Committed bool
+	Results   []BatchResult
+}
+
+type BatchAction struct {
+	store Store
+	// maxOperations наибольшее число операций в одном пакете.
+	maxOperations uint
+	log           *zap.Logger
+}
+
+func NewBatchAction(store Store, maxOperations uint, log *zap.Logger) *BatchAction {
+	return &BatchAction{store: store, maxOperations: maxOperations, log: log}
+}
+
+// Do проверяет все операции по порядку, как если бы они выполнялись одна за
+// другой, и передаёт удавшиеся хранилищу одной транзакцией. Каждая операция
+// получает свой итог. Права проверяются так же, как у отдельных запросов:
+// изменение и теги требуют write, удаление - владельца.
+func (a *BatchAction) Do(ctx context.Context, args BatchArgs) (BatchOutcome, error) {
+	if len(args.Operations) == 0 {
+		return BatchOutcome{}, ErrInvalidBatch.WithField("operations", "must not be empty")
+	}
+	if uint(len(args.Operations)) > a.maxOperations {
+		return BatchOutcome{}, ErrInvalidBatch.WithField("operations",
+			fmt.Sprintf("must contain at most %v operations", a.maxOperations))
+	}
+
+	plan := newBatchPlan(a.store, args.UserID, time.Now())
+	results := make([]BatchResult, len(args.Operations))
+	writes, positions := []BatchWrite{}, []int{}
+	failed := false
+	for i, op := range args.Operations {
+		write, result, err := plan.prepare(ctx, i, op)
+		if _, ok := apperr.As(err); err != nil && !ok {
+			return BatchOutcome{}, errors.WithMessagef(err, "prepare batch operation %v", i)
+		}
+		result.Err = err
+		results[i] = result
+		if err != nil {
+			failed = true
+			continue
+		}
+		if write != nil {
+			writes = append(writes, *write)
+			positions = append(positions, i)
+		}
+	}
+
+	if failed && !args.ContinueOnError {
+		return abortBatch(results), nil
+	}
+
+	if len(writes) > 0 {
+		errs, err := a.store.Batch(ctx, writes, args.ContinueOnError)
+		if err != nil {
+			return BatchOutcome{}, errors.WithMessage(err, "apply batch")
+		}
+		for j, err := range errs {
+			if err == nil {
+				continue
+			}
+			if _, ok := apperr.As(err); !ok && !args.ContinueOnError {
+				return BatchOutcome{}, errors.WithMessage(err, "apply batch")
+			}
+			i := positions[j]
+			results[i].Note, results[i].Err = nil, err
+			failed = true
+		}
+	}
+
+	if failed && !args.ContinueOnError {
+		return abortBatch(results), nil
+	}
+
+	a.log.Debug("Applied batch", zap.Int("operations", len(args.Operations)), zap.Int("writes", len(writes)))
+
+	return BatchOutcome{Committed: true, Results: results}, nil
+}
+
+// abortBatch итог пакета, который не был сохранён: операции без собственной
+// ошибки получают ErrBatchAborted.
+func abortBatch(results []BatchResult) BatchOutcome {
+	for i := range results {
+		results[i].Note = nil
+		if results[i].Err == nil {
+			results[i].Err = ErrBatchAborted
+		}
+	}
+
+	return BatchOutcome{Results: results}
+}
+
+// batchPlan состояние заметок с учётом уже проверенных операций пакета.
+type batchPlan struct {
+	store  Store
+	userID uuid.UUID
+	now    time.Time
+	// notes заметки, затронутые пакетом, nil - удалённая пакетом.
+	notes       map[uuid.UUID]*note.Note
+	permissions map[uuid.UUID]note.Permission
+}
+
+func newBatchPlan(store Store, userID uuid.UUID, now time.Time) *batchPlan {
+	return &batchPlan{
+		store:       store,
+		userID:      userID,
+		now:         now,
+		notes:       map[uuid.UUID]*note.Note{},
+		permissions: map[uuid.UUID]note.Permission{},
+	}
+}
+
+// prepare проверяет операцию и возвращает изменение для хранилища. nil без
+// ошибки означает, что операция ничего не меняет.
+func (p *batchPlan) prepare(ctx context.Context, i int, op BatchOperation) (*BatchWrite, BatchResult, error) {
+	result := BatchResult{Op: op.Op, ID: op.ID}
+	field := func(name string) string {
+		return fmt.Sprintf("operations[%v].%v", i, name)
+	}
+
+	switch op.Op {
+	case BatchOpCreate:
+		if op.ID != uuid.Nil {
+			return nil, result, ErrInvalidBatch.WithField(field("id"), "must not be set for create")
+		}
+		return p.create(ctx, op, result)
+	case BatchOpUpdate, BatchOpAddTags, BatchOpRemoveTags, BatchOpDelete:
+	default:
+		return nil, result, ErrInvalidBatch.WithField(field("op"), "must be one of create, update, delete, tag-add, tag-remove")
+	}
+
+	if op.ID == uuid.Nil {
+		return nil, result, ErrInvalidBatch.WithField(field("id"), "is required")
+	}
+	if (op.Op == BatchOpAddTags || op.Op == BatchOpRemoveTags) && len(op.Tags) == 0 {
+		return nil, result, ErrInvalidBatch.WithField(field("tags"), "must not be empty")
+	}
+
+	required := note.PermissionWrite
+	if op.Op == BatchOpDelete {
+		required = note.PermissionOwner
+	}
+	current, err := p.get(ctx, op.ID, required)
+	if err != nil {
+		return nil, result, err
+	}
+	if op.Version != 0 && op.Version != current.Version {
+		return nil, result, ErrVersionMismatch
+	}
+
+	if op.Op == BatchOpDelete {
+		p.notes[op.ID] = nil
+		return &BatchWrite{Delete: &BatchDelete{
+			OwnerID:   p.userID,
+			ID:        op.ID,
+			Version:   current.Version,
+			DeletedAt: p.now,
+		}}, result, nil
+	}
+
+	patched := current
+	switch op.Op {
+	case BatchOpUpdate:
+		patched.Label, patched.Body, patched.Tags = op.Label, op.Body, op.Tags
+		if err := patched.Validate(); err != nil {
+			return nil, result, err
+		}
+	case BatchOpAddTags:
+		patched.Tags = addTags(current.Tags, op.Tags)
+	case BatchOpRemoveTags:
+		patched.Tags = removeTags(current.Tags, op.Tags)
+	}
+	if op.Op != BatchOpUpdate && sameTags(patched.Tags, current.Tags) {
+		result.Note = &current
+		return nil, result, nil
+	}
+
+	args := PatchArgs{
+		UserID:    p.userID,
+		ID:        op.ID,
+		Tags:      &patched.Tags,
+		Version:   current.Version,
+		UpdatedAt: p.now,
+	}
+	if op.Op == BatchOpUpdate {
+		args.Label, args.Body = &patched.Label, &patched.Body
+	}
+
+	patched.Version++
+	patched.UpdatedAt = p.now
+	p.notes[op.ID] = &patched
+	result.Note = &patched
+
+	return &BatchWrite{Patch: &args}, result, nil
+}
+
+func (p *batchPlan) create(ctx context.Context, op BatchOperation, result BatchResult) (*BatchWrite, BatchResult, error) {
+	created := note.Note{
+		ID:        uuid.UUID(ulid.Make()),
+		OwnerID:   p.userID,
+		Label:     op.Label,
+		Body:      op.Body,
+		Tags:      op.Tags,
+		CreatedAt: p.now,
+		UpdatedAt: p.now,
+		Version:   1,
+	}
+	if err := created.Validate(); err != nil {
+		return nil, result, err
+	}
+
+	notebookID, err := ownNotebook(ctx, p.store, p.userID, op.NotebookID)
+	if err != nil {
+		return nil, result, err
+	}
+	if notebookID != uuid.Nil {
+		created.NotebookID = &notebookID
+	}
+
+	p.notes[created.ID] = &created
+	p.permissions[created.ID] = note.PermissionOwner
+	result.ID, result.Note = created.ID, &created
+
+	return &BatchWrite{Create: &created}, result, nil
+}
+
+// get заметка в состоянии после предыдущих операций пакета.
+func (p *batchPlan) get(ctx context.Context, id uuid.UUID, required note.Permission) (note.Note, error) {
+	permission, ok := p.permissions[id]
+	if !ok {
+		var err error
+		permission, err = p.store.Access(ctx, p.userID, id)
+		if err != nil {
+			return note.Note{}, errors.WithMessage(err, "check access")
+		}
+		p.permissions[id] = permission
+	}
+	if !permission.Allows(required) {
+		return note.Note{}, ErrForbidden
+	}
+
+	planned, ok := p.notes[id]
+	if ok && planned == nil {
+		return note.Note{}, NotFound
+	}
+	if ok {
+		return *planned, nil
+	}
+
+	current, err := p.store.GetByID(ctx, id)
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "get note")
+	}
+	p.notes[id] = &current
+
+	return current, nil
+}
+
+func addTags(tags, added []string) []string {
+	result := append([]string{}, tags...)
+	for _, tag := range added {
+		if !containsTag(result, tag) {
+			result = append(result, tag)
+		}
+	}
+
+	return result
+}
+
+func removeTags(tags, removed []string) []string {
+	result := []string{}
+	for _, tag := range tags {
+		if !containsTag(removed, tag) {
+			result = append(result, tag)
+		}
+	}
+
+	return result
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -27,25 +27,40 @@ func NewCreateAction(
 }
 
 type CreateArgs struct {
-	Label string   `json:"label"`
-	Body  string   `json:"body"`
-	Tags  []string `json:"tags"`
+	// OwnerID автор заметки, берётся из аутентификации, а не из тела запроса.
+	OwnerID uuid.UUID `json:"-"`
+	Label   string    `json:"label"`
+	Body    string    `json:"body"`
+	Tags    []string  `json:"tags"`
+	// NotebookID блокнот владельца, nil - заметка создаётся в корне.
+	NotebookID *uuid.UUID `json:"notebookId"`
 }
 
 func (a *CreateAction) Do(ctx context.Context, args CreateArgs) (note.Note, error) {
 	newNote := note.Note{
 		ID:        uuid.UUID(ulid.Make()),
+		OwnerID:   args.OwnerID,
 		Label:     args.Label,
 		Body:      args.Body,
 		Tags:      args.Tags,
 		CreatedAt: time.Now(),
+		Version:   1,
 	}
+	newNote.UpdatedAt = newNote.CreatedAt
 
 	err := newNote.Validate()
 	if err != nil {
 		return note.Note{}, errors.WithMessage(err, "Failed validation, during saving note.")
 	}
 
+	notebookID, err := ownNotebook(ctx, a.store, args.OwnerID, args.NotebookID)
+	if err != nil {
+		return note.Note{}, err
+	}
+	if notebookID != uuid.Nil {
+		newNote.NotebookID = &notebookID
+	}
+
 	a.log.Debug("Create note and validate it.", zap.Any("note", newNote))
 
 	err = a.store.Create(ctx, newNote)
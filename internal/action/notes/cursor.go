@@ -0,0 +1,135 @@
+package notes
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+)
+
+var ErrInvalidCursor = apperr.Validation("invalid_cursor", "invalid cursor", apperr.FieldError{Field: "cursor", Message: "is malformed or was tampered with"})
+
+// Keyset позиция в отсортированном списке: значение ключа сортировки и ID
+// последней (или первой, если Backward) показанной заметки.
+type Keyset struct {
+	Label     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ID        uuid.UUID
+	// Pinned закреплена ли заметка: закреплённые идут в списке первыми.
+	Pinned bool
+	// Backward страница перед позицией, а не после неё.
+	Backward bool
+}
+
+// cursorPayload содержимое курсора. Сортировка зашита в курсор, чтобы
+// следующая страница не могла быть запрошена с другим порядком.
+type cursorPayload struct {
+	SortBy    SortField     `json:"s"`
+	Direction SortDirection `json:"d"`
+	Label     string        `json:"l,omitempty"`
+	CreatedAt *time.Time    `json:"c,omitempty"`
+	UpdatedAt *time.Time    `json:"u,omitempty"`
+	ID        uuid.UUID     `json:"i"`
+	Pinned    bool          `json:"p,omitempty"`
+	Backward  bool          `json:"b,omitempty"`
+}
+
+// CursorCodec кодирует курсоры и подписывает их HMAC-SHA256, чтобы клиент
+// не мог подменить позицию или сортировку.
+type CursorCodec struct {
+	secret []byte
+}
+
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+// Encode строит курсор, указывающий на позицию заметки n в списке с сортировкой args.
+func (c *CursorCodec) Encode(args ListArgs, n note.Note, backward bool) (string, error) {
+	payload := cursorPayload{
+		SortBy:    args.SortBy,
+		Direction: args.SortDirection,
+		ID:        n.ID,
+		Pinned:    n.Pinned,
+		Backward:  backward,
+	}
+	switch args.SortBy {
+	case SortFieldDate:
+		createdAt := n.CreatedAt.UTC()
+		payload.CreatedAt = &createdAt
+	case SortFieldUpdated:
+		updatedAt := n.UpdatedAt.UTC()
+		payload.UpdatedAt = &updatedAt
+	default:
+		payload.Label = n.Label
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal cursor")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(data)), nil
+}
+
+// Decode проверяет подпись и возвращает позицию. Сортировка курсора должна
+// совпадать с сортировкой запроса.
+func (c *CursorCodec) Decode(cursor string, args ListArgs) (Keyset, error) {
+	encoded, signature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return Keyset{}, ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Keyset{}, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, c.sign(data)) {
+		return Keyset{}, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		return Keyset{}, ErrInvalidCursor
+	}
+
+	if payload.SortBy != args.SortBy || payload.Direction != args.SortDirection {
+		return Keyset{}, apperr.Validation(ErrInvalidCursor.Code, ErrInvalidCursor.Message,
+			apperr.FieldError{Field: "cursor", Message: "was issued for another sort order"})
+	}
+
+	keyset := Keyset{
+		Label:    payload.Label,
+		ID:       payload.ID,
+		Pinned:   payload.Pinned,
+		Backward: payload.Backward,
+	}
+	if payload.CreatedAt != nil {
+		keyset.CreatedAt = *payload.CreatedAt
+	}
+	if payload.UpdatedAt != nil {
+		keyset.UpdatedAt = *payload.UpdatedAt
+	}
+
+	return keyset, nil
+}
+
+func (c *CursorCodec) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,112 @@
+package notes
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+)
+
+var cursorNote = note.Note{
+	ID:        uuid.FromStringOrNil("0190f1f4-6d0e-7c2c-8f4b-000000000001"),
+	Label:     "label",
+	CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.FixedZone("UTC+3", 3*60*60)),
+	UpdatedAt: time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC),
+	Pinned:    true,
+}
+
+func TestCursorCodecRoundTrip(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+
+	tests := []struct {
+		args     ListArgs
+		backward bool
+		want     Keyset
+	}{
+		{
+			args: ListArgs{SortBy: SortFieldLabel},
+			want: Keyset{Label: "label", ID: cursorNote.ID, Pinned: true},
+		},
+		{
+			args:     ListArgs{SortBy: SortFieldDate, SortDirection: SortDirectionDesc},
+			backward: true,
+			want:     Keyset{CreatedAt: cursorNote.CreatedAt, ID: cursorNote.ID, Pinned: true, Backward: true},
+		},
+		{
+			args: ListArgs{SortBy: SortFieldUpdated},
+			want: Keyset{UpdatedAt: cursorNote.UpdatedAt, ID: cursorNote.ID, Pinned: true},
+		},
+	}
+
+	for _, tt := range tests {
+		cursor, err := codec.Encode(tt.args, cursorNote, tt.backward)
+		if err != nil {
+			t.Fatalf("Encode(%v): %v", tt.args.SortBy, err)
+		}
+		got, err := codec.Decode(cursor, tt.args)
+		if err != nil {
+			t.Fatalf("Decode(%v): %v", tt.args.SortBy, err)
+		}
+		if got.Label != tt.want.Label || !got.CreatedAt.Equal(tt.want.CreatedAt) || !got.UpdatedAt.Equal(tt.want.UpdatedAt) ||
+			got.ID != tt.want.ID || got.Pinned != tt.want.Pinned || got.Backward != tt.want.Backward {
+			t.Errorf("Decode(%v) = %+v, want %+v", tt.args.SortBy, got, tt.want)
+		}
+	}
+}
+
+func TestCursorCodecRejects(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	args := ListArgs{SortBy: SortFieldLabel}
+	cursor, err := codec.Encode(args, cursorNote, false)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	encoded, signature, _ := strings.Cut(cursor, ".")
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	tampered := base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(payload), `"label"`, `"zzz"`, 1))) + "." + signature
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		t.Fatalf("decode signature: %v", err)
+	}
+	sig[0] ^= 1
+	badSignature := encoded + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	otherSecret, err := NewCursorCodec([]byte("other")).Encode(args, cursorNote, false)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		args   ListArgs
+	}{
+		{name: "TamperedPayload", cursor: tampered, args: args},
+		{name: "BadSignature", cursor: badSignature, args: args},
+		{name: "OtherSecret", cursor: otherSecret, args: args},
+		{name: "NoSignature", cursor: encoded, args: args},
+		{name: "EmptySignature", cursor: encoded + ".", args: args},
+		{name: "NotBase64", cursor: "!!!." + signature, args: args},
+		{name: "Garbage", cursor: "garbage", args: args},
+		{name: "OtherSortBy", cursor: cursor, args: ListArgs{SortBy: SortFieldDate}},
+		{name: "OtherDirection", cursor: cursor, args: ListArgs{SortBy: SortFieldLabel, SortDirection: SortDirectionDesc}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := codec.Decode(tt.cursor, tt.args); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
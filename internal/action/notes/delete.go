@@ -2,12 +2,21 @@ package notes
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
 	"go.uber.org/zap"
 )
 
+// DeleteTarget заметка для пакетного удаления.
+type DeleteTarget struct {
+	ID uuid.UUID `json:"id"`
+	// Version версия, которую видел клиент, 0 - без проверки.
+	Version uint `json:"version"`
+}
+
 type DeleteAction struct {
 	store Store
 	log   *zap.Logger
@@ -20,13 +29,55 @@ func NewDeleteAction(store Store, log *zap.Logger) *DeleteAction {
 	}
 }
 
-func (a *DeleteAction) Do(ctx context.Context, noteIDs []uuid.UUID) error {
-	err := a.store.Delete(ctx, noteIDs)
+// Do переносит заметки владельца в корзину. Если среди них есть чужая
+// заметка, доступная пользователю, или заметка изменилась после чтения,
+// ничего не удаляется и возвращается ErrForbidden или ErrVersionMismatch.
+// Невидимые пользователю ID пропускаются, как и несуществующие.
+func (a *DeleteAction) Do(ctx context.Context, ownerID uuid.UUID, targets []DeleteTarget) error {
+	for _, target := range targets {
+		err := authorize(ctx, a.store, ownerID, target.ID, note.PermissionOwner)
+		if errors.Is(err, NotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	err := a.store.Delete(ctx, ownerID, targets, time.Now())
 	if err != nil {
 		return errors.WithMessage(err, "Failed during action deleting")
 	}
 
-	a.log.Debug("Deleted notes", zap.Any("noteIDs", noteIDs))
+	a.log.Debug("Deleted notes", zap.Any("targets", targets))
+
+	return nil
+}
+
+type DeleteByIDAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewDeleteByIDAction(store Store, log *zap.Logger) *DeleteByIDAction {
+	return &DeleteByIDAction{
+		store: store,
+		log:   log,
+	}
+}
+
+// Do переносит в корзину одну заметку, если её версия всё ещё равна version.
+// В отличие от DeleteAction, недоступная заметка даёт NotFound.
+func (a *DeleteByIDAction) Do(ctx context.Context, ownerID, noteID uuid.UUID, version uint) error {
+	if err := authorize(ctx, a.store, ownerID, noteID, note.PermissionOwner); err != nil {
+		return err
+	}
+
+	if err := a.store.DeleteOne(ctx, ownerID, noteID, version, time.Now()); err != nil {
+		return errors.WithMessage(err, "delete note")
+	}
+
+	a.log.Debug("Deleted note", zap.Any("noteID", noteID))
 
 	return nil
 }
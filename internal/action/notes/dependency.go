@@ -2,19 +2,129 @@ package notes
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
 	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
 )
 
+// Store хранилище заметок. Списки, поиск, теги и удаление работают только с
+// заметками одного владельца. GetByID и Update обращаются к заметке по ID,
+// права на неё проверяют действия через Access. Заметки в корзине видны
+// только Query и Count с Filter.Trashed, Restore и Purge.
 type Store interface {
+	// Create сохраняет заметку с версией 1.
 	Create(ctx context.Context, note note.Note) error
+	// Update увеличивает версию заметки. Если args.Version не 0 и не совпадает
+	// с текущей, заметка не меняется и возвращается ErrVersionMismatch.
 	Update(ctx context.Context, args UpdateArgs) error
-	Delete(ctx context.Context, ids []uuid.UUID) error
+	// Patch меняет только заданные в args поля, версию и историю ведёт как Update.
+	Patch(ctx context.Context, args PatchArgs) error
+	// Delete переносит заметки владельца в корзину, отсутствующие пропускает.
+	// Если версия хотя бы одной не совпадает, ничего не удаляет и возвращает
+	// ErrVersionMismatch.
+	Delete(ctx context.Context, ownerID uuid.UUID, targets []DeleteTarget, deletedAt time.Time) error
+	// DeleteOne переносит заметку владельца в корзину с проверкой версии, как Update.
+	DeleteOne(ctx context.Context, ownerID, id uuid.UUID, version uint, deletedAt time.Time) error
+	// Restore и Purge возвращают NotFound, если заметки нет в корзине владельца.
+	Restore(ctx context.Context, ownerID, id uuid.UUID) error
+	// Purge стирает заметку навсегда вместе с правами, ссылками и историей.
+	Purge(ctx context.Context, ownerID, id uuid.UUID) error
+	// PurgeDeleted стирает заметки всех владельцев, попавшие в корзину раньше before.
+	PurgeDeleted(ctx context.Context, before time.Time) (uint, error)
 	GetByID(ctx context.Context, id uuid.UUID) (note.Note, error)
 	Query(ctx context.Context, args ListArgs) ([]note.Note, error)
-	Count(ctx context.Context) (uint, error)
+	Count(ctx context.Context, filter Filter) (uint, error)
+	// Search возвращает заметки, подходящие под запрос, по убыванию релевантности.
+	Search(ctx context.Context, args SearchArgs) ([]note.FoundNote, error)
+	SearchCount(ctx context.Context, args SearchArgs) (uint, error)
+	// ListTags возвращает все теги с числом заметок, в которых они встречаются.
+	ListTags(ctx context.Context, ownerID uuid.UUID) ([]note.Tag, error)
+	// RenameTag и DeleteTag меняют тег во всех заметках сразу и возвращают число
+	// затронутых заметок.
+	RenameTag(ctx context.Context, ownerID uuid.UUID, from, to string) (uint, error)
+	DeleteTag(ctx context.Context, ownerID uuid.UUID, tag string) (uint, error)
+	// Access право пользователя на заметку: PermissionOwner для владельца,
+	// выданное через Share для остальных или NotFound, если доступа нет.
+	Access(ctx context.Context, userID, noteID uuid.UUID) (note.Permission, error)
+	// Share выдаёт право на заметку или меняет уже выданное.
+	Share(ctx context.Context, share note.Share) error
+	// Unshare возвращает ErrShareNotFound, если право не выдавалось.
+	Unshare(ctx context.Context, noteID, userID uuid.UUID) error
+	ListShares(ctx context.Context, noteID uuid.UUID) ([]note.Share, error)
+	// SaveLink создаёт публичную ссылку или заменяет существующую.
+	SaveLink(ctx context.Context, link note.Link) error
+	// GetLink и GetLinkByToken возвращают ErrLinkNotFound, если ссылки нет.
+	GetLink(ctx context.Context, noteID uuid.UUID) (note.Link, error)
+	GetLinkByToken(ctx context.Context, token string) (note.Link, error)
+	DeleteLink(ctx context.Context, noteID uuid.UUID) error
+	// ListRevisions возвращает историю заметки от новых ревизий к старым.
+	// Create и Update сами сохраняют ревизию в той же транзакции.
+	ListRevisions(ctx context.Context, noteID uuid.UUID) ([]note.Revision, error)
+	// GetRevision возвращает ErrRevisionNotFound, если такой ревизии нет.
+	GetRevision(ctx context.Context, noteID uuid.UUID, rev uint) (note.Revision, error)
+	// CreateNotebook сохраняет блокнот, существование родителя проверяет действие.
+	CreateNotebook(ctx context.Context, notebook note.Notebook) error
+	// GetNotebook возвращает ErrNotebookNotFound, если у владельца нет такого блокнота.
+	GetNotebook(ctx context.Context, ownerID, id uuid.UUID) (note.Notebook, error)
+	// ListNotebooks возвращает все блокноты владельца по имени.
+	ListNotebooks(ctx context.Context, ownerID uuid.UUID) ([]note.Notebook, error)
+	// UpdateNotebook меняет имя и родителя блокнота. Циклы проверяет действие.
+	UpdateNotebook(ctx context.Context, notebook note.Notebook) error
+	// DeleteNotebook удаляет блокнот. С NotebookDeleteMove вложенные блокноты
+	// и заметки переходят к его родителю, с NotebookDeleteTrash блокнот
+	// удаляется вместе с вложенными, а их заметки попадают в корзину.
+	DeleteNotebook(ctx context.Context, ownerID, id uuid.UUID, policy NotebookDeletePolicy, at time.Time) error
+	// MoveNotes переносит заметки владельца в блокнот, uuid.Nil - в корень.
+	// Возвращает число перенесённых заметок, их версия увеличивается.
+	MoveNotes(ctx context.Context, ownerID uuid.UUID, ids []uuid.UUID, notebookID uuid.UUID, at time.Time) (uint, error)
+	// SetFlag меняет признак заметки и увеличивает её версию без новой ревизии.
+	// Возвращает NotFound, если заметки нет или она в корзине, и
+	// ErrVersionMismatch, если version не 0 и не совпадает с текущей.
+	SetFlag(ctx context.Context, id uuid.UUID, flag Flag, value bool, version uint, at time.Time) error
+	// Batch выполняет изменения одной транзакцией и возвращает ошибку каждого.
+	// Без continueOnError останавливается на первой ошибке и ничего не
+	// сохраняет, с ним сохраняет все удавшиеся изменения.
+	Batch(ctx context.Context, writes []BatchWrite, continueOnError bool) ([]error, error)
 }
 
-var NotFound = errors.New("Not Found")
+// Renderer превращает Markdown в безопасный HTML и собирает оглавление.
+type Renderer interface {
+	Render(markdown string) (string, []note.Heading, error)
+}
+
+// UserFinder находит пользователей, с которыми делятся заметками.
+type UserFinder interface {
+	GetByID(ctx context.Context, id uuid.UUID) (user.User, error)
+	GetByEmail(ctx context.Context, email string) (user.User, error)
+}
+
+var (
+	NotFound = apperr.NotFound("note_not_found", "note not found")
+	// ErrAlreadyExists заметка с таким ID уже есть в хранилище.
+	ErrAlreadyExists = apperr.Conflict("note_already_exists", "note with this id already exists")
+	// ErrForbidden заметка видна пользователю, но его права недостаточно.
+	ErrForbidden        = apperr.Forbidden("note_forbidden", "not enough permissions for this note")
+	ErrShareNotFound    = apperr.NotFound("share_not_found", "note is not shared with this user")
+	ErrLinkNotFound     = apperr.NotFound("link_not_found", "link not found or expired")
+	ErrRevisionNotFound = apperr.NotFound("revision_not_found", "revision not found")
+	// ErrVersionMismatch заметку изменили после того, как клиент её прочитал.
+	ErrVersionMismatch = apperr.PreconditionFailed("version_mismatch", "note was modified, reload it and try again")
+)
+
+// authorize возвращает NotFound, если заметка не видна пользователю, и
+// ErrForbidden, если его права меньше required.
+func authorize(ctx context.Context, store Store, userID, noteID uuid.UUID, required note.Permission) error {
+	permission, err := store.Access(ctx, userID, noteID)
+	if err != nil {
+		return errors.WithMessage(err, "check access")
+	}
+	if !permission.Allows(required) {
+		return ErrForbidden
+	}
+
+	return nil
+}
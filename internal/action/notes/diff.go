@@ -0,0 +1,222 @@
+package notes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+)
+
+const (
+	// diffContext число неизменённых строк вокруг изменений в каждом блоке.
+	diffContext = 3
+	// maxDiffLines предел строк в каждой из сравниваемых версий, время
+	// сравнения растёт как произведение размера на число правок.
+	maxDiffLines = 5000
+)
+
+// ErrDiffTooLarge ревизии слишком велики для построчного сравнения.
+var ErrDiffTooLarge = apperr.New(apperr.KindUnprocessable, "diff_too_large",
+	fmt.Sprintf("revisions longer than %v lines can not be compared", maxDiffLines))
+
+type lineEdit struct {
+	// op ' ' для общей строки, '-' для удалённой, '+' для добавленной.
+	op   byte
+	line string
+	// a и b позиции в старом и новом тексте перед этой строкой.
+	a, b int
+}
+
+// unifiedDiff построчный diff в формате unified. Для одинаковых текстов
+// возвращает пустую строку.
+func unifiedDiff(fromName, toName, from, to string) (string, error) {
+	a, b := splitLines(from), splitLines(to)
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return "", ErrDiffTooLarge
+	}
+	edits := diffLines(a, b)
+
+	var out strings.Builder
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+
+		// Блок продолжается, пока между изменениями не больше 2*diffContext общих строк.
+		last := i
+		for j := i; j < len(edits); {
+			if edits[j].op != ' ' {
+				last = j
+				j++
+				continue
+			}
+			k := j
+			for k < len(edits) && edits[k].op == ' ' {
+				k++
+			}
+			if k == len(edits) || k-j > 2*diffContext {
+				break
+			}
+			j = k
+		}
+
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		stop := last + 1 + diffContext
+		if stop > len(edits) {
+			stop = len(edits)
+		}
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %v\n+++ %v\n", fromName, toName)
+		}
+		writeHunk(&out, edits[start:stop])
+		i = stop
+	}
+
+	return out.String(), nil
+}
+
+func writeHunk(out *strings.Builder, hunk []lineEdit) {
+	var aCount, bCount int
+	for _, e := range hunk {
+		if e.op != '+' {
+			aCount++
+		}
+		if e.op != '-' {
+			bCount++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%v +%v @@\n", hunkRange(hunk[0].a, aCount), hunkRange(hunk[0].b, bCount))
+	for _, e := range hunk {
+		out.WriteByte(e.op)
+		out.WriteString(e.line)
+		out.WriteByte('\n')
+	}
+}
+
+// hunkRange номер первой строки и число строк блока. Пустой диапазон
+// указывает на строку, после которой произошло изменение.
+func hunkRange(pos, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%v,0", pos)
+	case 1:
+		return fmt.Sprintf("%v", pos+1)
+	}
+	return fmt.Sprintf("%v,%v", pos+1, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines кратчайший список правок алгоритмом Майерса с поиском среднего
+// участка: время O((N+M)D), память O(N+M).
+func diffLines(a, b []string) []lineEdit {
+	d := differ{a: a, b: b}
+	d.compare(0, len(a), 0, len(b))
+	return d.edits
+}
+
+type differ struct {
+	a, b  []string
+	edits []lineEdit
+}
+
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.edits = append(d.edits, lineEdit{op: ' ', line: d.a[aLo], a: aLo, b: bLo})
+		aLo++
+		bLo++
+	}
+	// Общий хвост добавляется после правок середины.
+	tail := 0
+	for aLo < aHi-tail && bLo < bHi-tail && d.a[aHi-tail-1] == d.b[bHi-tail-1] {
+		tail++
+	}
+	aHi, bHi = aHi-tail, bHi-tail
+
+	switch {
+	case aLo == aHi:
+		for y := bLo; y < bHi; y++ {
+			d.edits = append(d.edits, lineEdit{op: '+', line: d.b[y], a: aLo, b: y})
+		}
+	case bLo == bHi:
+		for x := aLo; x < aHi; x++ {
+			d.edits = append(d.edits, lineEdit{op: '-', line: d.a[x], a: x, b: bLo})
+		}
+	default:
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.compare(aLo, x, bLo, y)
+		for ; x < u; x, y = x+1, y+1 {
+			d.edits = append(d.edits, lineEdit{op: ' ', line: d.a[x], a: x, b: y})
+		}
+		d.compare(u, aHi, v, bHi)
+	}
+
+	for i := 0; i < tail; i++ {
+		d.edits = append(d.edits, lineEdit{op: ' ', line: d.a[aHi+i], a: aHi + i, b: bHi + i})
+	}
+}
+
+// middleSnake общий участок (x, y)-(u, v) в середине кратчайшего пути. Поиск
+// идёт одновременно с начала и с конца, пока пути не встретятся. Концы
+// диапазонов не совпадают, поэтому участок всегда делит задачу на меньшие.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+
+	for step := 0; step <= limit; step++ {
+		for k := -step; k <= step; k += 2 {
+			var px int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				px = forward[offset+k+1]
+			} else {
+				px = forward[offset+k-1] + 1
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && d.a[aLo+px] == d.b[bLo+py] {
+				px++
+				py++
+			}
+			forward[offset+k] = px
+			if delta%2 != 0 && k >= delta-(step-1) && k <= delta+(step-1) && px+backward[offset+delta-k] >= n {
+				return aLo + sx, bLo + sy, aLo + px, bLo + py
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var px int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				px = backward[offset+k+1]
+			} else {
+				px = backward[offset+k-1] + 1
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && d.a[aHi-1-px] == d.b[bHi-1-py] {
+				px++
+				py++
+			}
+			backward[offset+k] = px
+			if delta%2 == 0 && delta-k >= -step && delta-k <= step && px+forward[offset+delta-k] >= n {
+				return aHi - px, bHi - py, aHi - sx, bHi - sy
+			}
+		}
+	}
+
+	panic("diff: paths did not meet")
+}
@@ -0,0 +1,179 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{
+			name: "Equal",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+			want: "",
+		},
+		{
+			name: "EmptyToText",
+			from: "",
+			to:   "a\nb\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "TextToEmpty",
+			from: "a\nb\n",
+			to:   "",
+			want: "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name: "SingleLine",
+			from: "a",
+			to:   "b",
+			want: "--- old\n+++ new\n@@ -1 +1 @@\n-a\n+b\n",
+		},
+		{
+			name: "Context",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			to:   "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- old\n+++ new\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "MergedHunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\neight\n9\n10\n",
+			want: "--- old\n+++ new\n@@ -1,10 +1,10 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n 9\n 10\n",
+		},
+		{
+			name: "SeparateHunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\neleven\n",
+			want: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -8,4 +8,4 @@\n 8\n 9\n 10\n-11\n+eleven\n",
+		},
+		{
+			name: "Insert",
+			from: "a\nc\n",
+			to:   "a\nb\nc\n",
+			want: "--- old\n+++ new\n@@ -1,2 +1,3 @@\n a\n+b\n c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unifiedDiff("old", "new", tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("unifiedDiff: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("unifiedDiff =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffTooLarge(t *testing.T) {
+	large := strings.Repeat("line\n", maxDiffLines+1)
+
+	for _, pair := range [][2]string{{large, "a"}, {"a", large}} {
+		if _, err := unifiedDiff("old", "new", pair[0], pair[1]); !errors.Is(err, ErrDiffTooLarge) {
+			t.Errorf("unifiedDiff error = %v, want %v", err, ErrDiffTooLarge)
+		}
+	}
+
+	limit := strings.Repeat("line\n", maxDiffLines)
+	if _, err := unifiedDiff("old", "new", limit, limit); err != nil {
+		t.Errorf("unifiedDiff at limit: %v", err)
+	}
+}
+
+// TestDiffLinesDisjoint самый дорогой для алгоритма случай, все строки
+// различаются. Память не должна расти с числом правок.
+func TestDiffLinesDisjoint(t *testing.T) {
+	a := make([]string, maxDiffLines)
+	b := make([]string, maxDiffLines)
+	for i := range a {
+		a[i] = fmt.Sprintf("a%v", i)
+		b[i] = fmt.Sprintf("b%v", i)
+	}
+
+	allocs := testing.AllocsPerRun(1, func() { diffLines(a, b) })
+	edits := diffLines(a, b)
+	if len(edits) != 2*maxDiffLines {
+		t.Fatalf("len(edits) = %v, want %v", len(edits), 2*maxDiffLines)
+	}
+	// Два массива на каждый уровень разбиения и рост списка правок.
+	if allocs > 200 {
+		t.Errorf("allocs = %v, want at most 200", allocs)
+	}
+}
+
+// TestDiffLinesMinimal сверяет правки со случайными текстами: они должны
+// собирать обе версии и быть не длиннее, чем даёт наибольшая общая подпоследовательность.
+func TestDiffLinesMinimal(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	words := []string{"a", "b", "c", "d"}
+	text := func() []string {
+		lines := make([]string, random.Intn(12))
+		for i := range lines {
+			lines[i] = words[random.Intn(len(words))]
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := text(), text()
+		edits := diffLines(a, b)
+
+		var gotA, gotB []string
+		changes := 0
+		for _, e := range edits {
+			if e.op != '+' {
+				if e.a != len(gotA) {
+					t.Fatalf("diffLines(%q, %q): edit %q at a = %v, want %v", a, b, e.line, e.a, len(gotA))
+				}
+				gotA = append(gotA, e.line)
+			}
+			if e.op != '-' {
+				if e.b != len(gotB) {
+					t.Fatalf("diffLines(%q, %q): edit %q at b = %v, want %v", a, b, e.line, e.b, len(gotB))
+				}
+				gotB = append(gotB, e.line)
+			}
+			if e.op != ' ' {
+				changes++
+			}
+		}
+		if strings.Join(gotA, "\n") != strings.Join(a, "\n") || strings.Join(gotB, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("diffLines(%q, %q) = %v, does not rebuild texts", a, b, edits)
+		}
+		if want := len(a) + len(b) - 2*lcs(a, b); changes != want {
+			t.Fatalf("diffLines(%q, %q): %v changes, want %v", a, b, changes, want)
+		}
+	}
+}
+
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
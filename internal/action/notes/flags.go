@@ -0,0 +1,85 @@
+package notes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+// Flag логический признак заметки, который владелец включает и выключает
+// отдельно от содержимого.
+type Flag string
+
+const (
+	FlagPinned   Flag = "pinned"
+	FlagArchived Flag = "archived"
+	FlagFavorite Flag = "favorite"
+)
+
+// Flags все признаки заметки.
+var Flags = []Flag{FlagPinned, FlagArchived, FlagFavorite}
+
+func (f Flag) value(n note.Note) bool {
+	switch f {
+	case FlagPinned:
+		return n.Pinned
+	case FlagArchived:
+		return n.Archived
+	}
+	return n.Favorite
+}
+
+type SetFlagArgs struct {
+	OwnerID uuid.UUID `json:"-"`
+	ID      uuid.UUID `json:"id"`
+	Flag    Flag      `json:"flag"`
+	Value   bool      `json:"value"`
+	// Version версия, которую видел клиент (If-Match), 0 - без проверки.
+	Version uint `json:"-"`
+}
+
+type SetFlagAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewSetFlagAction(store Store, log *zap.Logger) *SetFlagAction {
+	return &SetFlagAction{store: store, log: log}
+}
+
+// Do включает или выключает признак заметки. Менять признаки может только
+// владелец. Повторная установка того же значения ничего не записывает,
+// иначе версия заметки растёт, а история не пополняется: содержимое то же.
+func (a *SetFlagAction) Do(ctx context.Context, args SetFlagArgs) (note.Note, error) {
+	if err := authorize(ctx, a.store, args.OwnerID, args.ID, note.PermissionOwner); err != nil {
+		return note.Note{}, err
+	}
+
+	current, err := a.store.GetByID(ctx, args.ID)
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "get note to flag")
+	}
+	if args.Version != 0 && args.Version != current.Version {
+		return note.Note{}, ErrVersionMismatch
+	}
+	if args.Flag.value(current) == args.Value {
+		return current, nil
+	}
+
+	if err := a.store.SetFlag(ctx, args.ID, args.Flag, args.Value, args.Version, time.Now()); err != nil {
+		return note.Note{}, errors.WithMessage(err, "set note flag")
+	}
+
+	a.log.Debug("Set note flag", zap.Any("args", args))
+
+	flagged, err := a.store.GetByID(ctx, args.ID)
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "get flagged note")
+	}
+
+	return flagged, nil
+}
@@ -24,10 +24,15 @@ func NewGetByIDAction(
 	}
 }
 
-func (a *GetByIDAction) Do(ctx context.Context, noteID uuid.UUID) (note.Note, error) {
+// Do возвращает заметку владельцу и пользователям, с которыми ей поделились.
+func (a *GetByIDAction) Do(ctx context.Context, userID, noteID uuid.UUID) (note.Note, error) {
+	if err := authorize(ctx, a.store, userID, noteID, note.PermissionRead); err != nil {
+		return note.Note{}, err
+	}
+
 	n, err := a.store.GetByID(ctx, noteID)
 	switch {
-	case err != nil && (errors.Is(err, NotFound) || err.Error() == NotFound.Error()):
+	case errors.Is(err, NotFound):
 		return note.Note{}, err
 	case err != nil:
 		return note.Note{}, errors.WithMessage(err, "Failed during getting from store")
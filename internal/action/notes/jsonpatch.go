@@ -0,0 +1,373 @@
+package notes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+)
+
+// PatchOperation операция JSON Patch (RFC 6902) над документом
+// {"label": ..., "body": ..., "tags": [...]}.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+var (
+	// ErrInvalidPatch тело не является корректным JSON Patch.
+	ErrInvalidPatch = apperr.Validation("invalid_patch", "patch is not a valid JSON Patch document")
+	// ErrPatchFailed операцию нельзя применить к текущей заметке, в том числе
+	// не прошла проверка test.
+	ErrPatchFailed = apperr.New(apperr.KindUnprocessable, "patch_failed", "patch can not be applied to the note")
+)
+
+// applyOperations применяет args.Operations к заметке n и заполняет в args
+// только те поля, которые изменились.
+func applyOperations(args *PatchArgs, n note.Note) error {
+	if err := validateOperations(args.Operations); err != nil {
+		return err
+	}
+
+	tags := make([]any, 0, len(n.Tags))
+	for _, tag := range n.Tags {
+		tags = append(tags, tag)
+	}
+	var doc any = map[string]any{"label": n.Label, "body": n.Body, "tags": tags}
+
+	for i, op := range args.Operations {
+		var err error
+		doc, err = applyOperation(doc, op)
+		if err != nil {
+			return ErrPatchFailed.WithMessage(fmt.Sprintf("operation %v (%v %v): %v", i, op.Op, op.Path, err))
+		}
+	}
+
+	label, body, patchedTags, err := noteFields(doc)
+	if err != nil {
+		return ErrPatchFailed.WithMessage(err.Error())
+	}
+	if label != n.Label {
+		args.Label = &label
+	}
+	if body != n.Body {
+		args.Body = &body
+	}
+	if !sameTags(patchedTags, n.Tags) {
+		args.Tags = &patchedTags
+	}
+
+	return nil
+}
+
+func validateOperations(ops []PatchOperation) error {
+	var result *apperr.Error
+	fail := func(i int, field, message string) {
+		if result == nil {
+			result = ErrInvalidPatch
+		}
+		result = result.WithField(fmt.Sprintf("operations[%v].%v", i, field), message)
+	}
+
+	for i, op := range ops {
+		switch op.Op {
+		case "add", "replace", "test":
+			if len(op.Value) == 0 {
+				fail(i, "value", "is required")
+			}
+		case "move", "copy":
+			if _, err := parsePointer(op.From); err != nil {
+				fail(i, "from", err.Error())
+			}
+		case "remove":
+		default:
+			fail(i, "op", "must be one of add, remove, replace, move, copy, test")
+		}
+		if _, err := parsePointer(op.Path); err != nil {
+			fail(i, "path", err.Error())
+		}
+	}
+
+	if result != nil {
+		return result
+	}
+
+	return nil
+}
+
+func applyOperation(doc any, op PatchOperation) (any, error) {
+	path, _ := parsePointer(op.Path)
+	from, _ := parsePointer(op.From)
+
+	switch op.Op {
+	case "add":
+		return addValue(doc, path, decodeValue(op.Value))
+	case "remove":
+		return removeValue(doc, path)
+	case "replace":
+		if len(path) == 0 {
+			return decodeValue(op.Value), nil
+		}
+		doc, err := removeValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, decodeValue(op.Value))
+	case "move":
+		if len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+			return nil, fmt.Errorf("can not move %q into itself", op.From)
+		}
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = removeValue(doc, from); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+	case "copy":
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, copyValue(value))
+	default: // test
+		value, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, decodeValue(op.Value)) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	}
+}
+
+// parsePointer разбирает JSON Pointer (RFC 6901), пустая строка - весь документ.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("must be a JSON Pointer starting with /")
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// decodeValue значение уже проверено декодером тела запроса.
+func decodeValue(raw json.RawMessage) any {
+	var value any
+	_ = json.Unmarshal(raw, &value)
+
+	return value
+}
+
+func getValue(doc any, path []string) (any, error) {
+	node := doc
+	for _, token := range path {
+		var err error
+		if node, err = child(node, token); err != nil {
+			return nil, err
+		}
+	}
+
+	return node, nil
+}
+
+func addValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(doc, path, func(container any, token string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			c[token] = value
+			return c, nil
+		case []any:
+			i, err := arrayIndex(token, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			result := append(append(append([]any{}, c[:i]...), value), c[i:]...)
+			return result, nil
+		}
+		return nil, fmt.Errorf("parent of %q is not an object or array", token)
+	})
+}
+
+func removeValue(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("can not remove the whole note")
+	}
+
+	return update(doc, path, func(container any, token string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			if _, ok := c[token]; !ok {
+				return nil, fmt.Errorf("member %q does not exist", token)
+			}
+			delete(c, token)
+			return c, nil
+		case []any:
+			i, err := arrayIndex(token, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(append([]any{}, c[:i]...), c[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("parent of %q is not an object or array", token)
+	})
+}
+
+// update спускается к родителю последнего токена пути и заменяет его
+// результатом change. Массивы при изменении пересоздаются, поэтому новое
+// значение записывается обратно в предка.
+func update(node any, path []string, change func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return change(node, path[0])
+	}
+
+	next, err := child(node, path[0])
+	if err != nil {
+		return nil, err
+	}
+	if next, err = update(next, path[1:], change); err != nil {
+		return nil, err
+	}
+
+	switch c := node.(type) {
+	case map[string]any:
+		c[path[0]] = next
+	case []any:
+		i, _ := arrayIndex(path[0], len(c), false)
+		c[i] = next
+	}
+
+	return node, nil
+}
+
+func child(node any, token string) (any, error) {
+	switch c := node.(type) {
+	case map[string]any:
+		value, ok := c[token]
+		if !ok {
+			return nil, fmt.Errorf("member %q does not exist", token)
+		}
+		return value, nil
+	case []any:
+		i, err := arrayIndex(token, len(c), false)
+		if err != nil {
+			return nil, err
+		}
+		return c[i], nil
+	}
+
+	return nil, fmt.Errorf("%q is not inside an object or array", token)
+}
+
+// arrayIndex номер элемента массива длины length. Для добавления допустимы
+// индекс length и "-", означающие конец массива.
+func arrayIndex(token string, length int, forAdd bool) (int, error) {
+	if token == "-" && forAdd {
+		return length, nil
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%q is not an array index", token)
+	}
+	if i > length || (i == length && !forAdd) {
+		return 0, fmt.Errorf("index %v is out of range", i)
+	}
+
+	return i, nil
+}
+
+func copyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = copyValue(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = copyValue(item)
+		}
+		return result
+	}
+
+	return value
+}
+
+// noteFields достаёт поля заметки из документа после патча. Удалённые
+// label и body становятся пустыми строками, удалённые tags - пустым списком.
+func noteFields(doc any) (label, body string, tags []string, err error) {
+	fields, ok := doc.(map[string]any)
+	if !ok {
+		return "", "", nil, fmt.Errorf("note must stay an object")
+	}
+
+	for key, value := range fields {
+		switch key {
+		case "label", "body":
+			text, ok := value.(string)
+			if !ok {
+				return "", "", nil, fmt.Errorf("%v must be a string", key)
+			}
+			if key == "label" {
+				label = text
+			} else {
+				body = text
+			}
+		case "tags":
+			if value == nil {
+				continue
+			}
+			list, ok := value.([]any)
+			if !ok {
+				return "", "", nil, fmt.Errorf("tags must be an array of strings")
+			}
+			tags = []string{}
+			for _, item := range list {
+				tag, ok := item.(string)
+				if !ok {
+					return "", "", nil, fmt.Errorf("tags must be an array of strings")
+				}
+				tags = append(tags, tag)
+			}
+		default:
+			return "", "", nil, fmt.Errorf("field %q can not be patched", key)
+		}
+	}
+
+	return label, body, tags, nil
+}
+
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,213 @@
+package notes
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+)
+
+func TestApplyOperations(t *testing.T) {
+	current := note.Note{Label: "label", Body: "body", Tags: []string{"a", "b"}}
+	op := func(op, path, from, value string) PatchOperation {
+		return PatchOperation{Op: op, Path: path, From: from, Value: json.RawMessage(value)}
+	}
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name string
+		ops  []PatchOperation
+		// Ожидаемые поля PatchArgs, nil - поле не изменилось.
+		label, body *string
+		tags        *[]string
+		err         error
+	}{
+		{
+			name:  "Replace",
+			ops:   []PatchOperation{op("replace", "/label", "", `"new"`)},
+			label: str("new"),
+		},
+		{
+			name: "TestPasses",
+			ops:  []PatchOperation{op("test", "/tags", "", `["a","b"]`), op("replace", "/body", "", `"text"`)},
+			body: str("text"),
+		},
+		{
+			name: "TestFails",
+			ops:  []PatchOperation{op("test", "/label", "", `"other"`), op("replace", "/body", "", `"text"`)},
+			err:  ErrPatchFailed,
+		},
+		{
+			name: "AppendWithDash",
+			ops:  []PatchOperation{op("add", "/tags/-", "", `"c"`)},
+			tags: &[]string{"a", "b", "c"},
+		},
+		{
+			name: "AppendAtLength",
+			ops:  []PatchOperation{op("add", "/tags/2", "", `"c"`)},
+			tags: &[]string{"a", "b", "c"},
+		},
+		{
+			name: "InsertAtIndex",
+			ops:  []PatchOperation{op("add", "/tags/0", "", `"c"`)},
+			tags: &[]string{"c", "a", "b"},
+		},
+		{
+			name: "DashOutsideAdd",
+			ops:  []PatchOperation{op("remove", "/tags/-", "", "")},
+			err:  ErrPatchFailed,
+		},
+		{
+			name: "IndexOutOfRange",
+			ops:  []PatchOperation{op("add", "/tags/3", "", `"c"`)},
+			err:  ErrPatchFailed,
+		},
+		{
+			name: "LeadingZeroIndex",
+			ops:  []PatchOperation{op("remove", "/tags/01", "", "")},
+			err:  ErrPatchFailed,
+		},
+		{
+			name: "NegativeIndex",
+			ops:  []PatchOperation{op("remove", "/tags/-1", "", "")},
+			err:  ErrPatchFailed,
+		},
+		{
+			name: "RemoveTag",
+			ops:  []PatchOperation{op("remove", "/tags/0", "", "")},
+			tags: &[]string{"b"},
+		},
+		{
+			name: "RemoveTags",
+			ops:  []PatchOperation{op("remove", "/tags", "", "")},
+			tags: &[]string{},
+		},
+		{
+			name:  "RemoveLabel",
+			ops:   []PatchOperation{op("remove", "/label", "", "")},
+			label: str(""),
+		},
+		{
+			name: "RemoveMissing",
+			ops:  []PatchOperation{op("remove", "/missing", "", "")},
+			err:  ErrPatchFailed,
+		},
+		{
+			name: "MoveIntoChild",
+			ops:  []PatchOperation{op("move", "/tags/0", "/tags", "")},
+			err:  ErrPatchFailed,
+		},
+		{
+			name:  "MoveToLabel",
+			ops:   []PatchOperation{op("move", "/label", "/tags/1", "")},
+			label: str("b"),
+			tags:  &[]string{"a"},
+		},
+		{
+			name: "Copy",
+			ops:  []PatchOperation{op("copy", "/tags/-", "/tags/0", "")},
+			tags: &[]string{"a", "b", "a"},
+		},
+		{
+			name: "NonStringTag",
+			ops:  []PatchOperation{op("add", "/tags/-", "", `1`)},
+			err:  ErrPatchFailed,
+		},
+		{
+			name: "NonStringLabel",
+			ops:  []PatchOperation{op("replace", "/label", "", `{"a":1}`)},
+			err:  ErrPatchFailed,
+		},
+		{
+			name: "UnknownField",
+			ops:  []PatchOperation{op("add", "/color", "", `"red"`)},
+			err:  ErrPatchFailed,
+		},
+		{
+			name: "EscapedUnknownField",
+			ops:  []PatchOperation{op("add", "/a~1b", "", `"x"`)},
+			err:  ErrPatchFailed,
+		},
+		{
+			name: "UnknownOp",
+			ops:  []PatchOperation{op("merge", "/label", "", `"x"`)},
+			err:  ErrInvalidPatch,
+		},
+		{
+			name: "MissingValue",
+			ops:  []PatchOperation{op("add", "/label", "", "")},
+			err:  ErrInvalidPatch,
+		},
+		{
+			name: "RelativePath",
+			ops:  []PatchOperation{op("remove", "label", "", "")},
+			err:  ErrInvalidPatch,
+		},
+		{
+			name: "AtomicOnFailure",
+			ops:  []PatchOperation{op("replace", "/label", "", `"new"`), op("remove", "/missing", "", "")},
+			err:  ErrPatchFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			args := PatchArgs{Operations: tt.ops}
+			err := applyOperations(&args, current)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("applyOperations error = %v, want %v", err, tt.err)
+				}
+				if e, _ := apperr.As(err); tt.err == ErrPatchFailed && e.Kind != apperr.KindUnprocessable {
+					t.Errorf("Kind = %v, want %v", e.Kind, apperr.KindUnprocessable)
+				}
+				if !args.empty() {
+					t.Errorf("args = %+v, want no changes after failed patch", args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyOperations: %v", err)
+			}
+
+			assertField(t, "Label", args.Label, tt.label)
+			assertField(t, "Body", args.Body, tt.body)
+			switch {
+			case tt.tags == nil && args.Tags != nil:
+				t.Errorf("Tags = %q, want unchanged", *args.Tags)
+			case tt.tags != nil && args.Tags == nil:
+				t.Errorf("Tags unchanged, want %q", *tt.tags)
+			case tt.tags != nil && !sameTags(*args.Tags, *tt.tags):
+				t.Errorf("Tags = %q, want %q", *args.Tags, *tt.tags)
+			}
+		})
+	}
+}
+
+func assertField(t *testing.T, name string, got, want *string) {
+	t.Helper()
+
+	switch {
+	case want == nil && got != nil:
+		t.Errorf("%v = %q, want unchanged", name, *got)
+	case want != nil && got == nil:
+		t.Errorf("%v unchanged, want %q", name, *want)
+	case want != nil && *got != *want:
+		t.Errorf("%v = %q, want %q", name, *got, *want)
+	}
+}
+
+func TestApplyOperationsDoesNotChangeNote(t *testing.T) {
+	current := note.Note{Label: "label", Tags: []string{"a"}}
+	args := PatchArgs{Operations: []PatchOperation{{Op: "replace", Path: "/tags/0", Value: json.RawMessage(`"b"`)}}}
+
+	if err := applyOperations(&args, current); err != nil {
+		t.Fatalf("applyOperations: %v", err)
+	}
+	if current.Tags[0] != "a" {
+		t.Errorf("note tags = %q, want original", current.Tags)
+	}
+}
@@ -0,0 +1,137 @@
+package notes
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+// linkTokenSize 256 бит, токен ссылки невозможно подобрать.
+const linkTokenSize = 32
+
+type CreateLinkArgs struct {
+	// ExpiresAt без срока ссылка действует до отзыва.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type CreateLinkAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewCreateLinkAction(store Store, log *zap.Logger) *CreateLinkAction {
+	return &CreateLinkAction{store: store, log: log}
+}
+
+// Do создаёт публичную ссылку на заметку владельца. Прежняя ссылка перестаёт работать.
+func (a *CreateLinkAction) Do(ctx context.Context, ownerID, noteID uuid.UUID, args CreateLinkArgs) (note.Link, error) {
+	now := time.Now()
+	if args.ExpiresAt != nil && !args.ExpiresAt.After(now) {
+		return note.Link{}, apperr.Validation("link_invalid", "link is invalid",
+			apperr.FieldError{Field: "expires_at", Message: "must be in the future"})
+	}
+
+	if err := authorize(ctx, a.store, ownerID, noteID, note.PermissionOwner); err != nil {
+		return note.Link{}, err
+	}
+
+	secret := make([]byte, linkTokenSize)
+	if _, err := rand.Read(secret); err != nil {
+		return note.Link{}, errors.Wrap(err, "generate link token")
+	}
+
+	link := note.Link{
+		NoteID:    noteID,
+		OwnerID:   ownerID,
+		Token:     base64.RawURLEncoding.EncodeToString(secret),
+		CreatedAt: now,
+		ExpiresAt: args.ExpiresAt,
+	}
+	if err := a.store.SaveLink(ctx, link); err != nil {
+		return note.Link{}, errors.WithMessage(err, "save link")
+	}
+
+	a.log.Debug("Created public link", zap.Any("noteID", noteID))
+
+	return link, nil
+}
+
+type GetLinkAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewGetLinkAction(store Store, log *zap.Logger) *GetLinkAction {
+	return &GetLinkAction{store: store, log: log}
+}
+
+func (a *GetLinkAction) Do(ctx context.Context, ownerID, noteID uuid.UUID) (note.Link, error) {
+	if err := authorize(ctx, a.store, ownerID, noteID, note.PermissionOwner); err != nil {
+		return note.Link{}, err
+	}
+
+	link, err := a.store.GetLink(ctx, noteID)
+	if err != nil {
+		return note.Link{}, errors.WithMessage(err, "get link")
+	}
+
+	return link, nil
+}
+
+type DeleteLinkAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewDeleteLinkAction(store Store, log *zap.Logger) *DeleteLinkAction {
+	return &DeleteLinkAction{store: store, log: log}
+}
+
+func (a *DeleteLinkAction) Do(ctx context.Context, ownerID, noteID uuid.UUID) error {
+	if err := authorize(ctx, a.store, ownerID, noteID, note.PermissionOwner); err != nil {
+		return err
+	}
+
+	if err := a.store.DeleteLink(ctx, noteID); err != nil {
+		return errors.WithMessage(err, "delete link")
+	}
+
+	a.log.Debug("Revoked public link", zap.Any("noteID", noteID))
+
+	return nil
+}
+
+type GetByLinkAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewGetByLinkAction(store Store, log *zap.Logger) *GetByLinkAction {
+	return &GetByLinkAction{store: store, log: log}
+}
+
+// Do открывает заметку по токену ссылки от имени владельца. Отозванная,
+// истёкшая и неизвестная ссылки неразличимы: все дают ErrLinkNotFound.
+func (a *GetByLinkAction) Do(ctx context.Context, token string) (note.Note, error) {
+	link, err := a.store.GetLinkByToken(ctx, token)
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "get link")
+	}
+	if link.Expired(time.Now()) {
+		return note.Note{}, ErrLinkNotFound
+	}
+
+	n, err := NewGetByIDAction(a.store, a.log).Do(ctx, link.OwnerID, link.NoteID)
+	if errors.Is(err, NotFound) {
+		return note.Note{}, ErrLinkNotFound
+	}
+
+	return n, err
+}
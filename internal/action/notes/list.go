@@ -2,8 +2,11 @@ package notes
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
 	"github.com/victor8titov/rest-api-notes/internal/entity/note"
 	"go.uber.org/zap"
 )
@@ -11,8 +14,9 @@ import (
 type SortField string
 
 const (
-	SortFieldLabel SortField = "label"
-	SortFieldDate  SortField = "created_at"
+	SortFieldLabel   SortField = "label"
+	SortFieldDate    SortField = "created_at"
+	SortFieldUpdated SortField = "updated_at"
 )
 
 type SortDirection int
@@ -22,39 +26,133 @@ const (
 	SortDirectionDesc
 )
 
+// Filter условия отбора заметок, общие для списка и подсчёта.
+type Filter struct {
+	// OwnerID владелец заметок, условие применяется всегда, если не задан SharedWith.
+	OwnerID uuid.UUID `json:"-"`
+	// SharedWith вместо заметок владельца отбираются заметки, которыми
+	// поделились с этим пользователем.
+	SharedWith uuid.UUID `json:"-"`
+	// TagsAll заметка содержит все перечисленные теги.
+	TagsAll []string `json:"tagsAll,omitempty"`
+	// TagsAny заметка содержит хотя бы один из тегов.
+	TagsAny []string `json:"tagsAny,omitempty"`
+	// TagsNone заметка не содержит ни одного из тегов.
+	TagsNone []string `json:"tagsNone,omitempty"`
+	// ModifiedSince заметка изменена в этот момент или позже, нулевое время - без ограничения.
+	ModifiedSince time.Time `json:"modifiedSince,omitempty"`
+	// CreatedBefore заметка создана строго раньше, нулевое время - без ограничения.
+	CreatedBefore time.Time `json:"createdBefore,omitempty"`
+	// Trashed вместо обычных заметок отбираются заметки из корзины.
+	Trashed bool `json:"-"`
+	// NotebookID заметки этого блокнота, uuid.Nil - без ограничения.
+	NotebookID uuid.UUID `json:"notebookId,omitempty"`
+	// Recursive вместе с NotebookID добавляет заметки вложенных блокнотов.
+	Recursive bool `json:"recursive,omitempty"`
+	// Pinned, Archived и Favorite отбирают заметки с флагом или без него, nil - без ограничения.
+	Pinned   *bool `json:"pinned,omitempty"`
+	Archived *bool `json:"archived,omitempty"`
+	Favorite *bool `json:"favorite,omitempty"`
+}
+
 type ListArgs struct {
+	Filter
 	SortBy        SortField     `json:"sortBy"`
 	SortDirection SortDirection `json:"direction"`
 	Offset        uint          `json:"offset"`
 	Limit         uint          `json:"limit"`
+	// Cursor курсор из nextCursor/prevCursor предыдущей страницы. Несовместим с Offset.
+	Cursor string `json:"cursor"`
+	// WithTotal посчитать общее число заметок. Требует отдельного COUNT(*).
+	WithTotal bool `json:"withTotal"`
+	// Keyset позиция, разобранная из Cursor. Заполняется действием для хранилища.
+	Keyset *Keyset `json:"-"`
 }
 
 type ListAction struct {
-	store Store
-	log   *zap.Logger
+	store   Store
+	cursors *CursorCodec
+	log     *zap.Logger
 }
 
-func NewListAction(store Store, log *zap.Logger) *ListAction {
-	return &ListAction{store: store, log: log}
+func NewListAction(store Store, cursors *CursorCodec, log *zap.Logger) *ListAction {
+	return &ListAction{store: store, cursors: cursors, log: log}
 }
 
 func (a *ListAction) Do(ctx context.Context, args ListArgs) (note.ListNotes, error) {
-	result, err := a.store.Query(ctx, args)
+	if args.Cursor != "" {
+		if args.Offset > 0 {
+			return note.ListNotes{}, apperr.Validation(ErrInvalidCursor.Code, ErrInvalidCursor.Message,
+				apperr.FieldError{Field: "offset", Message: "can not be combined with cursor"})
+		}
+
+		keyset, err := a.cursors.Decode(args.Cursor, args)
+		if err != nil {
+			return note.ListNotes{}, err
+		}
+		args.Keyset = &keyset
+	}
+
+	if args.NotebookID != uuid.Nil && args.SharedWith == uuid.Nil {
+		if _, err := ownNotebook(ctx, a.store, args.OwnerID, &args.NotebookID); err != nil {
+			return note.ListNotes{}, err
+		}
+	}
+
+	// Запрашиваем на одну заметку больше, чтобы узнать, есть ли следующая страница.
+	query := args
+	if args.Limit > 0 {
+		query.Limit = args.Limit + 1
+	}
+
+	result, err := a.store.Query(ctx, query)
 	switch {
-	case errors.Cause(err) == NotFound || errors.Is(err, NotFound):
-		return note.ListNotes{}, nil
+	case errors.Is(err, NotFound):
+		result = []note.Note{}
 	case err != nil:
 		return note.ListNotes{}, errors.WithMessage(err, "list notes")
 	}
 
-	total, err := a.store.Count(ctx)
-	if err != nil {
-		return note.ListNotes{}, errors.WithMessage(err, "list notes")
+	hasMore := args.Limit > 0 && uint(len(result)) > args.Limit
+	if hasMore {
+		result = result[:args.Limit]
+	}
+
+	backward := args.Keyset != nil && args.Keyset.Backward
+	if backward {
+		// Хранилище отдаёт страницу назад в обратном порядке, начиная с ближайшей к курсору.
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+
+	list := note.ListNotes{Notes: result}
+
+	if len(result) > 0 {
+		hasNext := hasMore || backward
+		hasPrev := args.Offset > 0 || (args.Keyset != nil && !backward) || (backward && hasMore)
+
+		if hasNext {
+			list.NextCursor, err = a.cursors.Encode(args, result[len(result)-1], false)
+			if err != nil {
+				return note.ListNotes{}, errors.WithMessage(err, "list notes")
+			}
+		}
+		if hasPrev {
+			list.PrevCursor, err = a.cursors.Encode(args, result[0], true)
+			if err != nil {
+				return note.ListNotes{}, errors.WithMessage(err, "list notes")
+			}
+		}
 	}
 
-	return note.ListNotes{
-		Notes: result,
-		Total: total,
-	}, nil
+	if args.WithTotal {
+		total, err := a.store.Count(ctx, args.Filter)
+		if err != nil {
+			return note.ListNotes{}, errors.WithMessage(err, "list notes")
+		}
+		list.Total = &total
+	}
 
+	return list, nil
 }
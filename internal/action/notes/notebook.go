@@ -0,0 +1,272 @@
+package notes
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotebookNotFound = apperr.NotFound("notebook_not_found", "notebook not found")
+	// ErrNotebookCycle блокнот нельзя вложить в самого себя или в свой потомок.
+	ErrNotebookCycle = apperr.Validation("notebook_cycle", "notebook can not be moved into itself or its descendant",
+		apperr.FieldError{Field: "parentId", Message: "is inside the notebook"})
+)
+
+// NotebookDeletePolicy что происходит с содержимым удаляемого блокнота.
+type NotebookDeletePolicy string
+
+const (
+	// NotebookDeleteMove вложенные блокноты и заметки переходят к родителю
+	// удалённого блокнота или в корень.
+	NotebookDeleteMove NotebookDeletePolicy = "move"
+	// NotebookDeleteTrash вложенные блокноты удаляются, все их заметки
+	// попадают в корзину и при восстановлении оказываются в корне.
+	NotebookDeleteTrash NotebookDeletePolicy = "trash"
+)
+
+type CreateNotebookArgs struct {
+	OwnerID  uuid.UUID  `json:"-"`
+	Name     string     `json:"name"`
+	ParentID *uuid.UUID `json:"parentId"`
+}
+
+type CreateNotebookAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewCreateNotebookAction(store Store, log *zap.Logger) *CreateNotebookAction {
+	return &CreateNotebookAction{store: store, log: log}
+}
+
+func (a *CreateNotebookAction) Do(ctx context.Context, args CreateNotebookArgs) (note.Notebook, error) {
+	notebook := note.Notebook{
+		ID:        uuid.UUID(ulid.Make()),
+		OwnerID:   args.OwnerID,
+		ParentID:  args.ParentID,
+		Name:      strings.TrimSpace(args.Name),
+		CreatedAt: time.Now(),
+	}
+	notebook.UpdatedAt = notebook.CreatedAt
+
+	if err := notebook.Validate(); err != nil {
+		return note.Notebook{}, err
+	}
+	if err := checkParent(ctx, a.store, notebook); err != nil {
+		return note.Notebook{}, err
+	}
+
+	if err := a.store.CreateNotebook(ctx, notebook); err != nil {
+		return note.Notebook{}, errors.WithMessage(err, "create notebook")
+	}
+
+	a.log.Debug("Created notebook", zap.Any("notebook", notebook))
+
+	return notebook, nil
+}
+
+type ListNotebooksAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewListNotebooksAction(store Store, log *zap.Logger) *ListNotebooksAction {
+	return &ListNotebooksAction{store: store, log: log}
+}
+
+// Do возвращает все блокноты владельца плоским списком, дерево строится по parentId.
+func (a *ListNotebooksAction) Do(ctx context.Context, ownerID uuid.UUID) ([]note.Notebook, error) {
+	list, err := a.store.ListNotebooks(ctx, ownerID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "list notebooks")
+	}
+
+	return list, nil
+}
+
+type GetNotebookAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewGetNotebookAction(store Store, log *zap.Logger) *GetNotebookAction {
+	return &GetNotebookAction{store: store, log: log}
+}
+
+func (a *GetNotebookAction) Do(ctx context.Context, ownerID, id uuid.UUID) (note.Notebook, error) {
+	notebook, err := a.store.GetNotebook(ctx, ownerID, id)
+	if err != nil {
+		return note.Notebook{}, errors.WithMessage(err, "get notebook")
+	}
+
+	return notebook, nil
+}
+
+type UpdateNotebookArgs struct {
+	OwnerID uuid.UUID `json:"-"`
+	ID      uuid.UUID `json:"-"`
+	Name    string    `json:"name"`
+	// ParentID новый родитель, nil переносит блокнот в корень.
+	ParentID *uuid.UUID `json:"parentId"`
+}
+
+type UpdateNotebookAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewUpdateNotebookAction(store Store, log *zap.Logger) *UpdateNotebookAction {
+	return &UpdateNotebookAction{store: store, log: log}
+}
+
+// Do переименовывает блокнот и переносит его вместе с содержимым к другому родителю.
+func (a *UpdateNotebookAction) Do(ctx context.Context, args UpdateNotebookArgs) (note.Notebook, error) {
+	notebook, err := a.store.GetNotebook(ctx, args.OwnerID, args.ID)
+	if err != nil {
+		return note.Notebook{}, errors.WithMessage(err, "get notebook to update")
+	}
+
+	notebook.Name = strings.TrimSpace(args.Name)
+	notebook.ParentID = args.ParentID
+	notebook.UpdatedAt = time.Now()
+
+	if err := notebook.Validate(); err != nil {
+		return note.Notebook{}, err
+	}
+	if err := checkParent(ctx, a.store, notebook); err != nil {
+		return note.Notebook{}, err
+	}
+
+	if err := a.store.UpdateNotebook(ctx, notebook); err != nil {
+		return note.Notebook{}, errors.WithMessage(err, "update notebook")
+	}
+
+	a.log.Debug("Updated notebook", zap.Any("notebook", notebook))
+
+	return notebook, nil
+}
+
+// checkParent проверяет, что родитель блокнота принадлежит тому же владельцу
+// и не находится внутри самого блокнота.
+func checkParent(ctx context.Context, store Store, notebook note.Notebook) error {
+	if notebook.ParentID == nil {
+		return nil
+	}
+
+	_, err := store.GetNotebook(ctx, notebook.OwnerID, *notebook.ParentID)
+	if errors.Is(err, ErrNotebookNotFound) {
+		return ErrNotebookNotFound.WithField("parentId", "does not exist")
+	}
+	if err != nil {
+		return errors.WithMessage(err, "get parent notebook")
+	}
+
+	list, err := store.ListNotebooks(ctx, notebook.OwnerID)
+	if err != nil {
+		return errors.WithMessage(err, "list notebooks")
+	}
+	parents := make(map[uuid.UUID]*uuid.UUID, len(list))
+	for _, nb := range list {
+		parents[nb.ID] = nb.ParentID
+	}
+
+	seen := map[uuid.UUID]bool{}
+	for current := notebook.ParentID; current != nil && !seen[*current]; current = parents[*current] {
+		if *current == notebook.ID {
+			return ErrNotebookCycle
+		}
+		seen[*current] = true
+	}
+
+	return nil
+}
+
+type DeleteNotebookAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewDeleteNotebookAction(store Store, log *zap.Logger) *DeleteNotebookAction {
+	return &DeleteNotebookAction{store: store, log: log}
+}
+
+func (a *DeleteNotebookAction) Do(ctx context.Context, ownerID, id uuid.UUID, policy NotebookDeletePolicy) error {
+	if err := a.store.DeleteNotebook(ctx, ownerID, id, policy, time.Now()); err != nil {
+		return errors.WithMessage(err, "delete notebook")
+	}
+
+	a.log.Debug("Deleted notebook", zap.Any("notebookID", id), zap.String("policy", string(policy)))
+
+	return nil
+}
+
+type MoveNotesArgs struct {
+	OwnerID uuid.UUID   `json:"-"`
+	NoteIDs []uuid.UUID `json:"noteId"`
+	// NotebookID блокнот назначения, nil - корень.
+	NotebookID *uuid.UUID `json:"notebookId"`
+}
+
+type MoveNotesAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewMoveNotesAction(store Store, log *zap.Logger) *MoveNotesAction {
+	return &MoveNotesAction{store: store, log: log}
+}
+
+// Do переносит заметки владельца в блокнот и возвращает их число. Как и
+// DeleteAction, отказывает целиком, если среди заметок есть чужая доступная,
+// и пропускает невидимые.
+func (a *MoveNotesAction) Do(ctx context.Context, args MoveNotesArgs) (uint, error) {
+	notebookID, err := ownNotebook(ctx, a.store, args.OwnerID, args.NotebookID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range args.NoteIDs {
+		err := authorize(ctx, a.store, args.OwnerID, id, note.PermissionOwner)
+		if errors.Is(err, NotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	count, err := a.store.MoveNotes(ctx, args.OwnerID, args.NoteIDs, notebookID, time.Now())
+	if err != nil {
+		return 0, errors.WithMessage(err, "move notes")
+	}
+
+	a.log.Debug("Moved notes", zap.Any("args", args), zap.Uint("count", count))
+
+	return count, nil
+}
+
+// ownNotebook проверяет, что блокнот принадлежит владельцу. nil - корень,
+// для него возвращается uuid.Nil.
+func ownNotebook(ctx context.Context, store Store, ownerID uuid.UUID, notebookID *uuid.UUID) (uuid.UUID, error) {
+	if notebookID == nil || *notebookID == uuid.Nil {
+		return uuid.Nil, nil
+	}
+
+	_, err := store.GetNotebook(ctx, ownerID, *notebookID)
+	if errors.Is(err, ErrNotebookNotFound) {
+		return uuid.Nil, ErrNotebookNotFound.WithField("notebookId", "does not exist")
+	}
+	if err != nil {
+		return uuid.Nil, errors.WithMessage(err, "get notebook")
+	}
+
+	return *notebookID, nil
+}
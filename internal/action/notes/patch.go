@@ -0,0 +1,98 @@
+package notes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+// PatchArgs частичное изменение заметки: nil-поле остаётся прежним.
+type PatchArgs struct {
+	// UserID кто изменяет заметку: владелец или пользователь с правом write.
+	UserID uuid.UUID `json:"-"`
+	ID     uuid.UUID `json:"id"`
+	Label  *string   `json:"label,omitempty"`
+	Body   *string   `json:"body,omitempty"`
+	// Tags указатель на nil убирает все теги.
+	Tags *[]string `json:"tags,omitempty"`
+	// Operations операции JSON Patch (RFC 6902). Действие применяет их к
+	// текущей заметке и заполняет поля выше, хранилище их не видит.
+	Operations []PatchOperation `json:"operations,omitempty"`
+	// Version версия, которую видел клиент (If-Match), 0 - без проверки.
+	Version uint `json:"-"`
+	// UpdatedAt время изменения, его выставляет действие.
+	UpdatedAt time.Time `json:"-"`
+}
+
+func (a PatchArgs) empty() bool {
+	return a.Label == nil && a.Body == nil && a.Tags == nil
+}
+
+type PatchAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewPatchAction(store Store, log *zap.Logger) *PatchAction {
+	return &PatchAction{store: store, log: log}
+}
+
+// Do меняет только переданные поля. Результат проверяется note.Note.Validate
+// целиком, как при создании. Патч без изменений ничего не записывает.
+func (a *PatchAction) Do(ctx context.Context, args PatchArgs) (note.Note, error) {
+	if err := authorize(ctx, a.store, args.UserID, args.ID, note.PermissionWrite); err != nil {
+		return note.Note{}, err
+	}
+
+	current, err := a.store.GetByID(ctx, args.ID)
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "get note to patch")
+	}
+	if args.Version != 0 && args.Version != current.Version {
+		return note.Note{}, ErrVersionMismatch
+	}
+
+	if len(args.Operations) > 0 {
+		if err := applyOperations(&args, current); err != nil {
+			return note.Note{}, err
+		}
+		// Операции зависят от прочитанного состояния, поэтому записываются
+		// только поверх него, даже при If-Match: *.
+		args.Version = current.Version
+	}
+	if args.empty() {
+		return current, nil
+	}
+
+	patched := current
+	if args.Label != nil {
+		patched.Label = *args.Label
+	}
+	if args.Body != nil {
+		patched.Body = *args.Body
+	}
+	if args.Tags != nil {
+		patched.Tags = *args.Tags
+	}
+	if err := patched.Validate(); err != nil {
+		return note.Note{}, errors.WithMessage(err, "validate patched note")
+	}
+
+	args.UpdatedAt = time.Now()
+	if err := a.store.Patch(ctx, args); err != nil {
+		return note.Note{}, errors.WithMessage(err, "patch note")
+	}
+
+	updated, err := a.store.GetByID(ctx, args.ID)
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "get patched note")
+	}
+
+	a.log.Debug("Patched note", zap.Any("note", updated))
+
+	return updated, nil
+}
@@ -0,0 +1,35 @@
+package notes
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type RenderAction struct {
+	get      *GetByIDAction
+	renderer Renderer
+	log      *zap.Logger
+}
+
+func NewRenderAction(store Store, renderer Renderer, log *zap.Logger) *RenderAction {
+	return &RenderAction{get: NewGetByIDAction(store, log), renderer: renderer, log: log}
+}
+
+// Do возвращает заметку с текстом в HTML тем же пользователям, что и GetByIDAction.
+func (a *RenderAction) Do(ctx context.Context, userID, noteID uuid.UUID) (note.RenderedNote, error) {
+	n, err := a.get.Do(ctx, userID, noteID)
+	if err != nil {
+		return note.RenderedNote{}, err
+	}
+
+	html, toc, err := a.renderer.Render(n.Body)
+	if err != nil {
+		return note.RenderedNote{}, errors.WithMessage(err, "render note")
+	}
+
+	return note.RenderedNote{Note: n, HTML: html, TOC: toc}, nil
+}
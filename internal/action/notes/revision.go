@@ -0,0 +1,127 @@
+package notes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type ListRevisionsAction struct {
+	store Store
+	users UserFinder
+	log   *zap.Logger
+}
+
+func NewListRevisionsAction(store Store, users UserFinder, log *zap.Logger) *ListRevisionsAction {
+	return &ListRevisionsAction{store: store, users: users, log: log}
+}
+
+// Do история заметки от новых ревизий к старым, доступна всем, кто может её читать.
+func (a *ListRevisionsAction) Do(ctx context.Context, userID, noteID uuid.UUID) ([]note.Revision, error) {
+	if err := authorize(ctx, a.store, userID, noteID, note.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	revisions, err := a.store.ListRevisions(ctx, noteID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "list revisions")
+	}
+
+	emails := map[uuid.UUID]string{}
+	for i := range revisions {
+		authorID := revisions[i].AuthorID
+		if authorID == uuid.Nil {
+			continue
+		}
+		email, ok := emails[authorID]
+		if !ok {
+			author, err := a.users.GetByID(ctx, authorID)
+			// Удалённый автор остаётся в истории без почты.
+			if e, ok := apperr.As(err); ok && e.Kind == apperr.KindNotFound {
+				err = nil
+			}
+			if err != nil {
+				return nil, errors.WithMessage(err, "find revision author")
+			}
+			email = author.Email
+			emails[authorID] = email
+		}
+		revisions[i].AuthorEmail = email
+	}
+
+	return revisions, nil
+}
+
+type DiffRevisionsAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewDiffRevisionsAction(store Store, log *zap.Logger) *DiffRevisionsAction {
+	return &DiffRevisionsAction{store: store, log: log}
+}
+
+// Do unified diff между ревизиями from и to, пустая строка, если они совпадают.
+func (a *DiffRevisionsAction) Do(ctx context.Context, userID, noteID uuid.UUID, from, to uint) (string, error) {
+	if err := authorize(ctx, a.store, userID, noteID, note.PermissionRead); err != nil {
+		return "", err
+	}
+
+	fromRevision, err := a.store.GetRevision(ctx, noteID, from)
+	if err != nil {
+		return "", errors.WithMessage(err, "get revision from")
+	}
+	toRevision, err := a.store.GetRevision(ctx, noteID, to)
+	if err != nil {
+		return "", errors.WithMessage(err, "get revision to")
+	}
+
+	return unifiedDiff(
+		fmt.Sprintf("rev %v", from),
+		fmt.Sprintf("rev %v", to),
+		fromRevision.Text(),
+		toRevision.Text(),
+	)
+}
+
+type RestoreRevisionAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewRestoreRevisionAction(store Store, log *zap.Logger) *RestoreRevisionAction {
+	return &RestoreRevisionAction{store: store, log: log}
+}
+
+// Do возвращает заметке содержимое старой ревизии. История не переписывается:
+// восстановление сохраняется новой ревизией от имени userID.
+func (a *RestoreRevisionAction) Do(ctx context.Context, userID, noteID uuid.UUID, rev uint) (note.Note, error) {
+	if err := authorize(ctx, a.store, userID, noteID, note.PermissionWrite); err != nil {
+		return note.Note{}, err
+	}
+
+	revision, err := a.store.GetRevision(ctx, noteID, rev)
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "get revision")
+	}
+
+	restored, err := NewUpdateAction(a.store, a.log).Do(ctx, UpdateArgs{
+		UserID: userID,
+		ID:     noteID,
+		Label:  revision.Label,
+		Body:   revision.Body,
+		Tags:   revision.Tags,
+	})
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "restore revision")
+	}
+
+	a.log.Debug("Restored note revision", zap.Any("noteID", noteID), zap.Uint("rev", rev))
+
+	return restored, nil
+}
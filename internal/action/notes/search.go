@@ -0,0 +1,57 @@
+package notes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+var ErrEmptySearchQuery = apperr.Validation("search_query_empty", "search query is empty", apperr.FieldError{Field: "q", Message: "is required"})
+
+type SearchArgs struct {
+	OwnerID uuid.UUID `json:"-"`
+	Query   string    `json:"q"`
+	Offset  uint      `json:"offset"`
+	Limit   uint      `json:"limit"`
+}
+
+type SearchAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewSearchAction(store Store, log *zap.Logger) *SearchAction {
+	return &SearchAction{store: store, log: log}
+}
+
+func (a *SearchAction) Do(ctx context.Context, args SearchArgs) (note.SearchNotes, error) {
+	args.Query = strings.TrimSpace(args.Query)
+	if args.Query == "" {
+		return note.SearchNotes{}, ErrEmptySearchQuery
+	}
+
+	total, err := a.store.SearchCount(ctx, args)
+	if err != nil {
+		return note.SearchNotes{}, errors.WithMessage(err, "search notes")
+	}
+
+	found := []note.FoundNote{}
+	if total > args.Offset {
+		found, err = a.store.Search(ctx, args)
+		if err != nil {
+			return note.SearchNotes{}, errors.WithMessage(err, "search notes")
+		}
+	}
+
+	a.log.Debug("Searched notes", zap.String("query", args.Query), zap.Uint("total", total))
+
+	return note.SearchNotes{
+		Notes: found,
+		Total: total,
+	}, nil
+}
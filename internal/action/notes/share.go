@@ -0,0 +1,130 @@
+package notes
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+var ErrShareInvalid = apperr.Validation("share_invalid", "share is invalid")
+
+type ShareArgs struct {
+	// Email пользователя, которому выдаётся доступ.
+	Email      string          `json:"email"`
+	Permission note.Permission `json:"permission"`
+}
+
+type ShareAction struct {
+	store Store
+	users UserFinder
+	log   *zap.Logger
+}
+
+func NewShareAction(store Store, users UserFinder, log *zap.Logger) *ShareAction {
+	return &ShareAction{store: store, users: users, log: log}
+}
+
+// Do выдаёт пользователю с args.Email право на заметку. Делиться может только
+// владелец, повторный вызов меняет право.
+func (a *ShareAction) Do(ctx context.Context, ownerID, noteID uuid.UUID, args ShareArgs) (note.Share, error) {
+	if !args.Permission.Grantable() {
+		return note.Share{}, ErrShareInvalid.WithField("permission", "must be one of read, write")
+	}
+
+	if err := authorize(ctx, a.store, ownerID, noteID, note.PermissionOwner); err != nil {
+		return note.Share{}, err
+	}
+
+	grantee, err := a.users.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(args.Email)))
+	if err != nil {
+		if e, ok := apperr.As(err); ok && e.Kind == apperr.KindNotFound {
+			return note.Share{}, ErrShareInvalid.WithField("email", "no user with this email")
+		}
+		return note.Share{}, errors.WithMessage(err, "find grantee")
+	}
+	if grantee.ID == ownerID {
+		return note.Share{}, ErrShareInvalid.WithField("email", "can not share a note with its owner")
+	}
+
+	share := note.Share{
+		NoteID:     noteID,
+		UserID:     grantee.ID,
+		Permission: args.Permission,
+		CreatedAt:  time.Now(),
+	}
+	if err := a.store.Share(ctx, share); err != nil {
+		return note.Share{}, errors.WithMessage(err, "share note")
+	}
+	share.Email = grantee.Email
+
+	a.log.Debug("Shared note", zap.Any("noteID", noteID), zap.Any("userID", grantee.ID))
+
+	return share, nil
+}
+
+type ListSharesAction struct {
+	store Store
+	users UserFinder
+	log   *zap.Logger
+}
+
+func NewListSharesAction(store Store, users UserFinder, log *zap.Logger) *ListSharesAction {
+	return &ListSharesAction{store: store, users: users, log: log}
+}
+
+// Do список пользователей, с которыми владелец поделился заметкой.
+func (a *ListSharesAction) Do(ctx context.Context, ownerID, noteID uuid.UUID) ([]note.Share, error) {
+	if err := authorize(ctx, a.store, ownerID, noteID, note.PermissionOwner); err != nil {
+		return nil, err
+	}
+
+	shares, err := a.store.ListShares(ctx, noteID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "list shares")
+	}
+
+	for i := range shares {
+		grantee, err := a.users.GetByID(ctx, shares[i].UserID)
+		if err != nil {
+			return nil, errors.WithMessage(err, "find grantee")
+		}
+		shares[i].Email = grantee.Email
+	}
+
+	return shares, nil
+}
+
+type UnshareAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewUnshareAction(store Store, log *zap.Logger) *UnshareAction {
+	return &UnshareAction{store: store, log: log}
+}
+
+// Do отзывает доступ. Владелец может отозвать любой, пользователь - только свой,
+// чтобы убрать чужую заметку из списка.
+func (a *UnshareAction) Do(ctx context.Context, userID, noteID, granteeID uuid.UUID) error {
+	required := note.PermissionOwner
+	if userID == granteeID {
+		required = note.PermissionRead
+	}
+	if err := authorize(ctx, a.store, userID, noteID, required); err != nil {
+		return err
+	}
+
+	if err := a.store.Unshare(ctx, noteID, granteeID); err != nil {
+		return errors.WithMessage(err, "unshare note")
+	}
+
+	a.log.Debug("Unshared note", zap.Any("noteID", noteID), zap.Any("userID", granteeID))
+
+	return nil
+}
@@ -0,0 +1,1683 @@
+// Package storetest набор проверок, которым должна соответствовать любая
+// реализация notes.Store. Тесты хранилищ вызывают Run со своей фабрикой.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+// Factory возвращает пустое хранилище. Освобождение ресурсов регистрируется через t.Cleanup.
+// Если хранилище проверяет ссылку на владельца, фабрика создаёт пользователей owners.
+type Factory func(t *testing.T, owners ...uuid.UUID) notes.Store
+
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store notes.Store)
+	}{
+		{"CreateAndGetByID", testCreateAndGetByID},
+		{"CreateDuplicateID", testCreateDuplicateID},
+		{"EmptyTags", testEmptyTags},
+		{"GetByIDUnknown", testGetByIDUnknown},
+		{"Update", testUpdate},
+		{"UpdateUnknown", testUpdateUnknown},
+		{"Patch", testPatch},
+		{"Delete", testDelete},
+		{"DeleteUnknown", testDeleteUnknown},
+		{"Count", testCount},
+		{"QueryEmpty", testQueryEmpty},
+		{"QuerySortByLabel", testQuerySortByLabel},
+		{"QuerySortByDate", testQuerySortByDate},
+		{"QueryLimitOffset", testQueryLimitOffset},
+		{"CursorPagination", testCursorPagination},
+		{"Search", testSearch},
+		{"SearchSnippetEscaped", testSearchSnippetEscaped},
+		{"TagFilters", testTagFilters},
+		{"ListTags", testListTags},
+		{"RenameTag", testRenameTag},
+		{"DeleteTag", testDeleteTag},
+		{"OwnerIsolation", testOwnerIsolation},
+		{"Shares", testShares},
+		{"SharedWithFilter", testSharedWithFilter},
+		{"Links", testLinks},
+		{"Revisions", testRevisions},
+		{"TagRevisions", testTagRevisions},
+		{"Versions", testVersions},
+		{"UpdatedAt", testUpdatedAt},
+		{"Trash", testTrash},
+		{"PurgeDeleted", testPurgeDeleted},
+		{"Notebooks", testNotebooks},
+		{"DeleteNotebook", testDeleteNotebook},
+		{"Flags", testFlags},
+		{"Batch", testBatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t, owner, stranger))
+		})
+	}
+}
+
+// base время с точностью до микросекунд, как хранит postgres.
+var base = time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+
+// owner владелец заметок, которыми оперирует набор, stranger - другой пользователь.
+var (
+	owner    = uuid.FromStringOrNil("0190f1f4-6d0e-7c2c-8f4b-000000000001")
+	stranger = uuid.FromStringOrNil("0190f1f4-6d0e-7c2c-8f4b-000000000002")
+	own      = notes.Filter{OwnerID: owner}
+)
+
+func newNote(label string, createdAt time.Time, tags ...string) note.Note {
+	return note.Note{
+		ID:        uuid.UUID(ulid.Make()),
+		OwnerID:   owner,
+		Label:     label,
+		Body:      "body of " + label,
+		Tags:      tags,
+		CreatedAt: createdAt,
+	}
+}
+
+func mustCreate(t *testing.T, store notes.Store, list ...note.Note) {
+	t.Helper()
+
+	for _, n := range list {
+		if err := store.Create(context.Background(), n); err != nil {
+			t.Fatalf("Create(%v): %v", n.Label, err)
+		}
+	}
+}
+
+func assertNote(t *testing.T, got, want note.Note) {
+	t.Helper()
+
+	if got.ID != want.ID {
+		t.Errorf("ID = %v, want %v", got.ID, want.ID)
+	}
+	if got.OwnerID != want.OwnerID {
+		t.Errorf("OwnerID = %v, want %v", got.OwnerID, want.OwnerID)
+	}
+	if got.Label != want.Label {
+		t.Errorf("Label = %q, want %q", got.Label, want.Label)
+	}
+	if got.Body != want.Body {
+		t.Errorf("Body = %q, want %q", got.Body, want.Body)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	assertTags(t, got.Tags, want.Tags)
+}
+
+func assertTags(t *testing.T, got, want []string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Errorf("Tags = %q, want %q", got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("Tags = %q, want %q", got, want)
+			return
+		}
+	}
+}
+
+func assertLabels(t *testing.T, list []note.Note, want ...string) {
+	t.Helper()
+
+	got := make([]string, len(list))
+	for i, n := range list {
+		got[i] = n.Label
+	}
+	if len(got) != len(want) {
+		t.Fatalf("labels = %q, want %q", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("labels = %q, want %q", got, want)
+		}
+	}
+}
+
+func testCreateAndGetByID(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	n := newNote("first", base, "go", "notes")
+	mustCreate(t, store, n)
+
+	got, err := store.GetByID(ctx, n.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertNote(t, got, n)
+}
+
+func testCreateDuplicateID(t *testing.T, store notes.Store) {
+	n := newNote("first", base)
+	mustCreate(t, store, n)
+
+	if err := store.Create(context.Background(), n); !errors.Is(err, notes.ErrAlreadyExists) {
+		t.Fatalf("Create with duplicate ID: err = %v, want notes.ErrAlreadyExists", err)
+	}
+}
+
+func testEmptyTags(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	withNil := newNote("nil tags", base)
+	withEmpty := newNote("empty tags", base)
+	withEmpty.Tags = []string{}
+	mustCreate(t, store, withNil, withEmpty)
+
+	for _, n := range []note.Note{withNil, withEmpty} {
+		got, err := store.GetByID(ctx, n.ID)
+		if err != nil {
+			t.Fatalf("GetByID(%v): %v", n.Label, err)
+		}
+		if len(got.Tags) != 0 {
+			t.Errorf("%v: Tags = %q, want empty", n.Label, got.Tags)
+		}
+	}
+}
+
+func testGetByIDUnknown(t *testing.T, store notes.Store) {
+	_, err := store.GetByID(context.Background(), uuid.NewV4())
+	if !errors.Is(err, notes.NotFound) {
+		t.Fatalf("GetByID unknown: err = %v, want notes.NotFound", err)
+	}
+}
+
+func testUpdate(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	n := newNote("before", base, "old")
+	other := newNote("other", base, "keep")
+	mustCreate(t, store, n, other)
+
+	err := store.Update(ctx, notes.UpdateArgs{
+		UserID: owner,
+		ID:     n.ID,
+		Label:  "after",
+		Body:   "new body",
+		Tags:   []string{"new", "tags"},
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got, err := store.GetByID(ctx, n.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	n.Label, n.Body, n.Tags = "after", "new body", []string{"new", "tags"}
+	assertNote(t, got, n)
+
+	got, err = store.GetByID(ctx, other.ID)
+	if err != nil {
+		t.Fatalf("GetByID other: %v", err)
+	}
+	assertNote(t, got, other)
+}
+
+func testUpdateUnknown(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+
+	err := store.Update(ctx, notes.UpdateArgs{UserID: owner, ID: uuid.NewV4(), Label: "ghost"})
+	if !errors.Is(err, notes.NotFound) {
+		t.Fatalf("Update unknown: err = %v, want notes.NotFound", err)
+	}
+
+	count, err := store.Count(ctx, own)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("Count after update of unknown note = %v, want 0", count)
+	}
+}
+
+func testPatch(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	n := newNote("patched", base, "old")
+	n.Body = "body"
+	mustCreate(t, store, n)
+
+	tags := []string{"new"}
+	updatedAt := base.Add(time.Minute)
+	err := store.Patch(ctx, notes.PatchArgs{UserID: stranger, ID: n.ID, Tags: &tags, Version: 1, UpdatedAt: updatedAt})
+	if err != nil {
+		t.Fatalf("Patch tags: %v", err)
+	}
+
+	got, err := store.GetByID(ctx, n.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Label != n.Label || got.Body != n.Body || got.Version != 2 || !got.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("after tags patch = %+v, want only tags changed at version 2", got)
+	}
+	assertTags(t, got.Tags, tags)
+
+	list, err := store.ListRevisions(ctx, n.ID)
+	if err != nil || len(list) != 2 || list[0].AuthorID != stranger || list[0].Body != n.Body {
+		t.Errorf("ListRevisions after Patch = %+v, %v, want new full revision by stranger", list, err)
+	}
+
+	// Указатель на nil убирает теги.
+	var none []string
+	label := "renamed"
+	if err := store.Patch(ctx, notes.PatchArgs{UserID: owner, ID: n.ID, Label: &label, Tags: &none, UpdatedAt: updatedAt}); err != nil {
+		t.Fatalf("Patch label and tags: %v", err)
+	}
+	got, _ = store.GetByID(ctx, n.ID)
+	if got.Label != label || got.Body != n.Body || got.Version != 3 {
+		t.Errorf("after label patch = %+v, want label %q at version 3", got, label)
+	}
+	assertTags(t, got.Tags, nil)
+
+	body := "stale"
+	if err := store.Patch(ctx, notes.PatchArgs{UserID: owner, ID: n.ID, Body: &body, Version: 2, UpdatedAt: updatedAt}); !errors.Is(err, notes.ErrVersionMismatch) {
+		t.Fatalf("Patch with stale version: err = %v, want notes.ErrVersionMismatch", err)
+	}
+	if got, _ := store.GetByID(ctx, n.ID); got.Body != n.Body {
+		t.Errorf("Body after stale patch = %q, want %q", got.Body, n.Body)
+	}
+
+	if err := store.Delete(ctx, owner, []notes.DeleteTarget{{ID: n.ID}}, base); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Patch(ctx, notes.PatchArgs{UserID: owner, ID: n.ID, Body: &body, UpdatedAt: updatedAt}); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Patch trashed note: err = %v, want notes.NotFound", err)
+	}
+}
+
+func testDelete(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	a, b, c := newNote("a", base), newNote("b", base), newNote("c", base)
+	mustCreate(t, store, a, b, c)
+
+	// Несовпавшая версия одной заметки отменяет удаление всех.
+	stale := []notes.DeleteTarget{{ID: a.ID, Version: 1}, {ID: b.ID, Version: 2}}
+	if err := store.Delete(ctx, owner, stale, base); !errors.Is(err, notes.ErrVersionMismatch) {
+		t.Fatalf("Delete with stale version: err = %v, want notes.ErrVersionMismatch", err)
+	}
+	if _, err := store.GetByID(ctx, a.ID); err != nil {
+		t.Fatalf("GetByID(a) after stale delete: %v", err)
+	}
+
+	if err := store.Delete(ctx, owner, []notes.DeleteTarget{{ID: a.ID, Version: 1}, {ID: c.ID}}, base); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	for _, n := range []note.Note{a, c} {
+		if _, err := store.GetByID(ctx, n.ID); !errors.Is(err, notes.NotFound) {
+			t.Errorf("GetByID(%v) after delete: err = %v, want notes.NotFound", n.Label, err)
+		}
+	}
+	if _, err := store.GetByID(ctx, b.ID); err != nil {
+		t.Errorf("GetByID(b): %v", err)
+	}
+}
+
+func testDeleteUnknown(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	n := newNote("a", base)
+	mustCreate(t, store, n)
+
+	if err := store.Delete(ctx, owner, []notes.DeleteTarget{{ID: uuid.NewV4()}}, base); err != nil {
+		t.Fatalf("Delete unknown: %v", err)
+	}
+
+	count, err := store.Count(ctx, own)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Count = %v, want 1", count)
+	}
+}
+
+func testCount(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+
+	count, err := store.Count(ctx, own)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("Count of empty store = %v, want 0", count)
+	}
+
+	mustCreate(t, store, newNote("a", base), newNote("b", base), newNote("c", base))
+
+	count, err = store.Count(ctx, own)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("Count = %v, want 3", count)
+	}
+}
+
+func testQueryEmpty(t *testing.T, store notes.Store) {
+	_, err := store.Query(context.Background(), notes.ListArgs{Filter: own})
+	if !errors.Is(err, notes.NotFound) {
+		t.Fatalf("Query of empty store: err = %v, want notes.NotFound", err)
+	}
+}
+
+// seed создаёт заметки, у которых порядок по метке и по дате не совпадает.
+func seed(t *testing.T, store notes.Store) {
+	mustCreate(t, store,
+		newNote("charlie", base.Add(1*time.Hour)),
+		newNote("alpha", base.Add(3*time.Hour)),
+		newNote("delta", base.Add(2*time.Hour)),
+		newNote("bravo", base.Add(4*time.Hour)),
+	)
+}
+
+func testQuerySortByLabel(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	seed(t, store)
+
+	list, err := store.Query(ctx, notes.ListArgs{Filter: own, SortBy: notes.SortFieldLabel})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	assertLabels(t, list, "alpha", "bravo", "charlie", "delta")
+
+	list, err = store.Query(ctx, notes.ListArgs{Filter: own, SortBy: notes.SortFieldLabel, SortDirection: notes.SortDirectionDesc})
+	if err != nil {
+		t.Fatalf("Query desc: %v", err)
+	}
+	assertLabels(t, list, "delta", "charlie", "bravo", "alpha")
+}
+
+func testQuerySortByDate(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	seed(t, store)
+
+	list, err := store.Query(ctx, notes.ListArgs{Filter: own, SortBy: notes.SortFieldDate})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	assertLabels(t, list, "charlie", "delta", "alpha", "bravo")
+
+	list, err = store.Query(ctx, notes.ListArgs{Filter: own, SortBy: notes.SortFieldDate, SortDirection: notes.SortDirectionDesc})
+	if err != nil {
+		t.Fatalf("Query desc: %v", err)
+	}
+	assertLabels(t, list, "bravo", "alpha", "delta", "charlie")
+}
+
+func testQueryLimitOffset(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	seed(t, store)
+
+	tests := []struct {
+		name   string
+		offset uint
+		limit  uint
+		want   []string
+	}{
+		{"limit only", 0, 2, []string{"alpha", "bravo"}},
+		{"offset only", 1, 0, []string{"bravo", "charlie", "delta"}},
+		{"middle page", 1, 2, []string{"bravo", "charlie"}},
+		{"last partial page", 3, 2, []string{"delta"}},
+		{"limit above total", 0, 10, []string{"alpha", "bravo", "charlie", "delta"}},
+	}
+
+	for _, tt := range tests {
+		list, err := store.Query(ctx, notes.ListArgs{Filter: own, Offset: tt.offset, Limit: tt.limit})
+		if err != nil {
+			t.Fatalf("%v: Query: %v", tt.name, err)
+		}
+		assertLabels(t, list, tt.want...)
+	}
+
+	_, err := store.Query(ctx, notes.ListArgs{Filter: own, Offset: 4, Limit: 2})
+	if !errors.Is(err, notes.NotFound) {
+		t.Fatalf("offset past the end: err = %v, want notes.NotFound", err)
+	}
+}
+
+func testCursorPagination(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	// Одинаковые метки и даты проверяют, что id разрешает равенство ключей.
+	mustCreate(t, store,
+		newNote("alpha", base.Add(1*time.Hour)),
+		newNote("bravo", base.Add(2*time.Hour)),
+		newNote("bravo", base.Add(2*time.Hour)),
+		newNote("charlie", base.Add(3*time.Hour)),
+		newNote("delta", base.Add(2*time.Hour)),
+	)
+	// Закреплённые заметки идут первыми, страницы переходят через их границу.
+	list, err := store.Query(ctx, notes.ListArgs{Filter: own})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	for _, n := range []note.Note{list[2], list[3]} {
+		if err := store.SetFlag(ctx, n.ID, notes.FlagPinned, true, 0, base); err != nil {
+			t.Fatalf("SetFlag(%v): %v", n.Label, err)
+		}
+	}
+	action := notes.NewListAction(store, notes.NewCursorCodec([]byte("secret")), zap.NewNop())
+
+	for _, sortBy := range []notes.SortField{notes.SortFieldLabel, notes.SortFieldDate, notes.SortFieldUpdated} {
+		for _, direction := range []notes.SortDirection{notes.SortDirectionAsc, notes.SortDirectionDesc} {
+			args := notes.ListArgs{Filter: own, SortBy: sortBy, SortDirection: direction}
+
+			all, err := action.Do(ctx, args)
+			if err != nil {
+				t.Fatalf("%v/%v: list all: %v", sortBy, direction, err)
+			}
+
+			args.Limit = 2
+			forward := []note.Note{}
+			pages := []note.ListNotes{}
+			for page := 0; ; page++ {
+				if page > len(all.Notes) {
+					t.Fatalf("%v/%v: pagination does not end", sortBy, direction)
+				}
+				list, err := action.Do(ctx, args)
+				if err != nil {
+					t.Fatalf("%v/%v: page %v: %v", sortBy, direction, page, err)
+				}
+				if (page == 0) != (list.PrevCursor == "") {
+					t.Fatalf("%v/%v: page %v: PrevCursor = %q", sortBy, direction, page, list.PrevCursor)
+				}
+				forward = append(forward, list.Notes...)
+				pages = append(pages, list)
+				if list.NextCursor == "" {
+					break
+				}
+				args.Cursor = list.NextCursor
+			}
+			assertSameOrder(t, forward, all.Notes)
+
+			backward := []note.Note{}
+			args.Cursor = pages[len(pages)-1].PrevCursor
+			for args.Cursor != "" {
+				list, err := action.Do(ctx, args)
+				if err != nil {
+					t.Fatalf("%v/%v: backward: %v", sortBy, direction, err)
+				}
+				if list.NextCursor == "" {
+					t.Fatalf("%v/%v: backward page without NextCursor", sortBy, direction)
+				}
+				backward = append(list.Notes, backward...)
+				args.Cursor = list.PrevCursor
+			}
+			assertSameOrder(t, append(backward, pages[len(pages)-1].Notes...), all.Notes)
+		}
+	}
+
+	args := notes.ListArgs{Filter: own, Limit: 2}
+	page, err := action.Do(ctx, args)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	args.Cursor = page.NextCursor + "x"
+	if _, err := action.Do(ctx, args); !errors.Is(err, notes.ErrInvalidCursor) {
+		t.Fatalf("tampered cursor: err = %v, want notes.ErrInvalidCursor", err)
+	}
+
+	args.Cursor = page.NextCursor
+	args.SortBy = notes.SortFieldDate
+	if _, err := action.Do(ctx, args); !errors.Is(err, notes.ErrInvalidCursor) {
+		t.Fatalf("cursor with another sort: err = %v, want notes.ErrInvalidCursor", err)
+	}
+}
+
+func assertSameOrder(t *testing.T, got, want []note.Note) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v notes, want %v", len(got), len(want))
+	}
+	for i := range got {
+		if got[i].ID != want[i].ID {
+			t.Fatalf("note %v: got %v (%v), want %v (%v)", i, got[i].Label, got[i].ID, want[i].Label, want[i].ID)
+		}
+	}
+}
+
+func testSearch(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	inLabel := newNote("golang tips", base)
+	inLabel.Body = "use channels to communicate"
+	inBody := newNote("dinner", base)
+	inBody.Body = "cook pasta while reading about golang"
+	mustCreate(t, store, inLabel, inBody, newNote("unrelated", base))
+
+	count, err := store.SearchCount(ctx, notes.SearchArgs{OwnerID: owner, Query: "golang"})
+	if err != nil {
+		t.Fatalf("SearchCount: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("SearchCount = %v, want 2", count)
+	}
+
+	found, err := store.Search(ctx, notes.SearchArgs{OwnerID: owner, Query: "golang"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(found) != 2 || found[0].ID != inLabel.ID || found[1].ID != inBody.ID {
+		t.Fatalf("Search: got %v results, want label match ranked above body match", len(found))
+	}
+	if found[0].Rank <= found[1].Rank {
+		t.Errorf("Rank: label match %v, body match %v", found[0].Rank, found[1].Rank)
+	}
+	if !strings.Contains(found[1].Snippet, "<b>golang</b>") {
+		t.Errorf("Snippet = %q, want highlighted term", found[1].Snippet)
+	}
+
+	found, err = store.Search(ctx, notes.SearchArgs{OwnerID: owner, Query: "golang", Offset: 1, Limit: 1})
+	if err != nil {
+		t.Fatalf("Search page: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != inBody.ID {
+		t.Fatalf("Search page: got %v results, want second match", len(found))
+	}
+
+	found, err = store.Search(ctx, notes.SearchArgs{OwnerID: owner, Query: "golang channels"})
+	if err != nil {
+		t.Fatalf("Search all terms: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != inLabel.ID {
+		t.Fatalf("Search all terms: got %v results, want only note with both terms", len(found))
+	}
+
+	count, err = store.SearchCount(ctx, notes.SearchArgs{OwnerID: owner, Query: "nothing"})
+	if err != nil {
+		t.Fatalf("SearchCount: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("SearchCount without matches = %v, want 0", count)
+	}
+}
+
+func seedTags(t *testing.T, store notes.Store) {
+	mustCreate(t, store,
+		newNote("alpha", base, "go", "db"),
+		newNote("bravo", base, "go"),
+		newNote("charlie", base, "db", "ops"),
+		newNote("delta", base),
+	)
+}
+
+// testSearchSnippetEscaped фрагмент показывают как HTML, поэтому разметка
+// из текста заметки должна приходить экранированной.
+func testSearchSnippetEscaped(t *testing.T, store notes.Store) {
+	n := newNote("xss", base)
+	n.Body = `golang <script>alert(1)</script> <img src=x onerror="alert(2)"> & more`
+	mustCreate(t, store, n)
+
+	found, err := store.Search(context.Background(), notes.SearchArgs{OwnerID: owner, Query: "golang"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("Search: got %v results, want 1", len(found))
+	}
+
+	snippet := found[0].Snippet
+	if !strings.Contains(snippet, "<b>golang</b>") {
+		t.Errorf("Snippet = %q, want highlighted term", snippet)
+	}
+	for _, raw := range []string{"<script", "<img", "</script"} {
+		if strings.Contains(snippet, raw) {
+			t.Errorf("Snippet = %q, contains unescaped %q", snippet, raw)
+		}
+	}
+	if !strings.Contains(snippet, "&lt;script&gt;") {
+		t.Errorf("Snippet = %q, want escaped markup", snippet)
+	}
+	if strings.Count(snippet, "<") != strings.Count(snippet, "<b>")+strings.Count(snippet, "</b>") {
+		t.Errorf("Snippet = %q, want only highlight markup", snippet)
+	}
+}
+
+func testTagFilters(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	seedTags(t, store)
+
+	tests := []struct {
+		name   string
+		filter notes.Filter
+		want   []string
+	}{
+		{"all", notes.Filter{OwnerID: owner, TagsAll: []string{"go", "db"}}, []string{"alpha"}},
+		{"any", notes.Filter{OwnerID: owner, TagsAny: []string{"go", "ops"}}, []string{"alpha", "bravo", "charlie"}},
+		{"none", notes.Filter{OwnerID: owner, TagsNone: []string{"go"}}, []string{"charlie", "delta"}},
+		{"combined", notes.Filter{OwnerID: owner, TagsAny: []string{"db"}, TagsNone: []string{"ops"}}, []string{"alpha"}},
+	}
+
+	for _, tt := range tests {
+		list, err := store.Query(ctx, notes.ListArgs{Filter: tt.filter})
+		if err != nil {
+			t.Fatalf("%v: Query: %v", tt.name, err)
+		}
+		assertLabels(t, list, tt.want...)
+
+		count, err := store.Count(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("%v: Count: %v", tt.name, err)
+		}
+		if count != uint(len(tt.want)) {
+			t.Fatalf("%v: Count = %v, want %v", tt.name, count, len(tt.want))
+		}
+	}
+
+	_, err := store.Query(ctx, notes.ListArgs{Filter: notes.Filter{OwnerID: owner, TagsAll: []string{"missing"}}})
+	if !errors.Is(err, notes.NotFound) {
+		t.Fatalf("Query by unknown tag: err = %v, want notes.NotFound", err)
+	}
+}
+
+func assertTagCounts(t *testing.T, store notes.Store, want ...note.Tag) {
+	t.Helper()
+
+	got, err := store.ListTags(context.Background(), owner)
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListTags = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("ListTags = %v, want %v", got, want)
+		}
+	}
+}
+
+func testListTags(t *testing.T, store notes.Store) {
+	seedTags(t, store)
+
+	assertTagCounts(t, store,
+		note.Tag{Name: "db", Count: 2},
+		note.Tag{Name: "go", Count: 2},
+		note.Tag{Name: "ops", Count: 1},
+	)
+}
+
+func testRenameTag(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	seedTags(t, store)
+
+	count, err := store.RenameTag(ctx, owner, "db", "go")
+	if err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("RenameTag affected %v notes, want 2", count)
+	}
+
+	list, err := store.Query(ctx, notes.ListArgs{Filter: own})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	assertTags(t, list[0].Tags, []string{"go"})
+	assertTags(t, list[2].Tags, []string{"go", "ops"})
+
+	count, err = store.RenameTag(ctx, owner, "missing", "other")
+	if err != nil {
+		t.Fatalf("RenameTag missing: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("RenameTag missing affected %v notes, want 0", count)
+	}
+}
+
+func testDeleteTag(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	seedTags(t, store)
+
+	count, err := store.DeleteTag(ctx, owner, "go")
+	if err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("DeleteTag affected %v notes, want 2", count)
+	}
+
+	assertTagCounts(t, store,
+		note.Tag{Name: "db", Count: 2},
+		note.Tag{Name: "ops", Count: 1},
+	)
+}
+
+// testOwnerIsolation проверяет, что чужие заметки не видны и не меняются ни одним методом.
+func testOwnerIsolation(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	mine := newNote("mine", base, "shared")
+	theirs := newNote("theirs golang", base, "shared")
+	theirs.OwnerID = stranger
+	mustCreate(t, store, mine, theirs)
+
+	if _, err := store.Access(ctx, owner, theirs.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Access to foreign note: err = %v, want notes.NotFound", err)
+	}
+
+	if err := store.Delete(ctx, owner, []notes.DeleteTarget{{ID: theirs.ID}}, base); err != nil {
+		t.Fatalf("Delete of foreign note: %v", err)
+	}
+
+	count, err := store.RenameTag(ctx, owner, "shared", "renamed")
+	if err != nil || count != 1 {
+		t.Errorf("RenameTag: count = %v, err = %v, want 1 own note", count, err)
+	}
+	count, err = store.DeleteTag(ctx, owner, "renamed")
+	if err != nil || count != 1 {
+		t.Errorf("DeleteTag: count = %v, err = %v, want 1 own note", count, err)
+	}
+
+	got, err := store.GetByID(ctx, theirs.ID)
+	if err != nil {
+		t.Fatalf("GetByID after foreign delete: %v", err)
+	}
+	assertNote(t, got, theirs)
+
+	list, err := store.Query(ctx, notes.ListArgs{Filter: own})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	assertLabels(t, list, "mine")
+
+	count, err = store.Count(ctx, own)
+	if err != nil || count != 1 {
+		t.Errorf("Count: count = %v, err = %v, want 1", count, err)
+	}
+
+	found, err := store.Search(ctx, notes.SearchArgs{OwnerID: owner, Query: "golang"})
+	if err != nil || len(found) != 0 {
+		t.Errorf("Search: found = %v, err = %v, want nothing", len(found), err)
+	}
+	count, err = store.SearchCount(ctx, notes.SearchArgs{OwnerID: owner, Query: "golang"})
+	if err != nil || count != 0 {
+		t.Errorf("SearchCount: count = %v, err = %v, want 0", count, err)
+	}
+
+	tags, err := store.ListTags(ctx, stranger)
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != (note.Tag{Name: "shared", Count: 1}) {
+		t.Errorf("ListTags of stranger = %v, want untouched shared tag", tags)
+	}
+}
+
+func testShares(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	n := newNote("mine", base)
+	mustCreate(t, store, n)
+
+	permission, err := store.Access(ctx, owner, n.ID)
+	if err != nil || permission != note.PermissionOwner {
+		t.Fatalf("Access of owner = %v, %v, want owner", permission, err)
+	}
+
+	share := note.Share{NoteID: n.ID, UserID: stranger, Permission: note.PermissionRead, CreatedAt: base}
+	if err := store.Share(ctx, share); err != nil {
+		t.Fatalf("Share: %v", err)
+	}
+	permission, err = store.Access(ctx, stranger, n.ID)
+	if err != nil || permission != note.PermissionRead {
+		t.Errorf("Access of grantee = %v, %v, want read", permission, err)
+	}
+
+	// Повторная выдача меняет право, но не время выдачи.
+	share.Permission = note.PermissionWrite
+	share.CreatedAt = base.Add(time.Hour)
+	if err := store.Share(ctx, share); err != nil {
+		t.Fatalf("Share again: %v", err)
+	}
+	permission, err = store.Access(ctx, stranger, n.ID)
+	if err != nil || permission != note.PermissionWrite {
+		t.Errorf("Access after upgrade = %v, %v, want write", permission, err)
+	}
+
+	shares, err := store.ListShares(ctx, n.ID)
+	if err != nil {
+		t.Fatalf("ListShares: %v", err)
+	}
+	if len(shares) != 1 || shares[0].UserID != stranger || shares[0].NoteID != n.ID ||
+		shares[0].Permission != note.PermissionWrite || !shares[0].CreatedAt.Equal(base) {
+		t.Errorf("ListShares = %+v, want single write share created at %v", shares, base)
+	}
+
+	if err := store.Share(ctx, note.Share{NoteID: uuid.NewV4(), UserID: stranger, Permission: note.PermissionRead, CreatedAt: base}); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Share of unknown note: err = %v, want notes.NotFound", err)
+	}
+
+	if err := store.Unshare(ctx, n.ID, stranger); err != nil {
+		t.Fatalf("Unshare: %v", err)
+	}
+	if _, err := store.Access(ctx, stranger, n.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Access after Unshare: err = %v, want notes.NotFound", err)
+	}
+	if err := store.Unshare(ctx, n.ID, stranger); !errors.Is(err, notes.ErrShareNotFound) {
+		t.Errorf("Unshare twice: err = %v, want notes.ErrShareNotFound", err)
+	}
+
+	if err := store.Share(ctx, note.Share{NoteID: n.ID, UserID: stranger, Permission: note.PermissionRead, CreatedAt: base}); err != nil {
+		t.Fatalf("Share before delete: %v", err)
+	}
+	if err := store.Delete(ctx, owner, []notes.DeleteTarget{{ID: n.ID}}, base); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Purge(ctx, owner, n.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	shares, err = store.ListShares(ctx, n.ID)
+	if err != nil || len(shares) != 0 {
+		t.Errorf("ListShares after Purge = %v, %v, want none", shares, err)
+	}
+}
+
+func testSharedWithFilter(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	mine := newNote("mine", base, "go")
+	sharedGo := newNote("shared go", base, "go")
+	sharedGo.OwnerID = stranger
+	sharedOps := newNote("shared ops", base, "ops")
+	sharedOps.OwnerID = stranger
+	private := newNote("private", base, "go")
+	private.OwnerID = stranger
+	mustCreate(t, store, mine, sharedGo, sharedOps, private)
+
+	for _, n := range []note.Note{sharedGo, sharedOps} {
+		if err := store.Share(ctx, note.Share{NoteID: n.ID, UserID: owner, Permission: note.PermissionRead, CreatedAt: base}); err != nil {
+			t.Fatalf("Share %v: %v", n.Label, err)
+		}
+	}
+
+	sharedWithMe := notes.Filter{SharedWith: owner}
+	list, err := store.Query(ctx, notes.ListArgs{Filter: sharedWithMe})
+	if err != nil {
+		t.Fatalf("Query shared: %v", err)
+	}
+	assertLabels(t, list, "shared go", "shared ops")
+
+	count, err := store.Count(ctx, sharedWithMe)
+	if err != nil || count != 2 {
+		t.Errorf("Count shared = %v, %v, want 2", count, err)
+	}
+
+	list, err = store.Query(ctx, notes.ListArgs{Filter: notes.Filter{SharedWith: owner, TagsAll: []string{"go"}}})
+	if err != nil {
+		t.Fatalf("Query shared with tag: %v", err)
+	}
+	assertLabels(t, list, "shared go")
+}
+
+func testLinks(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	n := newNote("mine", base)
+	mustCreate(t, store, n)
+
+	if _, err := store.GetLink(ctx, n.ID); !errors.Is(err, notes.ErrLinkNotFound) {
+		t.Fatalf("GetLink before SaveLink: err = %v, want notes.ErrLinkNotFound", err)
+	}
+
+	expiresAt := base.Add(time.Hour)
+	link := note.Link{NoteID: n.ID, OwnerID: owner, Token: "first", CreatedAt: base, ExpiresAt: &expiresAt}
+	if err := store.SaveLink(ctx, link); err != nil {
+		t.Fatalf("SaveLink: %v", err)
+	}
+
+	got, err := store.GetLinkByToken(ctx, "first")
+	if err != nil {
+		t.Fatalf("GetLinkByToken: %v", err)
+	}
+	if got.NoteID != n.ID || got.OwnerID != owner || got.Token != "first" ||
+		!got.CreatedAt.Equal(base) || got.ExpiresAt == nil || !got.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("GetLinkByToken = %+v, want %+v", got, link)
+	}
+
+	// Новая ссылка заменяет старую.
+	if err := store.SaveLink(ctx, note.Link{NoteID: n.ID, OwnerID: owner, Token: "second", CreatedAt: base}); err != nil {
+		t.Fatalf("SaveLink again: %v", err)
+	}
+	if _, err := store.GetLinkByToken(ctx, "first"); !errors.Is(err, notes.ErrLinkNotFound) {
+		t.Errorf("GetLinkByToken of replaced link: err = %v, want notes.ErrLinkNotFound", err)
+	}
+	got, err = store.GetLink(ctx, n.ID)
+	if err != nil || got.Token != "second" || got.ExpiresAt != nil {
+		t.Errorf("GetLink = %+v, %v, want second link without expiry", got, err)
+	}
+
+	if err := store.SaveLink(ctx, note.Link{NoteID: uuid.NewV4(), Token: "ghost", CreatedAt: base}); !errors.Is(err, notes.NotFound) {
+		t.Errorf("SaveLink of unknown note: err = %v, want notes.NotFound", err)
+	}
+
+	if err := store.DeleteLink(ctx, n.ID); err != nil {
+		t.Fatalf("DeleteLink: %v", err)
+	}
+	if _, err := store.GetLinkByToken(ctx, "second"); !errors.Is(err, notes.ErrLinkNotFound) {
+		t.Errorf("GetLinkByToken after DeleteLink: err = %v, want notes.ErrLinkNotFound", err)
+	}
+	if err := store.DeleteLink(ctx, n.ID); !errors.Is(err, notes.ErrLinkNotFound) {
+		t.Errorf("DeleteLink twice: err = %v, want notes.ErrLinkNotFound", err)
+	}
+
+	if err := store.SaveLink(ctx, note.Link{NoteID: n.ID, OwnerID: owner, Token: "third", CreatedAt: base}); err != nil {
+		t.Fatalf("SaveLink before delete: %v", err)
+	}
+	if err := store.Delete(ctx, owner, []notes.DeleteTarget{{ID: n.ID}}, base); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.GetLinkByToken(ctx, "third"); !errors.Is(err, notes.ErrLinkNotFound) {
+		t.Errorf("GetLinkByToken after note Delete: err = %v, want notes.ErrLinkNotFound", err)
+	}
+}
+
+// testTagRevisions переименование и удаление тега меняют заметки, поэтому
+// тоже сохраняются ревизиями.
+func testTagRevisions(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	n := newNote("tagged", base, "old", "keep")
+	other := newNote("untagged", base, "keep")
+	mustCreate(t, store, n, other)
+
+	if _, err := store.RenameTag(ctx, owner, "old", "new"); err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if _, err := store.DeleteTag(ctx, owner, "new"); err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+
+	list, err := store.ListRevisions(ctx, n.ID)
+	if err != nil {
+		t.Fatalf("ListRevisions: %v", err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("ListRevisions returned %v revisions, want 3", len(list))
+	}
+	for i, tags := range [][]string{{"keep"}, {"new", "keep"}} {
+		if list[i].Rev != uint(3-i) || list[i].AuthorID != owner {
+			t.Errorf("revision %v = %+v, want rev %v by owner", i, list[i], 3-i)
+		}
+		assertTags(t, list[i].Tags, tags)
+	}
+
+	got, err := store.GetByID(ctx, n.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Version != 3 {
+		t.Errorf("Version = %v, want 3", got.Version)
+	}
+
+	list, err = store.ListRevisions(ctx, other.ID)
+	if err != nil {
+		t.Fatalf("ListRevisions other: %v", err)
+	}
+	if len(list) != 1 {
+		t.Errorf("ListRevisions other returned %v revisions, want 1", len(list))
+	}
+}
+
+func testRevisions(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	n := newNote("first", base, "a")
+	mustCreate(t, store, n)
+
+	updatedAt := base.Add(time.Minute)
+	err := store.Update(ctx, notes.UpdateArgs{UserID: stranger, ID: n.ID, Label: "second", Body: "new body", Tags: []string{"b"}, UpdatedAt: updatedAt})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	list, err := store.ListRevisions(ctx, n.ID)
+	if err != nil {
+		t.Fatalf("ListRevisions: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("ListRevisions returned %v revisions, want 2", len(list))
+	}
+
+	latest, first := list[0], list[1]
+	if latest.Rev != 2 || latest.NoteID != n.ID || latest.Label != "second" || latest.Body != "new body" ||
+		latest.AuthorID != stranger || !latest.CreatedAt.Equal(updatedAt) {
+		t.Errorf("latest revision = %+v, want rev 2 by stranger", latest)
+	}
+	assertTags(t, latest.Tags, []string{"b"})
+	if first.Rev != 1 || first.Label != n.Label || first.Body != n.Body || first.AuthorID != owner || !first.CreatedAt.Equal(base) {
+		t.Errorf("first revision = %+v, want rev 1 by owner", first)
+	}
+	assertTags(t, first.Tags, n.Tags)
+
+	got, err := store.GetRevision(ctx, n.ID, 1)
+	if err != nil || got.Label != n.Label {
+		t.Errorf("GetRevision(1) = %+v, %v, want first revision", got, err)
+	}
+	if _, err := store.GetRevision(ctx, n.ID, 3); !errors.Is(err, notes.ErrRevisionNotFound) {
+		t.Errorf("GetRevision(3): err = %v, want notes.ErrRevisionNotFound", err)
+	}
+
+	// Неудачное изменение не оставляет ревизии.
+	if err := store.Update(ctx, notes.UpdateArgs{UserID: owner, ID: uuid.NewV4(), Label: "ghost", UpdatedAt: updatedAt}); !errors.Is(err, notes.NotFound) {
+		t.Fatalf("Update unknown: err = %v, want notes.NotFound", err)
+	}
+
+	if err := store.Delete(ctx, owner, []notes.DeleteTarget{{ID: n.ID}}, base); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Purge(ctx, owner, n.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	list, err = store.ListRevisions(ctx, n.ID)
+	if err != nil || len(list) != 0 {
+		t.Errorf("ListRevisions after Purge = %v, %v, want empty", list, err)
+	}
+}
+
+func testVersions(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	n := newNote("versioned", base, "a")
+	mustCreate(t, store, n)
+
+	assertVersion := func(want uint) {
+		t.Helper()
+		got, err := store.GetByID(ctx, n.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Version != want {
+			t.Fatalf("Version = %v, want %v", got.Version, want)
+		}
+	}
+	assertVersion(1)
+
+	update := notes.UpdateArgs{UserID: owner, ID: n.ID, Label: "second", Tags: []string{"a"}, Version: 1, UpdatedAt: base}
+	if err := store.Update(ctx, update); err != nil {
+		t.Fatalf("Update with current version: %v", err)
+	}
+	assertVersion(2)
+
+	// Устаревшая версия не меняет заметку.
+	update.Label = "stale"
+	if err := store.Update(ctx, update); !errors.Is(err, notes.ErrVersionMismatch) {
+		t.Fatalf("Update with stale version: err = %v, want notes.ErrVersionMismatch", err)
+	}
+	if got, _ := store.GetByID(ctx, n.ID); got.Label != "second" {
+		t.Errorf("Label after stale update = %q, want %q", got.Label, "second")
+	}
+
+	update.Version = 0
+	if err := store.Update(ctx, update); err != nil {
+		t.Fatalf("Update without version: %v", err)
+	}
+	assertVersion(3)
+
+	if err := store.Update(ctx, notes.UpdateArgs{ID: uuid.NewV4(), Label: "ghost", Version: 1, UpdatedAt: base}); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Update unknown with version: err = %v, want notes.NotFound", err)
+	}
+
+	if _, err := store.RenameTag(ctx, owner, "a", "b"); err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	assertVersion(4)
+
+	if err := store.DeleteOne(ctx, owner, n.ID, 3, base); !errors.Is(err, notes.ErrVersionMismatch) {
+		t.Fatalf("DeleteOne with stale version: err = %v, want notes.ErrVersionMismatch", err)
+	}
+	if err := store.DeleteOne(ctx, stranger, n.ID, 4, base); !errors.Is(err, notes.NotFound) {
+		t.Errorf("DeleteOne by stranger: err = %v, want notes.NotFound", err)
+	}
+	if err := store.DeleteOne(ctx, owner, n.ID, 4, base); err != nil {
+		t.Fatalf("DeleteOne: %v", err)
+	}
+	if _, err := store.GetByID(ctx, n.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("GetByID after DeleteOne: err = %v, want notes.NotFound", err)
+	}
+	if err := store.DeleteOne(ctx, owner, n.ID, 0, base); !errors.Is(err, notes.NotFound) {
+		t.Errorf("DeleteOne twice: err = %v, want notes.NotFound", err)
+	}
+}
+
+func testUpdatedAt(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	old := newNote("old", base)
+	middle := newNote("middle", base.Add(time.Hour))
+	fresh := newNote("fresh", base.Add(2*time.Hour))
+	mustCreate(t, store, old, middle, fresh)
+
+	got, err := store.GetByID(ctx, old.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !got.UpdatedAt.Equal(old.CreatedAt) {
+		t.Errorf("UpdatedAt of new note = %v, want CreatedAt %v", got.UpdatedAt, old.CreatedAt)
+	}
+
+	updatedAt := base.Add(3 * time.Hour)
+	err = store.Update(ctx, notes.UpdateArgs{UserID: owner, ID: old.ID, Label: old.Label, UpdatedAt: updatedAt})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = store.GetByID(ctx, old.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !got.UpdatedAt.Equal(updatedAt) || !got.CreatedAt.Equal(old.CreatedAt) {
+		t.Errorf("after Update CreatedAt, UpdatedAt = %v, %v, want %v, %v", got.CreatedAt, got.UpdatedAt, old.CreatedAt, updatedAt)
+	}
+
+	list, err := store.Query(ctx, notes.ListArgs{Filter: own, SortBy: notes.SortFieldUpdated, SortDirection: notes.SortDirectionDesc})
+	if err != nil {
+		t.Fatalf("Query by updated_at: %v", err)
+	}
+	assertLabels(t, list, "old", "fresh", "middle")
+
+	filter := own
+	filter.ModifiedSince = base.Add(2 * time.Hour)
+	list, err = store.Query(ctx, notes.ListArgs{Filter: filter, SortBy: notes.SortFieldDate})
+	if err != nil {
+		t.Fatalf("Query modified since: %v", err)
+	}
+	assertLabels(t, list, "old", "fresh")
+
+	filter.CreatedBefore = base.Add(2 * time.Hour)
+	list, err = store.Query(ctx, notes.ListArgs{Filter: filter, SortBy: notes.SortFieldDate})
+	if err != nil {
+		t.Fatalf("Query modified since and created before: %v", err)
+	}
+	assertLabels(t, list, "old")
+
+	count, err := store.Count(ctx, filter)
+	if err != nil || count != 1 {
+		t.Errorf("Count = %v, %v, want 1", count, err)
+	}
+}
+
+func testTrash(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	trashed, kept := newNote("trashed", base, "go"), newNote("kept", base, "go")
+	mustCreate(t, store, trashed, kept)
+
+	if err := store.Share(ctx, note.Share{NoteID: trashed.ID, UserID: stranger, Permission: note.PermissionRead, CreatedAt: base}); err != nil {
+		t.Fatalf("Share: %v", err)
+	}
+	if err := store.SaveLink(ctx, note.Link{NoteID: trashed.ID, OwnerID: owner, Token: "trashed", CreatedAt: base}); err != nil {
+		t.Fatalf("SaveLink: %v", err)
+	}
+
+	deletedAt := base.Add(time.Hour)
+	if err := store.Delete(ctx, owner, []notes.DeleteTarget{{ID: trashed.ID}}, deletedAt); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// Заметка в корзине не видна ни владельцу, ни тем, с кем ею поделились.
+	if _, err := store.GetByID(ctx, trashed.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("GetByID of trashed note: err = %v, want notes.NotFound", err)
+	}
+	for _, userID := range []uuid.UUID{owner, stranger} {
+		if _, err := store.Access(ctx, userID, trashed.ID); !errors.Is(err, notes.NotFound) {
+			t.Errorf("Access(%v) to trashed note: err = %v, want notes.NotFound", userID, err)
+		}
+	}
+	if _, err := store.GetLinkByToken(ctx, "trashed"); !errors.Is(err, notes.ErrLinkNotFound) {
+		t.Errorf("GetLinkByToken of trashed note: err = %v, want notes.ErrLinkNotFound", err)
+	}
+	if err := store.Update(ctx, notes.UpdateArgs{UserID: owner, ID: trashed.ID, Label: "edited", UpdatedAt: deletedAt}); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Update of trashed note: err = %v, want notes.NotFound", err)
+	}
+	list, err := store.Query(ctx, notes.ListArgs{Filter: own})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	assertLabels(t, list, "kept")
+	assertTagCounts(t, store, note.Tag{Name: "go", Count: 1})
+	if count, err := store.RenameTag(ctx, owner, "go", "golang"); err != nil || count != 1 {
+		t.Errorf("RenameTag = %v, %v, want 1", count, err)
+	}
+
+	trash := notes.Filter{OwnerID: owner, Trashed: true}
+	list, err = store.Query(ctx, notes.ListArgs{Filter: trash})
+	if err != nil {
+		t.Fatalf("Query trash: %v", err)
+	}
+	assertLabels(t, list, "trashed")
+	if list[0].DeletedAt == nil || !list[0].DeletedAt.Equal(deletedAt) {
+		t.Errorf("DeletedAt = %v, want %v", list[0].DeletedAt, deletedAt)
+	}
+	assertTags(t, list[0].Tags, []string{"go"})
+	if count, err := store.Count(ctx, trash); err != nil || count != 1 {
+		t.Errorf("Count trash = %v, %v, want 1", count, err)
+	}
+
+	// Повторное удаление не продлевает срок хранения в корзине.
+	if err := store.Delete(ctx, owner, []notes.DeleteTarget{{ID: trashed.ID}}, deletedAt.Add(time.Hour)); err != nil {
+		t.Fatalf("Delete twice: %v", err)
+	}
+	list, err = store.Query(ctx, notes.ListArgs{Filter: trash})
+	if err != nil || len(list) != 1 || list[0].DeletedAt == nil || !list[0].DeletedAt.Equal(deletedAt) {
+		t.Errorf("Query trash after second Delete = %v, %v, want DeletedAt %v", list, err, deletedAt)
+	}
+
+	if err := store.Restore(ctx, stranger, trashed.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Restore by stranger: err = %v, want notes.NotFound", err)
+	}
+	if err := store.Restore(ctx, owner, kept.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Restore of note outside trash: err = %v, want notes.NotFound", err)
+	}
+	if err := store.Purge(ctx, owner, kept.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Purge of note outside trash: err = %v, want notes.NotFound", err)
+	}
+
+	if err := store.Restore(ctx, owner, trashed.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	got, err := store.GetByID(ctx, trashed.ID)
+	if err != nil || got.DeletedAt != nil {
+		t.Errorf("GetByID after Restore = %+v, %v, want note without DeletedAt", got, err)
+	}
+	if permission, err := store.Access(ctx, stranger, trashed.ID); err != nil || permission != note.PermissionRead {
+		t.Errorf("Access after Restore = %v, %v, want %v", permission, err, note.PermissionRead)
+	}
+	if _, err := store.GetLinkByToken(ctx, "trashed"); err != nil {
+		t.Errorf("GetLinkByToken after Restore: %v", err)
+	}
+	if err := store.Restore(ctx, owner, trashed.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Restore twice: err = %v, want notes.NotFound", err)
+	}
+
+	if err := store.DeleteOne(ctx, owner, trashed.ID, 0, deletedAt); err != nil {
+		t.Fatalf("DeleteOne: %v", err)
+	}
+	if err := store.Purge(ctx, stranger, trashed.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Purge by stranger: err = %v, want notes.NotFound", err)
+	}
+	if err := store.Purge(ctx, owner, trashed.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if err := store.Restore(ctx, owner, trashed.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Restore after Purge: err = %v, want notes.NotFound", err)
+	}
+	if count, err := store.Count(ctx, trash); err != nil || count != 0 {
+		t.Errorf("Count trash after Purge = %v, %v, want 0", count, err)
+	}
+}
+
+func testPurgeDeleted(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	old, recent, live := newNote("old", base), newNote("recent", base), newNote("live", base)
+	theirs := newNote("theirs", base)
+	theirs.OwnerID = stranger
+	mustCreate(t, store, old, recent, live, theirs)
+
+	for _, d := range []struct {
+		ownerID   uuid.UUID
+		n         note.Note
+		deletedAt time.Time
+	}{
+		{owner, old, base},
+		{owner, recent, base.Add(2 * time.Hour)},
+		{stranger, theirs, base},
+	} {
+		if err := store.Delete(ctx, d.ownerID, []notes.DeleteTarget{{ID: d.n.ID}}, d.deletedAt); err != nil {
+			t.Fatalf("Delete %v: %v", d.n.Label, err)
+		}
+	}
+
+	count, err := store.PurgeDeleted(ctx, base.Add(time.Hour))
+	if err != nil || count != 2 {
+		t.Fatalf("PurgeDeleted = %v, %v, want 2", count, err)
+	}
+
+	list, err := store.Query(ctx, notes.ListArgs{Filter: notes.Filter{OwnerID: owner, Trashed: true}})
+	if err != nil {
+		t.Fatalf("Query trash: %v", err)
+	}
+	assertLabels(t, list, "recent")
+	if _, err := store.GetByID(ctx, live.ID); err != nil {
+		t.Errorf("GetByID(live): %v", err)
+	}
+	if err := store.Restore(ctx, stranger, theirs.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("Restore of purged note: err = %v, want notes.NotFound", err)
+	}
+}
+
+func newNotebook(name string, parent *note.Notebook) note.Notebook {
+	notebook := note.Notebook{ID: uuid.UUID(ulid.Make()), OwnerID: owner, Name: name, CreatedAt: base, UpdatedAt: base}
+	if parent != nil {
+		notebook.ParentID = &parent.ID
+	}
+	return notebook
+}
+
+func mustCreateNotebooks(t *testing.T, store notes.Store, list ...note.Notebook) {
+	t.Helper()
+
+	for _, notebook := range list {
+		if err := store.CreateNotebook(context.Background(), notebook); err != nil {
+			t.Fatalf("CreateNotebook(%v): %v", notebook.Name, err)
+		}
+	}
+}
+
+func inNotebook(n note.Note, notebook note.Notebook) note.Note {
+	n.NotebookID = &notebook.ID
+	return n
+}
+
+func queryNotebook(t *testing.T, store notes.Store, notebookID uuid.UUID, recursive bool) []note.Note {
+	t.Helper()
+
+	filter := own
+	filter.NotebookID, filter.Recursive = notebookID, recursive
+	list, err := store.Query(context.Background(), notes.ListArgs{Filter: filter})
+	if err != nil && !errors.Is(err, notes.NotFound) {
+		t.Fatalf("Query notebook: %v", err)
+	}
+
+	return list
+}
+
+func testNotebooks(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	work := newNotebook("work", nil)
+	projects := newNotebook("projects", &work)
+	home := newNotebook("home", nil)
+	mustCreateNotebooks(t, store, work, projects, home)
+
+	got, err := store.GetNotebook(ctx, owner, projects.ID)
+	if err != nil || got.Name != "projects" || got.ParentID == nil || *got.ParentID != work.ID || !got.CreatedAt.Equal(base) {
+		t.Errorf("GetNotebook = %+v, %v, want projects inside work", got, err)
+	}
+	if _, err := store.GetNotebook(ctx, stranger, projects.ID); !errors.Is(err, notes.ErrNotebookNotFound) {
+		t.Errorf("GetNotebook by stranger: err = %v, want notes.ErrNotebookNotFound", err)
+	}
+
+	list, err := store.ListNotebooks(ctx, owner)
+	if err != nil || len(list) != 3 || list[0].Name != "home" || list[1].Name != "projects" || list[2].Name != "work" {
+		t.Errorf("ListNotebooks = %+v, %v, want home, projects, work", list, err)
+	}
+
+	plan, report, loose := inNotebook(newNote("plan", base), work), inNotebook(newNote("report", base), projects), newNote("loose", base)
+	mustCreate(t, store, plan, report, loose)
+
+	n, err := store.GetByID(ctx, report.ID)
+	if err != nil || n.NotebookID == nil || *n.NotebookID != projects.ID {
+		t.Errorf("GetByID = %+v, %v, want note in projects", n, err)
+	}
+	assertLabels(t, queryNotebook(t, store, work.ID, false), "plan")
+	assertLabels(t, queryNotebook(t, store, work.ID, true), "plan", "report")
+	assertLabels(t, queryNotebook(t, store, home.ID, true))
+
+	count, err := store.MoveNotes(ctx, owner, []uuid.UUID{loose.ID, plan.ID, uuid.NewV4()}, work.ID, base.Add(time.Minute))
+	if err != nil || count != 1 {
+		t.Fatalf("MoveNotes = %v, %v, want 1 moved note", count, err)
+	}
+	n, _ = store.GetByID(ctx, loose.ID)
+	if n.NotebookID == nil || *n.NotebookID != work.ID || n.Version != 2 || !n.UpdatedAt.Equal(base.Add(time.Minute)) {
+		t.Errorf("moved note = %+v, want version 2 in work", n)
+	}
+	if count, err := store.MoveNotes(ctx, stranger, []uuid.UUID{report.ID}, uuid.Nil, base); err != nil || count != 0 {
+		t.Errorf("MoveNotes by stranger = %v, %v, want 0", count, err)
+	}
+	if count, err := store.MoveNotes(ctx, owner, []uuid.UUID{report.ID}, uuid.Nil, base); err != nil || count != 1 {
+		t.Errorf("MoveNotes to root = %v, %v, want 1", count, err)
+	}
+	if n, _ := store.GetByID(ctx, report.ID); n.NotebookID != nil {
+		t.Errorf("NotebookID after move to root = %v, want nil", *n.NotebookID)
+	}
+
+	projects.Name, projects.ParentID, projects.UpdatedAt = "archive", &home.ID, base.Add(time.Hour)
+	if err := store.UpdateNotebook(ctx, projects); err != nil {
+		t.Fatalf("UpdateNotebook: %v", err)
+	}
+	got, _ = store.GetNotebook(ctx, owner, projects.ID)
+	if got.Name != "archive" || got.ParentID == nil || *got.ParentID != home.ID || !got.UpdatedAt.Equal(projects.UpdatedAt) {
+		t.Errorf("after UpdateNotebook = %+v, want archive inside home", got)
+	}
+	projects.OwnerID = stranger
+	if err := store.UpdateNotebook(ctx, projects); !errors.Is(err, notes.ErrNotebookNotFound) {
+		t.Errorf("UpdateNotebook by stranger: err = %v, want notes.ErrNotebookNotFound", err)
+	}
+}
+
+func testDeleteNotebook(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	root := newNotebook("root", nil)
+	middle := newNotebook("middle", &root)
+	leaf := newNotebook("leaf", &middle)
+	mustCreateNotebooks(t, store, root, middle, leaf)
+
+	a, b, c := inNotebook(newNote("a", base), middle), inNotebook(newNote("b", base), leaf), inNotebook(newNote("c", base), leaf)
+	mustCreate(t, store, a, b, c)
+	if err := store.DeleteOne(ctx, owner, c.ID, 0, base); err != nil {
+		t.Fatalf("DeleteOne: %v", err)
+	}
+
+	if err := store.DeleteNotebook(ctx, stranger, middle.ID, notes.NotebookDeleteMove, base); !errors.Is(err, notes.ErrNotebookNotFound) {
+		t.Fatalf("DeleteNotebook by stranger: err = %v, want notes.ErrNotebookNotFound", err)
+	}
+
+	// Содержимое middle переходит к root.
+	if err := store.DeleteNotebook(ctx, owner, middle.ID, notes.NotebookDeleteMove, base.Add(time.Minute)); err != nil {
+		t.Fatalf("DeleteNotebook move: %v", err)
+	}
+	if _, err := store.GetNotebook(ctx, owner, middle.ID); !errors.Is(err, notes.ErrNotebookNotFound) {
+		t.Errorf("GetNotebook after delete: err = %v, want notes.ErrNotebookNotFound", err)
+	}
+	got, err := store.GetNotebook(ctx, owner, leaf.ID)
+	if err != nil || got.ParentID == nil || *got.ParentID != root.ID {
+		t.Errorf("leaf after delete of middle = %+v, %v, want inside root", got, err)
+	}
+	assertLabels(t, queryNotebook(t, store, root.ID, false), "a")
+	if n, _ := store.GetByID(ctx, a.ID); n.Version != 2 {
+		t.Errorf("Version of moved note = %v, want 2", n.Version)
+	}
+
+	// Удаление root в корзину стирает leaf и отправляет в корзину все заметки.
+	if err := store.DeleteNotebook(ctx, owner, root.ID, notes.NotebookDeleteTrash, base.Add(time.Hour)); err != nil {
+		t.Fatalf("DeleteNotebook trash: %v", err)
+	}
+	list, err := store.ListNotebooks(ctx, owner)
+	if err != nil || len(list) != 0 {
+		t.Errorf("ListNotebooks after trash = %+v, %v, want empty", list, err)
+	}
+	if count, err := store.Count(ctx, own); err != nil || count != 0 {
+		t.Errorf("Count after trash = %v, %v, want 0", count, err)
+	}
+
+	trash := own
+	trash.Trashed = true
+	trashed, err := store.Query(ctx, notes.ListArgs{Filter: trash})
+	if err != nil {
+		t.Fatalf("Query trash: %v", err)
+	}
+	assertLabels(t, trashed, "a", "b", "c")
+	for _, n := range trashed {
+		if n.NotebookID != nil {
+			t.Errorf("NotebookID of trashed %v = %v, want nil", n.Label, *n.NotebookID)
+		}
+	}
+
+	if err := store.Restore(ctx, owner, b.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if n, err := store.GetByID(ctx, b.ID); err != nil || n.NotebookID != nil {
+		t.Errorf("restored note = %+v, %v, want in root", n, err)
+	}
+}
+
+func testFlags(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	alpha := newNote("alpha", base.Add(3*time.Hour))
+	bravo := newNote("bravo", base.Add(4*time.Hour))
+	charlie := newNote("charlie", base.Add(1*time.Hour))
+	delta := newNote("delta", base.Add(2*time.Hour))
+	delta.Favorite = true
+	mustCreate(t, store, alpha, bravo, charlie, delta)
+
+	got, err := store.GetByID(ctx, delta.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Pinned || got.Archived || !got.Favorite {
+		t.Fatalf("created flags = %v/%v/%v, want only favorite", got.Pinned, got.Archived, got.Favorite)
+	}
+
+	for _, set := range []struct {
+		n    note.Note
+		flag notes.Flag
+	}{
+		{bravo, notes.FlagPinned},
+		{delta, notes.FlagPinned},
+		{alpha, notes.FlagArchived},
+		{charlie, notes.FlagFavorite},
+	} {
+		if err := store.SetFlag(ctx, set.n.ID, set.flag, true, 0, base.Add(time.Hour)); err != nil {
+			t.Fatalf("SetFlag(%v, %v): %v", set.n.Label, set.flag, err)
+		}
+	}
+	if err := store.SetFlag(ctx, delta.ID, notes.FlagFavorite, false, 1, base.Add(2*time.Hour)); !errors.Is(err, notes.ErrVersionMismatch) {
+		t.Fatalf("SetFlag stale version: err = %v, want notes.ErrVersionMismatch", err)
+	}
+	if err := store.SetFlag(ctx, delta.ID, notes.FlagFavorite, false, 2, base.Add(2*time.Hour)); err != nil {
+		t.Fatalf("SetFlag(delta, favorite, false): %v", err)
+	}
+
+	got, err = store.GetByID(ctx, delta.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !got.Pinned || got.Favorite || got.Version != 3 || !got.UpdatedAt.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("delta = pinned %v, favorite %v, version %v, updated_at %v; want pinned, version 3",
+			got.Pinned, got.Favorite, got.Version, got.UpdatedAt)
+	}
+	revisions, err := store.ListRevisions(ctx, delta.ID)
+	if err != nil {
+		t.Fatalf("ListRevisions: %v", err)
+	}
+	if len(revisions) != 1 {
+		t.Fatalf("got %v revisions, want flags not to add any", len(revisions))
+	}
+
+	yes, no := true, false
+	tests := []struct {
+		name string
+		args notes.ListArgs
+		want []string
+	}{
+		{"label", notes.ListArgs{Filter: own}, []string{"bravo", "delta", "alpha", "charlie"}},
+		{"label desc", notes.ListArgs{Filter: own, SortDirection: notes.SortDirectionDesc}, []string{"delta", "bravo", "charlie", "alpha"}},
+		{"date", notes.ListArgs{Filter: own, SortBy: notes.SortFieldDate}, []string{"delta", "bravo", "charlie", "alpha"}},
+		{"not archived", notes.ListArgs{Filter: notes.Filter{OwnerID: owner, Archived: &no}}, []string{"bravo", "delta", "charlie"}},
+		{"favorite", notes.ListArgs{Filter: notes.Filter{OwnerID: owner, Favorite: &yes}}, []string{"charlie"}},
+		{"not pinned", notes.ListArgs{Filter: notes.Filter{OwnerID: owner, Pinned: &no}}, []string{"alpha", "charlie"}},
+	}
+	for _, tt := range tests {
+		list, err := store.Query(ctx, tt.args)
+		if err != nil {
+			t.Fatalf("%v: Query: %v", tt.name, err)
+		}
+		assertLabels(t, list, tt.want...)
+
+		count, err := store.Count(ctx, tt.args.Filter)
+		if err != nil {
+			t.Fatalf("%v: Count: %v", tt.name, err)
+		}
+		if count != uint(len(tt.want)) {
+			t.Fatalf("%v: Count = %v, want %v", tt.name, count, len(tt.want))
+		}
+	}
+
+	if err := store.SetFlag(ctx, uuid.UUID(ulid.Make()), notes.FlagPinned, true, 0, base); !errors.Is(err, notes.NotFound) {
+		t.Fatalf("SetFlag unknown: err = %v, want notes.NotFound", err)
+	}
+	if err := store.Delete(ctx, owner, []notes.DeleteTarget{{ID: alpha.ID}}, base); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.SetFlag(ctx, alpha.ID, notes.FlagPinned, true, 0, base); !errors.Is(err, notes.NotFound) {
+		t.Fatalf("SetFlag trashed: err = %v, want notes.NotFound", err)
+	}
+}
+
+func testBatch(t *testing.T, store notes.Store) {
+	ctx := context.Background()
+	kept, removed := newNote("kept", base, "a"), newNote("removed", base)
+	mustCreate(t, store, kept, removed)
+
+	label := "renamed"
+	created := newNote("created", base.Add(time.Minute))
+	created.Version = 1
+	created.UpdatedAt = created.CreatedAt
+	rename := notes.BatchWrite{Patch: &notes.PatchArgs{UserID: owner, ID: kept.ID, Label: &label, Version: 1, UpdatedAt: base.Add(time.Minute)}}
+	remove := notes.BatchWrite{Delete: &notes.BatchDelete{OwnerID: owner, ID: removed.ID, Version: 1, DeletedAt: base.Add(time.Minute)}}
+	stale := notes.BatchWrite{Patch: &notes.PatchArgs{UserID: owner, ID: kept.ID, Label: &label, Version: 5, UpdatedAt: base.Add(time.Minute)}}
+
+	// Без continueOnError ошибка откатывает все изменения пакета.
+	errs, err := store.Batch(ctx, []notes.BatchWrite{{Create: &created}, rename, remove, stale}, false)
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if len(errs) != 4 || errs[0] != nil || errs[1] != nil || errs[2] != nil || !errors.Is(errs[3], notes.ErrVersionMismatch) {
+		t.Fatalf("Batch errs = %v, want only the last to be notes.ErrVersionMismatch", errs)
+	}
+	if _, err := store.GetByID(ctx, created.ID); !errors.Is(err, notes.NotFound) {
+		t.Errorf("GetByID(created) after rollback: err = %v, want notes.NotFound", err)
+	}
+	got, err := store.GetByID(ctx, kept.ID)
+	if err != nil || got.Label != kept.Label || got.Version != 1 {
+		t.Errorf("GetByID(kept) after rollback = %+v, %v, want unchanged", got, err)
+	}
+	if revisions, err := store.ListRevisions(ctx, kept.ID); err != nil || len(revisions) != 1 {
+		t.Errorf("ListRevisions(kept) after rollback = %v, %v, want 1 revision", revisions, err)
+	}
+	list, err := store.Query(ctx, notes.ListArgs{Filter: own})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	assertLabels(t, list, "kept", "removed")
+
+	// С continueOnError неудавшееся изменение пропускается, остальные сохраняются.
+	errs, err = store.Batch(ctx, []notes.BatchWrite{{Create: &created}, stale, rename, remove}, true)
+	if err != nil {
+		t.Fatalf("Batch continueOnError: %v", err)
+	}
+	if len(errs) != 4 || errs[0] != nil || !errors.Is(errs[1], notes.ErrVersionMismatch) || errs[2] != nil || errs[3] != nil {
+		t.Fatalf("Batch continueOnError errs = %v, want only the second to be notes.ErrVersionMismatch", errs)
+	}
+	got, err = store.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetByID(created): %v", err)
+	}
+	assertNote(t, got, created)
+	got, err = store.GetByID(ctx, kept.ID)
+	if err != nil || got.Label != label || got.Version != 2 {
+		t.Errorf("GetByID(kept) = %+v, %v, want renamed with version 2", got, err)
+	}
+	if revisions, err := store.ListRevisions(ctx, kept.ID); err != nil || len(revisions) != 2 {
+		t.Errorf("ListRevisions(kept) = %v, %v, want 2 revisions", revisions, err)
+	}
+	list, err = store.Query(ctx, notes.ListArgs{Filter: own})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	assertLabels(t, list, "created", "renamed")
+
+	trashed, err := store.Query(ctx, notes.ListArgs{Filter: notes.Filter{OwnerID: owner, Trashed: true}})
+	if err != nil {
+		t.Fatalf("Query trash: %v", err)
+	}
+	assertLabels(t, trashed, "removed")
+}
@@ -0,0 +1,103 @@
+package notes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrEmptyTag    = apperr.Validation("tag_empty", "tag name is empty")
+	ErrTagNotFound = apperr.NotFound("tag_not_found", "tag is not used by any note")
+)
+
+type ListTagsAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewListTagsAction(store Store, log *zap.Logger) *ListTagsAction {
+	return &ListTagsAction{store: store, log: log}
+}
+
+func (a *ListTagsAction) Do(ctx context.Context, ownerID uuid.UUID) ([]note.Tag, error) {
+	tags, err := a.store.ListTags(ctx, ownerID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "list tags")
+	}
+
+	return tags, nil
+}
+
+type RenameTagArgs struct {
+	OwnerID uuid.UUID `json:"-"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+}
+
+type RenameTagAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewRenameTagAction(store Store, log *zap.Logger) *RenameTagAction {
+	return &RenameTagAction{store: store, log: log}
+}
+
+// Do переименовывает тег во всех заметках. Если у заметки уже есть новый тег,
+// дубликат не появляется. Возвращает ErrTagNotFound, если тег не используется.
+func (a *RenameTagAction) Do(ctx context.Context, args RenameTagArgs) (note.Tag, error) {
+	args.From, args.To = strings.TrimSpace(args.From), strings.TrimSpace(args.To)
+	if args.From == "" {
+		return note.Tag{}, ErrEmptyTag.WithField("tag", "is required")
+	}
+	if args.To == "" {
+		return note.Tag{}, ErrEmptyTag.WithField("name", "is required")
+	}
+
+	count, err := a.store.RenameTag(ctx, args.OwnerID, args.From, args.To)
+	if err != nil {
+		return note.Tag{}, errors.WithMessage(err, "rename tag")
+	}
+	if count == 0 {
+		return note.Tag{}, ErrTagNotFound
+	}
+
+	a.log.Debug("Renamed tag", zap.Any("args", args), zap.Uint("notes", count))
+
+	return note.Tag{Name: args.To, Count: count}, nil
+}
+
+type DeleteTagAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewDeleteTagAction(store Store, log *zap.Logger) *DeleteTagAction {
+	return &DeleteTagAction{store: store, log: log}
+}
+
+// Do убирает тег из всех заметок. Возвращает ErrTagNotFound, если тег не используется.
+func (a *DeleteTagAction) Do(ctx context.Context, ownerID uuid.UUID, tag string) error {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return ErrEmptyTag.WithField("tag", "is required")
+	}
+
+	count, err := a.store.DeleteTag(ctx, ownerID, tag)
+	if err != nil {
+		return errors.WithMessage(err, "delete tag")
+	}
+	if count == 0 {
+		return ErrTagNotFound
+	}
+
+	a.log.Debug("Deleted tag", zap.String("tag", tag), zap.Uint("notes", count))
+
+	return nil
+}
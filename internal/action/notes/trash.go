@@ -0,0 +1,101 @@
+package notes
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type RestoreAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewRestoreAction(store Store, log *zap.Logger) *RestoreAction {
+	return &RestoreAction{store: store, log: log}
+}
+
+// Do возвращает заметку из корзины вместе с правами, ссылкой и историей.
+func (a *RestoreAction) Do(ctx context.Context, ownerID, noteID uuid.UUID) (note.Note, error) {
+	if err := a.store.Restore(ctx, ownerID, noteID); err != nil {
+		return note.Note{}, errors.WithMessage(err, "restore note")
+	}
+
+	restored, err := a.store.GetByID(ctx, noteID)
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "get restored note")
+	}
+
+	a.log.Debug("Restored note from trash", zap.Any("noteID", noteID))
+
+	return restored, nil
+}
+
+type PurgeAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewPurgeAction(store Store, log *zap.Logger) *PurgeAction {
+	return &PurgeAction{store: store, log: log}
+}
+
+// Do стирает заметку из корзины без возможности восстановления.
+func (a *PurgeAction) Do(ctx context.Context, ownerID, noteID uuid.UUID) error {
+	if err := a.store.Purge(ctx, ownerID, noteID); err != nil {
+		return errors.WithMessage(err, "purge note")
+	}
+
+	a.log.Debug("Purged note", zap.Any("noteID", noteID))
+
+	return nil
+}
+
+// Purger фоновая очистка корзины от заметок старше retention.
+type Purger struct {
+	store     Store
+	retention time.Duration
+	interval  time.Duration
+	log       *zap.Logger
+}
+
+func NewPurger(store Store, retention, interval time.Duration, log *zap.Logger) *Purger {
+	return &Purger{store: store, retention: retention, interval: interval, log: log}
+}
+
+// Do стирает заметки, пролежавшие в корзине дольше retention, и возвращает их число.
+func (p *Purger) Do(ctx context.Context) (uint, error) {
+	count, err := p.store.PurgeDeleted(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		return 0, errors.WithMessage(err, "purge trash")
+	}
+
+	return count, nil
+}
+
+// Run чистит корзину сразу и затем каждые interval, пока ctx не отменён.
+// Ошибки только пишутся в лог: следующий проход попробует снова.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := p.Do(ctx)
+		switch {
+		case err != nil:
+			p.log.Error("Failed to purge trash", zap.Error(err))
+		case count > 0:
+			p.log.Info("Purged notes from trash", zap.Uint("count", count))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,6 +2,7 @@ package notes
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
@@ -10,10 +11,30 @@ import (
 )
 
 type UpdateArgs struct {
-	ID    uuid.UUID `json:"id"`
-	Label string    `json:"label"`
-	Body  string    `json:"body"`
-	Tags  []string  `json:"tags"`
+	// UserID кто изменяет заметку: владелец или пользователь с правом write.
+	UserID uuid.UUID `json:"-"`
+	ID     uuid.UUID `json:"id"`
+	Label  string    `json:"label"`
+	Body   string    `json:"body"`
+	Tags   []string  `json:"tags"`
+	// Version версия, которую видел клиент (If-Match), 0 - без проверки.
+	Version uint `json:"-"`
+	// UpdatedAt время изменения, его выставляет действие.
+	UpdatedAt time.Time `json:"-"`
+}
+
+// Patch полная замена содержимого в виде частичного изменения всех полей.
+func (a UpdateArgs) Patch() PatchArgs {
+	tags := a.Tags
+	return PatchArgs{
+		UserID:    a.UserID,
+		ID:        a.ID,
+		Label:     &a.Label,
+		Body:      &a.Body,
+		Tags:      &tags,
+		Version:   a.Version,
+		UpdatedAt: a.UpdatedAt,
+	}
 }
 
 type UpdateAction struct {
@@ -28,16 +49,36 @@ func NewUpdateAction(store Store, log *zap.Logger) *UpdateAction {
 	}
 }
 
+// Do заменяет содержимое заметки. Результат проверяется note.Note.Validate,
+// как при создании и частичном изменении.
 func (a *UpdateAction) Do(ctx context.Context, args UpdateArgs) (note.Note, error) {
+	if err := authorize(ctx, a.store, args.UserID, args.ID, note.PermissionWrite); err != nil {
+		return note.Note{}, err
+	}
+
+	current, err := a.store.GetByID(ctx, args.ID)
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "get note to update")
+	}
+	if args.Version != 0 && args.Version != current.Version {
+		return note.Note{}, ErrVersionMismatch
+	}
+
+	replaced := current
+	replaced.Label, replaced.Body, replaced.Tags = args.Label, args.Body, args.Tags
+	if err := replaced.Validate(); err != nil {
+		return note.Note{}, errors.WithMessage(err, "validate updated note")
+	}
 
-	err := a.store.Update(ctx, args)
+	args.UpdatedAt = time.Now()
+	err = a.store.Update(ctx, args)
 	if err != nil {
 		return note.Note{}, errors.WithMessage(err, "Failed action update")
 	}
 
 	getByIdAction := NewGetByIDAction(a.store, a.log)
 
-	updatedNote, err := getByIdAction.Do(ctx, args.ID)
+	updatedNote, err := getByIdAction.Do(ctx, args.UserID, args.ID)
 	if err != nil {
 		return note.Note{}, errors.WithMessage(err, "failed during getting updated note")
 	}
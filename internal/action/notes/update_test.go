@@ -0,0 +1,50 @@
+package notes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/adaptor"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+func TestUpdateActionValidates(t *testing.T) {
+	ctx := context.Background()
+	store := adaptor.NewMemoryNoteStore(zap.NewNop())
+	owner := uuid.NewV4()
+	n := note.Note{ID: uuid.NewV4(), OwnerID: owner, Label: "label", Body: "body", CreatedAt: time.Now(), UpdatedAt: time.Now(), Version: 1}
+	if err := store.Create(ctx, n); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	action := notes.NewUpdateAction(store, zap.NewNop())
+
+	_, err := action.Do(ctx, notes.UpdateArgs{UserID: owner, ID: n.ID, Body: "new body", Version: 1})
+	if e, ok := apperr.As(err); !ok || e.Kind != apperr.KindValidation || e.Code != "note_invalid" {
+		t.Fatalf("Do with empty label: err = %v, want note_invalid", err)
+	}
+	got, err := store.GetByID(ctx, n.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Label != "label" || got.Body != "body" || got.Version != 1 {
+		t.Fatalf("note = %+v, want unchanged", got)
+	}
+
+	if _, err := action.Do(ctx, notes.UpdateArgs{UserID: owner, ID: n.ID, Label: "new", Version: 2}); !errors.Is(err, notes.ErrVersionMismatch) {
+		t.Fatalf("Do with stale version: err = %v, want %v", err, notes.ErrVersionMismatch)
+	}
+
+	updated, err := action.Do(ctx, notes.UpdateArgs{UserID: owner, ID: n.ID, Label: "new", Version: 1})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if updated.Label != "new" || updated.Body != "" || updated.Version != 2 {
+		t.Errorf("updated = %+v, want label new, empty body, version 2", updated)
+	}
+}
@@ -0,0 +1,135 @@
+package tokens
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+	"unicode/utf8"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+const (
+	maxNameLength = 100
+	// secretSize 256 бит случайных данных, достаточно для хранения в виде SHA-256.
+	secretSize = 32
+)
+
+type CreateArgs struct {
+	Name string `json:"name"`
+	// Scopes по умолчанию все scopes текущего токена, которые можно выдать API-токену.
+	Scopes []string `json:"scopes,omitempty"`
+	// ExpiresAt без срока токен действует до отзыва.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type CreateAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewCreateAction(store Store, log *zap.Logger) *CreateAction {
+	return &CreateAction{store: store, log: log}
+}
+
+// Do создаёт токен с scopes не шире, чем у principal.
+func (a *CreateAction) Do(ctx context.Context, principal user.Principal, args CreateArgs) (user.IssuedAPIToken, error) {
+	now := time.Now()
+	if err := validateCreate(args, now); err != nil {
+		return user.IssuedAPIToken{}, err
+	}
+
+	scopes := args.Scopes
+	if len(scopes) == 0 {
+		for _, scope := range principal.Scopes {
+			if knownScope(scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	for _, scope := range scopes {
+		if !principal.HasScope(scope) {
+			return user.IssuedAPIToken{}, ErrScopeNotGranted.WithField("scopes", scope+" is not granted to the current token")
+		}
+	}
+
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return user.IssuedAPIToken{}, errors.Wrap(err, "generate token")
+	}
+	raw := user.APITokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	token := user.APIToken{
+		ID:        uuid.UUID(ulid.Make()),
+		UserID:    principal.UserID,
+		Name:      args.Name,
+		Scopes:    uniqueScopes(scopes),
+		Hash:      hashToken(raw),
+		CreatedAt: now,
+		ExpiresAt: args.ExpiresAt,
+	}
+
+	err := a.store.Create(ctx, token)
+	if err != nil {
+		return user.IssuedAPIToken{}, errors.WithMessage(err, "save api token")
+	}
+
+	a.log.Debug("Created api token", zap.Any("tokenID", token.ID), zap.Any("userID", token.UserID))
+
+	return user.IssuedAPIToken{APIToken: token, Token: raw}, nil
+}
+
+func validateCreate(args CreateArgs, now time.Time) error {
+	fields := []apperr.FieldError{}
+
+	if args.Name == "" {
+		fields = append(fields, apperr.FieldError{Field: "name", Message: "is required"})
+	} else if utf8.RuneCountInString(args.Name) > maxNameLength {
+		fields = append(fields, apperr.FieldError{Field: "name", Message: "must be at most 100 characters"})
+	}
+
+	for _, scope := range args.Scopes {
+		if !knownScope(scope) {
+			fields = append(fields, apperr.FieldError{Field: "scopes", Message: "unknown scope " + scope})
+		}
+	}
+
+	if args.ExpiresAt != nil && !args.ExpiresAt.After(now) {
+		fields = append(fields, apperr.FieldError{Field: "expires_at", Message: "must be in the future"})
+	}
+
+	if len(fields) > 0 {
+		return apperr.Validation("token_invalid", "api token is invalid", fields...)
+	}
+
+	return nil
+}
+
+func knownScope(scope string) bool {
+	for _, known := range user.KnownScopes {
+		if scope == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func uniqueScopes(scopes []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+
+	return result
+}
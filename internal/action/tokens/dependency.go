@@ -0,0 +1,32 @@
+package tokens
+
+import (
+	"context"
+	"crypto/sha256"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+)
+
+type Store interface {
+	Create(ctx context.Context, token user.APIToken) error
+	// List токены пользователя, новые первыми.
+	List(ctx context.Context, userID uuid.UUID) ([]user.APIToken, error)
+	// Delete возвращает NotFound, если у пользователя нет такого токена.
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	GetByHash(ctx context.Context, hash []byte) (user.APIToken, error)
+	// Touch записывает время последнего использования токена.
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+var (
+	NotFound           = apperr.NotFound("token_not_found", "api token not found")
+	ErrScopeNotGranted = apperr.Forbidden("scope_not_granted", "cannot grant a scope the current token does not have")
+)
+
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
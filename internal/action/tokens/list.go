@@ -0,0 +1,28 @@
+package tokens
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+type ListAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewListAction(store Store, log *zap.Logger) *ListAction {
+	return &ListAction{store: store, log: log}
+}
+
+func (a *ListAction) Do(ctx context.Context, userID uuid.UUID) ([]user.APIToken, error) {
+	list, err := a.store.List(ctx, userID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "list api tokens")
+	}
+
+	return list, nil
+}
@@ -0,0 +1,29 @@
+package tokens
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"go.uber.org/zap"
+)
+
+type RevokeAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewRevokeAction(store Store, log *zap.Logger) *RevokeAction {
+	return &RevokeAction{store: store, log: log}
+}
+
+func (a *RevokeAction) Do(ctx context.Context, userID, id uuid.UUID) error {
+	err := a.store.Delete(ctx, userID, id)
+	if err != nil {
+		return errors.WithMessage(err, "revoke api token")
+	}
+
+	a.log.Debug("Revoked api token", zap.Any("tokenID", id))
+
+	return nil
+}
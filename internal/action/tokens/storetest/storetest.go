@@ -0,0 +1,183 @@
+// Package storetest проверки tokens.Store: выпуск, поиск по хешу, список,
+// отметка использования и отзыв токенов.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+)
+
+// Factory пустое хранилище токенов. Владельцы токенов owners должны существовать,
+// если хранилище проверяет ссылку на пользователя.
+type Factory func(t *testing.T, owners ...uuid.UUID) tokens.Store
+
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store tokens.Store)
+	}{
+		{"CreateAndGetByHash", testCreateAndGetByHash},
+		{"List", testList},
+		{"Delete", testDelete},
+		{"Touch", testTouch},
+		{"Unknown", testUnknown},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t, owner, stranger))
+		})
+	}
+}
+
+var (
+	owner    = uuid.FromStringOrNil("01890000-0000-7000-8000-000000000001")
+	stranger = uuid.FromStringOrNil("01890000-0000-7000-8000-000000000002")
+)
+
+// base время с точностью до микросекунд, как хранит postgres.
+var base = time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+
+func newToken(userID uuid.UUID, name string, createdAt time.Time) user.APIToken {
+	return user.APIToken{
+		ID:        uuid.UUID(ulid.Make()),
+		UserID:    userID,
+		Name:      name,
+		Scopes:    []string{user.ScopeNotesRead},
+		Hash:      []byte("hash of " + name),
+		CreatedAt: createdAt,
+	}
+}
+
+func create(t *testing.T, store tokens.Store, list ...user.APIToken) {
+	t.Helper()
+
+	for _, token := range list {
+		if err := store.Create(context.Background(), token); err != nil {
+			t.Fatalf("Create %v: %v", token.Name, err)
+		}
+	}
+}
+
+func assertToken(t *testing.T, got, want user.APIToken) {
+	t.Helper()
+
+	if got.ID != want.ID || got.UserID != want.UserID || got.Name != want.Name || string(got.Hash) != string(want.Hash) {
+		t.Errorf("token = %v %v %q %q, want %v %v %q %q",
+			got.ID, got.UserID, got.Name, got.Hash, want.ID, want.UserID, want.Name, want.Hash)
+	}
+	if len(got.Scopes) != len(want.Scopes) || (len(got.Scopes) > 0 && got.Scopes[0] != want.Scopes[0]) {
+		t.Errorf("Scopes = %v, want %v", got.Scopes, want.Scopes)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if !equalTimePtr(got.ExpiresAt, want.ExpiresAt) {
+		t.Errorf("ExpiresAt = %v, want %v", got.ExpiresAt, want.ExpiresAt)
+	}
+	if !equalTimePtr(got.LastUsedAt, want.LastUsedAt) {
+		t.Errorf("LastUsedAt = %v, want %v", got.LastUsedAt, want.LastUsedAt)
+	}
+}
+
+func equalTimePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+func testCreateAndGetByHash(t *testing.T, store tokens.Store) {
+	expiresAt := base.Add(time.Hour)
+	token := newToken(owner, "ci", base)
+	token.Scopes = []string{user.ScopeNotesRead, user.ScopeNotesWrite}
+	token.ExpiresAt = &expiresAt
+	create(t, store, token)
+
+	got, err := store.GetByHash(context.Background(), token.Hash)
+	if err != nil {
+		t.Fatalf("GetByHash: %v", err)
+	}
+	assertToken(t, got, token)
+	if len(got.Scopes) != 2 || got.Scopes[1] != user.ScopeNotesWrite {
+		t.Errorf("Scopes = %v, want %v", got.Scopes, token.Scopes)
+	}
+}
+
+func testList(t *testing.T, store tokens.Store) {
+	older := newToken(owner, "older", base)
+	newer := newToken(owner, "newer", base.Add(time.Minute))
+	create(t, store, older, newer, newToken(stranger, "other", base))
+
+	list, err := store.List(context.Background(), owner)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("List returned %d tokens, want 2", len(list))
+	}
+	assertToken(t, list[0], newer)
+	assertToken(t, list[1], older)
+
+	list, err = store.List(context.Background(), uuid.NewV4())
+	if err != nil || list == nil || len(list) != 0 {
+		t.Errorf("List for user without tokens = %v, %v, want empty list", list, err)
+	}
+}
+
+func testDelete(t *testing.T, store tokens.Store) {
+	ctx := context.Background()
+	token := newToken(owner, "ci", base)
+	create(t, store, token)
+
+	if err := store.Delete(ctx, stranger, token.ID); !errors.Is(err, tokens.NotFound) {
+		t.Fatalf("Delete by stranger: err = %v, want tokens.NotFound", err)
+	}
+	if err := store.Delete(ctx, owner, token.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.GetByHash(ctx, token.Hash); !errors.Is(err, tokens.NotFound) {
+		t.Errorf("GetByHash after Delete: err = %v, want tokens.NotFound", err)
+	}
+	if err := store.Delete(ctx, owner, token.ID); !errors.Is(err, tokens.NotFound) {
+		t.Errorf("Delete twice: err = %v, want tokens.NotFound", err)
+	}
+}
+
+func testTouch(t *testing.T, store tokens.Store) {
+	ctx := context.Background()
+	token := newToken(owner, "ci", base)
+	create(t, store, token)
+
+	usedAt := base.Add(time.Hour)
+	if err := store.Touch(ctx, token.ID, usedAt); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+
+	got, err := store.GetByHash(ctx, token.Hash)
+	if err != nil {
+		t.Fatalf("GetByHash: %v", err)
+	}
+	token.LastUsedAt = &usedAt
+	assertToken(t, got, token)
+}
+
+func testUnknown(t *testing.T, store tokens.Store) {
+	ctx := context.Background()
+
+	if _, err := store.GetByHash(ctx, []byte("unknown")); !errors.Is(err, tokens.NotFound) {
+		t.Errorf("GetByHash unknown: err = %v, want tokens.NotFound", err)
+	}
+	if err := store.Touch(ctx, uuid.NewV4(), base); !errors.Is(err, tokens.NotFound) {
+		t.Errorf("Touch unknown: err = %v, want tokens.NotFound", err)
+	}
+}
@@ -0,0 +1,49 @@
+package tokens
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+// touchInterval как часто обновлять время последнего использования, чтобы
+// не писать в базу на каждый запрос.
+const touchInterval = time.Minute
+
+type VerifyAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewVerifyAction(store Store, log *zap.Logger) *VerifyAction {
+	return &VerifyAction{store: store, log: log}
+}
+
+// Do находит действующий API-токен и возвращает его владельца и scopes.
+func (a *VerifyAction) Do(ctx context.Context, raw string) (user.Principal, error) {
+	token, err := a.store.GetByHash(ctx, hashToken(raw))
+	if errors.Is(err, NotFound) {
+		return user.Principal{}, users.ErrInvalidToken
+	}
+	if err != nil {
+		return user.Principal{}, errors.WithMessage(err, "find api token")
+	}
+
+	now := time.Now()
+	if token.Expired(now) {
+		return user.Principal{}, users.ErrInvalidToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= touchInterval {
+		// Ошибка записи не должна отклонять запрос с действующим токеном.
+		if err := a.store.Touch(ctx, token.ID, now); err != nil {
+			a.log.Warn("update api token last used time", zap.Any("tokenID", token.ID), zap.Error(err))
+		}
+	}
+
+	return user.Principal{UserID: token.UserID, Scopes: token.Scopes}, nil
+}
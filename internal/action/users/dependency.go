@@ -0,0 +1,29 @@
+package users
+
+import (
+	"context"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+)
+
+type Store interface {
+	// Create возвращает ErrEmailTaken, если email уже занят.
+	Create(ctx context.Context, user user.User) error
+	GetByID(ctx context.Context, id uuid.UUID) (user.User, error)
+	// GetByEmail ищет по email, уже приведённому к нижнему регистру.
+	GetByEmail(ctx context.Context, email string) (user.User, error)
+}
+
+// TokenIssuer выдаёт токен доступа для пользователя.
+type TokenIssuer interface {
+	Issue(user user.User) (user.Token, error)
+}
+
+var (
+	NotFound              = apperr.NotFound("user_not_found", "user not found")
+	ErrEmailTaken         = apperr.Conflict("email_taken", "user with this email already exists")
+	ErrInvalidCredentials = apperr.Unauthorized("invalid_credentials", "email or password is incorrect")
+	ErrInvalidToken       = apperr.Unauthorized("invalid_token", "access token is invalid or expired")
+)
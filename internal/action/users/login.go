@@ -0,0 +1,53 @@
+package users
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash сравнивается с паролем, когда пользователя нет, чтобы время ответа
+// не выдавало существование email.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+type LoginArgs struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type LoginAction struct {
+	store  Store
+	tokens TokenIssuer
+	log    *zap.Logger
+}
+
+func NewLoginAction(store Store, tokens TokenIssuer, log *zap.Logger) *LoginAction {
+	return &LoginAction{store: store, tokens: tokens, log: log}
+}
+
+func (a *LoginAction) Do(ctx context.Context, args LoginArgs) (user.Token, error) {
+	u, err := a.store.GetByEmail(ctx, normalizeEmail(args.Email))
+	switch {
+	case errors.Is(err, NotFound):
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(args.Password))
+		return user.Token{}, ErrInvalidCredentials
+	case err != nil:
+		return user.Token{}, errors.WithMessage(err, "get user by email")
+	}
+
+	if bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(args.Password)) != nil {
+		return user.Token{}, ErrInvalidCredentials
+	}
+
+	token, err := a.tokens.Issue(u)
+	if err != nil {
+		return user.Token{}, errors.WithMessage(err, "issue token")
+	}
+
+	a.log.Debug("User logged in", zap.Any("userID", u.ID))
+
+	return token, nil
+}
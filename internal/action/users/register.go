@@ -0,0 +1,94 @@
+package users
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	// maxPasswordLength bcrypt учитывает только первые 72 байта.
+	maxPasswordLength = 72
+)
+
+type RegisterArgs struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type RegisterAction struct {
+	store Store
+	log   *zap.Logger
+}
+
+func NewRegisterAction(store Store, log *zap.Logger) *RegisterAction {
+	return &RegisterAction{store: store, log: log}
+}
+
+func (a *RegisterAction) Do(ctx context.Context, args RegisterArgs) (user.User, error) {
+	email, err := validateCredentials(args)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(args.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return user.User{}, errors.Wrap(err, "hash password")
+	}
+
+	newUser := user.User{
+		ID:           uuid.UUID(ulid.Make()),
+		Email:        email,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	}
+	if err := newUser.Validate(); err != nil {
+		return user.User{}, err
+	}
+
+	err = a.store.Create(ctx, newUser)
+	if err != nil {
+		return user.User{}, errors.WithMessage(err, "save user")
+	}
+
+	a.log.Debug("Registered user", zap.Any("userID", newUser.ID))
+
+	return newUser, nil
+}
+
+// validateCredentials проверяет email и пароль и возвращает нормализованный email.
+func validateCredentials(args RegisterArgs) (string, error) {
+	fields := []apperr.FieldError{}
+
+	email := normalizeEmail(args.Email)
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		fields = append(fields, apperr.FieldError{Field: "email", Message: "must be a valid email address"})
+	}
+
+	if utf8.RuneCountInString(args.Password) < minPasswordLength {
+		fields = append(fields, apperr.FieldError{Field: "password", Message: "must be at least 8 characters"})
+	} else if len(args.Password) > maxPasswordLength {
+		fields = append(fields, apperr.FieldError{Field: "password", Message: "must be at most 72 bytes"})
+	}
+
+	if len(fields) > 0 {
+		return "", apperr.Validation("credentials_invalid", "email or password is invalid", fields...)
+	}
+
+	return email, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,102 @@
+// Package storetest проверки users.Store: создание, поиск и уникальность почты.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+)
+
+// Factory пустое хранилище пользователей.
+type Factory func(t *testing.T) users.Store
+
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store users.Store)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"EmailTaken", testEmailTaken},
+		{"Unknown", testUnknown},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+// base время с точностью до микросекунд, как хранит postgres.
+var base = time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+
+func newUser(email string) user.User {
+	return user.User{
+		ID:           uuid.UUID(ulid.Make()),
+		Email:        email,
+		PasswordHash: []byte("hash of " + email),
+		CreatedAt:    base,
+	}
+}
+
+func assertUser(t *testing.T, got, want user.User) {
+	t.Helper()
+
+	if got.ID != want.ID || got.Email != want.Email || string(got.PasswordHash) != string(want.PasswordHash) {
+		t.Errorf("user = %v %v %q, want %v %v %q",
+			got.ID, got.Email, got.PasswordHash, want.ID, want.Email, want.PasswordHash)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+}
+
+func testCreateAndGet(t *testing.T, store users.Store) {
+	ctx := context.Background()
+	u := newUser("alice@example.com")
+	if err := store.Create(ctx, u); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := store.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertUser(t, got, u)
+
+	got, err = store.GetByEmail(ctx, u.Email)
+	if err != nil {
+		t.Fatalf("GetByEmail: %v", err)
+	}
+	assertUser(t, got, u)
+}
+
+func testEmailTaken(t *testing.T, store users.Store) {
+	ctx := context.Background()
+	if err := store.Create(ctx, newUser("alice@example.com")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	err := store.Create(ctx, newUser("alice@example.com"))
+	if !errors.Is(err, users.ErrEmailTaken) {
+		t.Fatalf("Create with taken email: err = %v, want users.ErrEmailTaken", err)
+	}
+}
+
+func testUnknown(t *testing.T, store users.Store) {
+	ctx := context.Background()
+
+	if _, err := store.GetByID(ctx, uuid.NewV4()); !errors.Is(err, users.NotFound) {
+		t.Errorf("GetByID unknown: err = %v, want users.NotFound", err)
+	}
+	if _, err := store.GetByEmail(ctx, "nobody@example.com"); !errors.Is(err, users.NotFound) {
+		t.Errorf("GetByEmail unknown: err = %v, want users.NotFound", err)
+	}
+}
@@ -0,0 +1,137 @@
+package adaptor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+const APITokenTable = "api_tokens"
+
+const apiTokenColumns = "id, user_id, name, scopes, token_hash, created_at, expires_at, last_used_at"
+
+type APITokenStore struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+func NewAPITokenStore(db *sql.DB, logger *zap.Logger) *APITokenStore {
+	return &APITokenStore{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (s *APITokenStore) Create(ctx context.Context, t user.APIToken) error {
+	s.log.Debug("saving api token", zap.Any("tokenID", t.ID))
+
+	query := fmt.Sprintf(
+		`INSERT INTO %v (%v) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		APITokenTable, apiTokenColumns,
+	)
+
+	_, err := s.db.ExecContext(ctx, query,
+		t.ID, t.UserID, t.Name, pq.Array(t.Scopes), t.Hash, t.CreatedAt, t.ExpiresAt, t.LastUsedAt)
+
+	return errors.Wrap(err, "save api token to database")
+}
+
+func (s *APITokenStore) List(ctx context.Context, userID uuid.UUID) ([]user.APIToken, error) {
+	query := fmt.Sprintf(
+		`SELECT %v FROM %v WHERE user_id = $1 ORDER BY created_at DESC, id DESC`,
+		apiTokenColumns, APITokenTable,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list api tokens")
+	}
+	defer rows.Close()
+
+	list := []user.APIToken{}
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+
+	return list, errors.Wrap(rows.Err(), "read api tokens")
+}
+
+func (s *APITokenStore) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	s.log.Debug("deleting api token", zap.Any("tokenID", id))
+
+	query := fmt.Sprintf(`DELETE FROM %v WHERE id = $1 AND user_id = $2`, APITokenTable)
+
+	result, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return errors.Wrap(err, "delete api token")
+	}
+
+	return tokenAffected(result)
+}
+
+func (s *APITokenStore) GetByHash(ctx context.Context, hash []byte) (user.APIToken, error) {
+	query := fmt.Sprintf(`SELECT %v FROM %v WHERE token_hash = $1`, apiTokenColumns, APITokenTable)
+
+	t, err := scanAPIToken(s.db.QueryRowContext(ctx, query, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.APIToken{}, tokens.NotFound
+	}
+
+	return t, err
+}
+
+func (s *APITokenStore) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := fmt.Sprintf(`UPDATE %v SET last_used_at = $1 WHERE id = $2`, APITokenTable)
+
+	result, err := s.db.ExecContext(ctx, query, at, id)
+	if err != nil {
+		return errors.Wrap(err, "touch api token")
+	}
+
+	return tokenAffected(result)
+}
+
+func scanAPIToken(row interface{ Scan(...any) error }) (user.APIToken, error) {
+	var (
+		t          user.APIToken
+		expiresAt  sql.NullTime
+		lastUsedAt sql.NullTime
+	)
+
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, pq.Array(&t.Scopes), &t.Hash, &t.CreatedAt, &expiresAt, &lastUsedAt)
+	if err != nil {
+		return user.APIToken{}, errors.Wrap(err, "scan api token")
+	}
+	if expiresAt.Valid {
+		t.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		t.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return t, nil
+}
+
+func tokenAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+	if affected == 0 {
+		return tokens.NotFound
+	}
+
+	return nil
+}
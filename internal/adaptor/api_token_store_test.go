@@ -0,0 +1,19 @@
+package adaptor
+
+import (
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens/storetest"
+	"go.uber.org/zap"
+)
+
+func TestAPITokenStore(t *testing.T) {
+	db := openTestPostgres(t)
+
+	storetest.Run(t, func(t *testing.T, owners ...uuid.UUID) tokens.Store {
+		resetTables(t, db, owners...)
+		return NewAPITokenStore(db, zap.NewNop())
+	})
+}
@@ -0,0 +1,61 @@
+package adaptor
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+)
+
+// applyBatch применяет изменения пакета внутри tx и возвращает ошибку каждого
+// из них. Без continueOnError останавливается на первой ошибке, транзакцию
+// откатывает вызывающий. С continueOnError неудавшееся изменение откатывается
+// до точки сохранения, остальные продолжаются. Синтаксис точек сохранения
+// одинаков в postgres и SQLite.
+func applyBatch(
+	ctx context.Context,
+	tx *sql.Tx,
+	writes []notes.BatchWrite,
+	continueOnError bool,
+	apply func(write notes.BatchWrite) error,
+) ([]error, error) {
+	errs := make([]error, len(writes))
+	for i, write := range writes {
+		if !continueOnError {
+			if errs[i] = apply(write); errs[i] != nil {
+				return errs, nil
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT batch_write`); err != nil {
+			return errs, errors.Wrap(err, "create savepoint")
+		}
+		if errs[i] = apply(write); errs[i] != nil {
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch_write`); err != nil {
+				return errs, errors.Wrap(err, "rollback to savepoint")
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT batch_write`); err != nil {
+			return errs, errors.Wrap(err, "release savepoint")
+		}
+	}
+
+	return errs, nil
+}
+
+// committable можно ли фиксировать пакет: без continueOnError - только если
+// все изменения удались.
+func committable(errs []error, continueOnError bool) bool {
+	if continueOnError {
+		return true
+	}
+	for _, err := range errs {
+		if err != nil {
+			return false
+		}
+	}
+
+	return true
+}
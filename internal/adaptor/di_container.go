@@ -2,45 +2,268 @@ package adaptor
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
 	"database/sql"
 
 	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/config"
+	"github.com/victor8titov/rest-api-notes/internal/migrations"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type DIContainer struct {
-	database *sql.DB
-	log      *zap.Logger
+	config      config.Config
+	database    *sql.DB
+	memory      *MemoryNoteStore
+	memoryUsers *MemoryUserStore
+	memoryKeys  *MemoryAPITokenStore
+	memoryIdem  *MemoryIdempotencyStore
+	cursors     *notes.CursorCodec
+	tokens      *JWTIssuer
+	verifier    *JWTVerifier
+	renderer    *MarkdownRenderer
+	log         *zap.Logger
 }
 
-func NewDIContainer() (*DIContainer, error) {
-	logger, err := zap.NewDevelopment()
+var ErrMigrationsUnsupported = errors.New("migrations are supported only for postgres")
+
+func NewDIContainer(cfg config.Config) (*DIContainer, error) {
+	logger, err := newLogger(cfg.Log)
 	if err != nil {
-		panic(err) // Не удалось создать логгер
+		return nil, errors.WithMessage(err, "create logger")
 	}
 	defer logger.Sync()
 
-	connStr := "user=postgres password=postgres dbname=notesapp sslmode=disable host=127.0.0.1"
+	cursorSecret, err := secretOrRandom(cfg.Pagination.CursorSecret)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cursor secret")
+	}
+	if cfg.Pagination.CursorSecret == "" {
+		logger.Warn("pagination.cursorSecret is not set, cursors will not survive restart")
+	}
+
+	jwtSecret, err := secretOrRandom(cfg.Auth.JWTSecret)
+	if err != nil {
+		return nil, errors.WithMessage(err, "jwt secret")
+	}
+	if cfg.Auth.JWTSecret == "" {
+		logger.Warn("auth.jwtSecret is not set, access tokens will not survive restart")
+	}
 
-	db, err := sql.Open("postgres", connStr)
+	verifier, err := newJWTVerifier(cfg.Auth, jwtSecret)
 	if err != nil {
-		panic(err)
+		return nil, errors.WithMessage(err, "jwt verifier")
 	}
 
-	return &DIContainer{
-		database: db,
+	di := &DIContainer{
+		config:   cfg,
+		cursors:  notes.NewCursorCodec(cursorSecret),
+		tokens:   NewJWTIssuer(jwtSecret, cfg.Auth.TokenTTL.Duration, cfg.Auth.Issuer, cfg.Auth.Audience),
+		verifier: verifier,
+		renderer: NewMarkdownRenderer(),
 		log:      logger,
-	}, nil
+	}
+
+	switch cfg.Database.Driver {
+	case config.DriverMemory:
+		di.memory = NewMemoryNoteStore(logger)
+		di.memoryUsers = NewMemoryUserStore(logger)
+		di.memoryKeys = NewMemoryAPITokenStore(logger)
+		di.memoryIdem = NewMemoryIdempotencyStore(logger)
+	case config.DriverSQLite:
+		di.database, err = sql.Open("sqlite", cfg.Database.DSN)
+		if err != nil {
+			return nil, errors.Wrap(err, "open database")
+		}
+		// SQLite допускает только одного писателя.
+		di.database.SetMaxOpenConns(1)
+
+		err = NewSQLiteUserStore(di.database, logger).CreateTable(context.Background())
+		if err == nil {
+			err = NewSQLiteNoteStore(di.database, logger).CreateTable(context.Background())
+		}
+		if err == nil {
+			err = NewSQLiteAPITokenStore(di.database, logger).CreateTable(context.Background())
+		}
+		if err == nil {
+			err = NewSQLiteIdempotencyStore(di.database, logger).CreateTable(context.Background())
+		}
+		if err != nil {
+			di.database.Close()
+			return nil, errors.WithMessage(err, "init sqlite schema")
+		}
+	default:
+		di.database, err = sql.Open("postgres", cfg.Database.DSN)
+		if err != nil {
+			return nil, errors.Wrap(err, "open database")
+		}
+	}
+
+	return di, nil
+}
+
+// secretOrRandom возвращает ключ из конфигурации или 32 случайных байта, если он пуст.
+func secretOrRandom(value string) ([]byte, error) {
+	if value != "" {
+		return []byte(value), nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "generate secret")
+	}
+
+	return secret, nil
+}
+
+// newJWTVerifier собирает ключи проверки: секрет HS256, PEM и JWKS из конфигурации.
+func newJWTVerifier(cfg config.AuthConfig, secret []byte) (*JWTVerifier, error) {
+	keys := JWTKeys{
+		Secret:  secret,
+		RSA:     map[string]*rsa.PublicKey{},
+		Ed25519: map[string]ed25519.PublicKey{},
+	}
+
+	if cfg.JWTPublicKey != "" {
+		if err := addPublicKeyPEM(&keys, []byte(cfg.JWTPublicKey)); err != nil {
+			return nil, errors.WithMessage(err, "auth.jwtPublicKey")
+		}
+	}
+	if cfg.JWKSFile != "" {
+		if err := addJWKSFile(&keys, cfg.JWKSFile); err != nil {
+			return nil, errors.WithMessage(err, "auth.jwksFile")
+		}
+	}
+
+	return NewJWTVerifier(keys, cfg.Issuer, cfg.Audience), nil
 }
 
-func (di *DIContainer) GetNoteAdaptor(ctx context.Context) *NoteStore {
-	return NewNoteStore(di.database, di.log)
+func newLogger(cfg config.LogConfig) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse log level")
+	}
+
+	zapConfig := zap.NewProductionConfig()
+	if cfg.Development {
+		zapConfig = zap.NewDevelopmentConfig()
+	}
+	zapConfig.Level = zap.NewAtomicLevelAt(level)
+
+	return zapConfig.Build()
+}
+
+// GetNoteAdaptor возвращает хранилище заметок, выбранное в database.driver.
+func (di *DIContainer) GetNoteAdaptor(ctx context.Context) notes.Store {
+	switch di.config.Database.Driver {
+	case config.DriverMemory:
+		return di.memory
+	case config.DriverSQLite:
+		return NewSQLiteNoteStore(di.database, di.log)
+	default:
+		return NewNoteStore(di.database, di.log)
+	}
+}
+
+// GetUserAdaptor возвращает хранилище пользователей того же драйвера, что и заметки.
+func (di *DIContainer) GetUserAdaptor(ctx context.Context) users.Store {
+	switch di.config.Database.Driver {
+	case config.DriverMemory:
+		return di.memoryUsers
+	case config.DriverSQLite:
+		return NewSQLiteUserStore(di.database, di.log)
+	default:
+		return NewUserStore(di.database, di.log)
+	}
+}
+
+// GetAPITokenAdaptor возвращает хранилище API-токенов того же драйвера, что и заметки.
+func (di *DIContainer) GetAPITokenAdaptor(ctx context.Context) tokens.Store {
+	switch di.config.Database.Driver {
+	case config.DriverMemory:
+		return di.memoryKeys
+	case config.DriverSQLite:
+		return NewSQLiteAPITokenStore(di.database, di.log)
+	default:
+		return NewAPITokenStore(di.database, di.log)
+	}
+}
+
+// GetIdempotencyAdaptor возвращает хранилище ключей идемпотентности того же драйвера, что и заметки.
+func (di *DIContainer) GetIdempotencyAdaptor(ctx context.Context) idempotency.Store {
+	switch di.config.Database.Driver {
+	case config.DriverMemory:
+		return di.memoryIdem
+	case config.DriverSQLite:
+		return NewSQLiteIdempotencyStore(di.database, di.log)
+	default:
+		return NewIdempotencyStore(di.database, di.log)
+	}
+}
+
+// GetTrashPurger возвращает очистку корзины с настройками из trash.
+func (di *DIContainer) GetTrashPurger(ctx context.Context) *notes.Purger {
+	return notes.NewPurger(
+		di.GetNoteAdaptor(ctx),
+		di.config.Trash.Retention.Duration,
+		di.config.Trash.PurgeInterval.Duration,
+		di.log,
+	)
+}
+
+// GetIdempotencyPurger возвращает удаление истёкших ключей идемпотентности.
+func (di *DIContainer) GetIdempotencyPurger(ctx context.Context) *idempotency.Purger {
+	return idempotency.NewPurger(
+		di.GetIdempotencyAdaptor(ctx),
+		di.config.Idempotency.PurgeInterval.Duration,
+		di.log,
+	)
+}
+
+func (di *DIContainer) GetMigrator() (*migrations.Migrator, error) {
+	if di.config.Database.Driver != config.DriverPostgres {
+		return nil, ErrMigrationsUnsupported
+	}
+
+	return migrations.NewMigrator(di.database, di.log), nil
+}
+
+func (di *DIContainer) GetCursorCodec() *notes.CursorCodec {
+	return di.cursors
+}
+
+func (di *DIContainer) GetTokenIssuer() *JWTIssuer {
+	return di.tokens
+}
+
+func (di *DIContainer) GetTokenVerifier() *JWTVerifier {
+	return di.verifier
+}
+
+func (di *DIContainer) GetMarkdownRenderer() *MarkdownRenderer {
+	return di.renderer
 }
 
 func (di *DIContainer) GetLogger() *zap.Logger {
 	return di.log
 }
 
-func (di *DIContainer) Close() {
-	di.database.Close()
+func (di *DIContainer) GetConfig() config.Config {
+	return di.config
+}
+
+func (di *DIContainer) Close() error {
+	if di.database == nil {
+		return nil
+	}
+
+	return errors.Wrap(di.database.Close(), "close database")
 }
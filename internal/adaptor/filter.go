@@ -0,0 +1,397 @@
+package adaptor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+)
+
+// matchFilter проверка notes.Filter для хранилищ, которые фильтруют в Go.
+func matchFilter(n note.Note, filter notes.Filter) bool {
+	if (n.DeletedAt != nil) != filter.Trashed {
+		return false
+	}
+	// Доступ по SharedWith проверяет само хранилище, у заметки нет списка прав.
+	if filter.SharedWith == uuid.Nil && n.OwnerID != filter.OwnerID {
+		return false
+	}
+
+	for _, tag := range filter.TagsAll {
+		if !hasTag(n.Tags, tag) {
+			return false
+		}
+	}
+
+	if len(filter.TagsAny) > 0 {
+		found := false
+		for _, tag := range filter.TagsAny {
+			if hasTag(n.Tags, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, tag := range filter.TagsNone {
+		if hasTag(n.Tags, tag) {
+			return false
+		}
+	}
+
+	if !filter.ModifiedSince.IsZero() && n.UpdatedAt.Before(filter.ModifiedSince) {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !n.CreatedAt.Before(filter.CreatedBefore) {
+		return false
+	}
+
+	for _, flag := range noteFlags(filter) {
+		if flag.want != nil && *flag.want != flag.value(n) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// flagFilter условие фильтра на логическую колонку заметки.
+type flagFilter struct {
+	column string
+	want   *bool
+	value  func(n note.Note) bool
+}
+
+func noteFlags(filter notes.Filter) []flagFilter {
+	return []flagFilter{
+		{"pinned", filter.Pinned, func(n note.Note) bool { return n.Pinned }},
+		{"archived", filter.Archived, func(n note.Note) bool { return n.Archived }},
+		{"favorite", filter.Favorite, func(n note.Note) bool { return n.Favorite }},
+	}
+}
+
+// flagColumn колонка признака заметки.
+func flagColumn(flag notes.Flag) (string, error) {
+	switch flag {
+	case notes.FlagPinned, notes.FlagArchived, notes.FlagFavorite:
+		return string(flag), nil
+	}
+	return "", errors.Errorf("unknown note flag %q", flag)
+}
+
+// flagConditions условия на флаги заметки. Запись без параметров подходит
+// и для boolean postgres, и для целых 0/1 в SQLite.
+func flagConditions(filter notes.Filter) []string {
+	conditions := []string{}
+	for _, flag := range noteFlags(filter) {
+		switch {
+		case flag.want == nil:
+		case *flag.want:
+			conditions = append(conditions, flag.column)
+		default:
+			conditions = append(conditions, "NOT "+flag.column)
+		}
+	}
+
+	return conditions
+}
+
+// pgWhere строит условие WHERE для postgres. Параметры нумеруются после уже переданных в params.
+func pgWhere(filter notes.Filter, params []any) (string, []any) {
+	conditions, params := pgConditions(filter, params)
+	return whereClause(conditions), params
+}
+
+func pgConditions(filter notes.Filter, params []any) ([]string, []any) {
+	conditions := []string{}
+	add := func(format string, value any) {
+		params = append(params, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(params)))
+	}
+
+	if filter.SharedWith != uuid.Nil {
+		add("id IN (SELECT note_id FROM "+ShareTable+" WHERE user_id = $%v::uuid)", filter.SharedWith.String())
+	} else {
+		add("owner_id = $%v::uuid", filter.OwnerID.String())
+	}
+	conditions = append(conditions, trashCondition(filter.Trashed))
+	if filter.NotebookID != uuid.Nil {
+		add(notebookCondition(filter.Recursive, "$%v::uuid"), filter.NotebookID.String())
+	}
+	conditions = append(conditions, flagConditions(filter)...)
+	if len(filter.TagsAll) > 0 {
+		add("tags @> $%v::text[]", pq.Array(filter.TagsAll))
+	}
+	if len(filter.TagsAny) > 0 {
+		add("tags && $%v::text[]", pq.Array(filter.TagsAny))
+	}
+	if len(filter.TagsNone) > 0 {
+		add("NOT (coalesce(tags, '{}') && $%v::text[])", pq.Array(filter.TagsNone))
+	}
+	if !filter.ModifiedSince.IsZero() {
+		add("updated_at >= $%v", filter.ModifiedSince)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		add("created_at < $%v", filter.CreatedBefore)
+	}
+
+	return conditions, params
+}
+
+// trashCondition отбирает заметки из корзины или, наоборот, все остальные.
+func trashCondition(trashed bool) string {
+	if trashed {
+		return "deleted_at IS NOT NULL"
+	}
+	return "deleted_at IS NULL"
+}
+
+// notebookCondition отбирает заметки блокнота, параметр с его ID подставляется
+// вместо placeholder. Recursive добавляет все вложенные блокноты.
+func notebookCondition(recursive bool, placeholder string) string {
+	if !recursive {
+		return "notebook_id = " + placeholder
+	}
+
+	return "notebook_id IN (" + notebookTree(placeholder) + ")"
+}
+
+// notebookTree запрос ID блокнота и всех вложенных в него. UNION, а не
+// UNION ALL, не даёт запросу зациклиться.
+func notebookTree(placeholder string) string {
+	return fmt.Sprintf(
+		`WITH RECURSIVE tree (id) AS (
+			SELECT id FROM %[1]v WHERE id = %[2]v
+			UNION SELECT nb.id FROM %[1]v nb JOIN tree ON nb.parent_id = tree.id
+		) SELECT id FROM tree`,
+		NotebookTable, placeholder,
+	)
+}
+
+// pgKeyset условие "после курсора" в виде сравнения кортежей (ключ, id)
+// внутри группы закреплённых или остальных заметок.
+func pgKeyset(args notes.ListArgs, params []any) (string, []any) {
+	column, op := keysetColumn(args)
+	var value any = args.Keyset.Label
+	if at, ok := keysetTime(args); ok {
+		value = at
+	}
+	params = append(params, args.Keyset.Pinned, value, args.Keyset.ID.String())
+
+	return fmt.Sprintf("(pinned %v $%[2]v OR pinned = $%[2]v AND (%[3]v, id) %[4]v ($%[5]v, $%[6]v::uuid))",
+		keysetPinnedOp(args), len(params)-2, column, op, len(params)-1, len(params)), params
+}
+
+// sqliteWhere строит условие WHERE для SQLite, где теги лежат JSON-массивом.
+func sqliteWhere(filter notes.Filter, params []any) (string, []any) {
+	conditions, params := sqliteConditions(filter, params)
+	return whereClause(conditions), params
+}
+
+func sqliteConditions(filter notes.Filter, params []any) ([]string, []any) {
+	conditions := []string{"owner_id = ?"}
+	if filter.SharedWith != uuid.Nil {
+		params = append(params, filter.SharedWith.String())
+		conditions = []string{"id IN (SELECT note_id FROM " + ShareTable + " WHERE user_id = ?)"}
+	} else {
+		params = append(params, filter.OwnerID.String())
+	}
+	conditions = append(conditions, trashCondition(filter.Trashed))
+	if filter.NotebookID != uuid.Nil {
+		params = append(params, filter.NotebookID.String())
+		conditions = append(conditions, notebookCondition(filter.Recursive, "?"))
+	}
+	conditions = append(conditions, flagConditions(filter)...)
+	hasAny := func(tags []string) string {
+		for _, tag := range tags {
+			params = append(params, tag)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(tags)), ", ")
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(tags) WHERE value IN (%v))", placeholders)
+	}
+
+	for _, tag := range filter.TagsAll {
+		conditions = append(conditions, hasAny([]string{tag}))
+	}
+	if len(filter.TagsAny) > 0 {
+		conditions = append(conditions, hasAny(filter.TagsAny))
+	}
+	if len(filter.TagsNone) > 0 {
+		conditions = append(conditions, "NOT "+hasAny(filter.TagsNone))
+	}
+	if !filter.ModifiedSince.IsZero() {
+		params = append(params, filter.ModifiedSince.UnixNano())
+		conditions = append(conditions, "updated_at >= ?")
+	}
+	if !filter.CreatedBefore.IsZero() {
+		params = append(params, filter.CreatedBefore.UnixNano())
+		conditions = append(conditions, "created_at < ?")
+	}
+
+	return conditions, params
+}
+
+func sqliteKeyset(args notes.ListArgs, params []any) (string, []any) {
+	column, op := keysetColumn(args)
+	var value any = args.Keyset.Label
+	if at, ok := keysetTime(args); ok {
+		value = at.UnixNano()
+	}
+	pinned := 0
+	if args.Keyset.Pinned {
+		pinned = 1
+	}
+	params = append(params, pinned, pinned, value, args.Keyset.ID.String())
+
+	return fmt.Sprintf("(pinned %v ? OR pinned = ? AND (%v, id) %v (?, ?))", keysetPinnedOp(args), column, op), params
+}
+
+// keysetColumn колонка сортировки и оператор сравнения для позиции курсора.
+func keysetColumn(args notes.ListArgs) (column, op string) {
+	column = sortColumn(args.SortBy)
+
+	forward := args.SortDirection != notes.SortDirectionDesc
+	if args.Keyset.Backward {
+		forward = !forward
+	}
+
+	op = "<"
+	if forward {
+		op = ">"
+	}
+
+	return column, op
+}
+
+// keysetPinnedOp оператор сравнения pinned для позиции курсора. Закреплённые
+// заметки идут первыми при любом направлении сортировки.
+func keysetPinnedOp(args notes.ListArgs) string {
+	if args.Keyset.Backward {
+		return ">"
+	}
+	return "<"
+}
+
+// sortColumn колонка, по которой сортируется список.
+func sortColumn(sortBy notes.SortField) string {
+	switch sortBy {
+	case notes.SortFieldDate:
+		return "created_at"
+	case notes.SortFieldUpdated:
+		return "updated_at"
+	}
+	return "label"
+}
+
+// keysetTime значение курсора для сортировки по времени, ok = false для сортировки по метке.
+func keysetTime(args notes.ListArgs) (at time.Time, ok bool) {
+	switch args.SortBy {
+	case notes.SortFieldDate:
+		return args.Keyset.CreatedAt, true
+	case notes.SortFieldUpdated:
+		return args.Keyset.UpdatedAt, true
+	}
+	return time.Time{}, false
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// renameTag заменяет from на to, не допуская дубликатов to.
+func renameTag(tags []string, from, to string) ([]string, bool) {
+	if !hasTag(tags, from) {
+		return tags, false
+	}
+
+	result := []string{}
+	for _, t := range tags {
+		if t == from {
+			t = to
+		}
+		if !hasTag(result, t) {
+			result = append(result, t)
+		}
+	}
+
+	return result, true
+}
+
+func removeTag(tags []string, tag string) ([]string, bool) {
+	if !hasTag(tags, tag) {
+		return tags, false
+	}
+
+	result := []string{}
+	for _, t := range tags {
+		if t != tag {
+			result = append(result, t)
+		}
+	}
+
+	return result, true
+}
+
+// countTags считает теги по заметкам, сортируя по убыванию частоты, затем по имени.
+func countTags(list []note.Note) []note.Tag {
+	counts := map[string]uint{}
+	for _, n := range list {
+		seen := map[string]bool{}
+		for _, tag := range n.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				counts[tag]++
+			}
+		}
+	}
+
+	tags := make([]note.Tag, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, note.Tag{Name: name, Count: count})
+	}
+	sortTags(tags)
+
+	return tags
+}
+
+func sortTags(tags []note.Tag) {
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Name < tags[j].Name
+	})
+}
+
+// sortShares порядок выдачи прав: по времени, затем по ID пользователя.
+func sortShares(list []note.Share) {
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].UserID.String() < list[j].UserID.String()
+	})
+}
@@ -0,0 +1,152 @@
+package adaptor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency"
+	"go.uber.org/zap"
+)
+
+const IdempotencyTable = "idempotency_keys"
+
+const idempotencyColumns = "user_id, key, request_hash, status, headers, body, created_at, expires_at"
+
+type IdempotencyStore struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+func NewIdempotencyStore(db *sql.DB, logger *zap.Logger) *IdempotencyStore {
+	return &IdempotencyStore{
+		db:  db,
+		log: logger,
+	}
+}
+
+// Reserve вставляет запись или заменяет истёкшую одним запросом, чтобы два
+// одновременных запроса с одним ключом не прошли оба.
+func (s *IdempotencyStore) Reserve(ctx context.Context, record idempotency.Record) error {
+	s.log.Debug("reserving idempotency key", zap.String("key", record.Key))
+
+	query := fmt.Sprintf(
+		`INSERT INTO %[1]v (user_id, key, request_hash, created_at, expires_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash, status = NULL, headers = NULL, body = NULL,
+			created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE %[1]v.expires_at <= EXCLUDED.created_at`,
+		IdempotencyTable,
+	)
+
+	result, err := s.db.ExecContext(ctx, query, record.UserID, record.Key, record.RequestHash, record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		return errors.Wrap(err, "reserve idempotency key")
+	}
+
+	return keyReserved(result)
+}
+
+func (s *IdempotencyStore) Get(ctx context.Context, userID uuid.UUID, key string) (idempotency.Record, error) {
+	query := fmt.Sprintf(`SELECT %v FROM %v WHERE user_id = $1 AND key = $2`, idempotencyColumns, IdempotencyTable)
+
+	var (
+		r       idempotency.Record
+		status  sql.NullInt64
+		headers []byte
+		body    []byte
+	)
+	err := s.db.QueryRowContext(ctx, query, userID, key).
+		Scan(&r.UserID, &r.Key, &r.RequestHash, &status, &headers, &body, &r.CreatedAt, &r.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return idempotency.Record{}, idempotency.NotFound
+	}
+	if err != nil {
+		return idempotency.Record{}, errors.Wrap(err, "get idempotency key")
+	}
+
+	r.Response, err = storedResponse(status, headers, body)
+
+	return r, err
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, userID uuid.UUID, key string, response idempotency.Response) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return errors.Wrap(err, "marshal response headers")
+	}
+
+	query := fmt.Sprintf(`UPDATE %v SET status = $3, headers = $4, body = $5 WHERE user_id = $1 AND key = $2`, IdempotencyTable)
+
+	result, err := s.db.ExecContext(ctx, query, userID, key, response.Status, string(headers), response.Body)
+	if err != nil {
+		return errors.Wrap(err, "complete idempotency key")
+	}
+
+	return keyAffected(result)
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	query := fmt.Sprintf(`DELETE FROM %v WHERE user_id = $1 AND key = $2 AND status IS NULL`, IdempotencyTable)
+
+	result, err := s.db.ExecContext(ctx, query, userID, key)
+	if err != nil {
+		return errors.Wrap(err, "release idempotency key")
+	}
+
+	return keyAffected(result)
+}
+
+func (s *IdempotencyStore) PurgeExpired(ctx context.Context, before time.Time) (uint, error) {
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %v WHERE expires_at <= $1`, IdempotencyTable), before)
+	if err != nil {
+		return 0, errors.Wrap(err, "purge idempotency keys")
+	}
+
+	count, err := result.RowsAffected()
+
+	return uint(count), errors.Wrap(err, "rows affected")
+}
+
+// storedResponse собирает ответ из колонок, status NULL - ответа ещё нет.
+func storedResponse(status sql.NullInt64, headers, body []byte) (*idempotency.Response, error) {
+	if !status.Valid {
+		return nil, nil
+	}
+
+	response := &idempotency.Response{Status: int(status.Int64), Body: body}
+	if err := json.Unmarshal(headers, &response.Header); err != nil {
+		return nil, errors.Wrap(err, "unmarshal response headers")
+	}
+
+	return response, nil
+}
+
+// keyReserved переводит пропущенную вставку в ErrKeyExists.
+func keyReserved(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+	if affected == 0 {
+		return idempotency.ErrKeyExists
+	}
+
+	return nil
+}
+
+func keyAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected")
+	}
+	if affected == 0 {
+		return idempotency.NotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package adaptor
+
+import (
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency/storetest"
+	"go.uber.org/zap"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	db := openTestPostgres(t)
+
+	storetest.Run(t, func(t *testing.T, owners ...uuid.UUID) idempotency.Store {
+		resetTables(t, db, owners...)
+		return NewIdempotencyStore(db, zap.NewNop())
+	})
+}
@@ -0,0 +1,118 @@
+package adaptor
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// jwk открытый ключ в формате RFC 7517. Поддерживаются RSA и OKP/Ed25519.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+}
+
+// addPublicKeyPEM добавляет в keys открытый ключ RSA или Ed25519 из PEM без kid.
+func addPublicKeyPEM(keys *JWTKeys, data []byte) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return errors.New("public key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return errors.Wrap(err, "parse public key")
+	}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		keys.RSA[""] = key
+	case ed25519.PublicKey:
+		keys.Ed25519[""] = key
+	default:
+		return errors.Errorf("unsupported public key type %T", key)
+	}
+
+	return nil
+}
+
+// addJWKSFile добавляет в keys ключи подписи из локального файла JWKS.
+// Ключи шифрования (use=enc) и неизвестные типы пропускаются.
+func addJWKSFile(keys *JWTKeys, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "read jwks file")
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return errors.Wrapf(err, "parse jwks file %v", path)
+	}
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch {
+		case k.Kty == "RSA":
+			key, err := k.rsa()
+			if err != nil {
+				return errors.WithMessagef(err, "jwks key %q", k.Kid)
+			}
+			keys.RSA[k.Kid] = key
+		case k.Kty == "OKP" && k.Crv == "Ed25519":
+			key, err := k.ed25519()
+			if err != nil {
+				return errors.WithMessagef(err, "jwks key %q", k.Kid)
+			}
+			keys.Ed25519[k.Kid] = key
+		}
+	}
+
+	return nil
+}
+
+func (k jwk) rsa() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode exponent")
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 || exponent.Int64() < 3 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+func (k jwk) ed25519() (ed25519.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode key")
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid key size")
+	}
+
+	return ed25519.PublicKey(x), nil
+}
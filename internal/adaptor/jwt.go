@@ -0,0 +1,149 @@
+package adaptor
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+)
+
+// jwtLeeway допустимое расхождение часов с внешним издателем токенов.
+const jwtLeeway = 30 * time.Second
+
+// accessClaims поля токена доступа. Области доступа принимаются как строкой
+// scope через пробел (RFC 8693), так и массивом scp.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	Scope string   `json:"scope,omitempty"`
+	Scp   []string `json:"scp,omitempty"`
+}
+
+func (c accessClaims) scopes() []string {
+	return append(strings.Fields(c.Scope), c.Scp...)
+}
+
+// JWTIssuer выдаёт токены доступа HS256, subject - ID пользователя.
+type JWTIssuer struct {
+	secret   []byte
+	ttl      time.Duration
+	issuer   string
+	audience string
+}
+
+func NewJWTIssuer(secret []byte, ttl time.Duration, issuer, audience string) *JWTIssuer {
+	return &JWTIssuer{secret: secret, ttl: ttl, issuer: issuer, audience: audience}
+}
+
+func (i *JWTIssuer) Issue(u user.User) (user.Token, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.issuer,
+			Subject:   u.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Scope: strings.Join(user.DefaultScopes, " "),
+	}
+	if i.audience != "" {
+		claims.Audience = jwt.ClaimStrings{i.audience}
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret)
+	if err != nil {
+		return user.Token{}, errors.Wrap(err, "sign token")
+	}
+
+	return user.Token{
+		AccessToken: signed,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt,
+		Scopes:      user.DefaultScopes,
+	}, nil
+}
+
+// JWTKeys ключи проверки подписи. Пустой ключ отключает соответствующий алгоритм.
+type JWTKeys struct {
+	// Secret ключ HS256, тот же, которым подписывает JWTIssuer.
+	Secret []byte
+	// RSA и Ed25519 ключи по kid из JWKS. Ключ с пустым kid подходит токенам без kid.
+	RSA     map[string]*rsa.PublicKey
+	Ed25519 map[string]ed25519.PublicKey
+}
+
+// JWTVerifier проверяет токены HS256, RS256 и EdDSA.
+type JWTVerifier struct {
+	keys    JWTKeys
+	options []jwt.ParserOption
+}
+
+func NewJWTVerifier(keys JWTKeys, issuer, audience string) *JWTVerifier {
+	methods := []string{}
+	if len(keys.Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(keys.RSA) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(keys.Ed25519) > 0 {
+		methods = append(methods, jwt.SigningMethodEdDSA.Alg())
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	return &JWTVerifier{keys: keys, options: options}
+}
+
+// Verify проверяет подпись, срок действия, издателя и аудиторию и возвращает
+// пользователя с его областями доступа.
+func (v *JWTVerifier) Verify(token string) (user.Principal, error) {
+	claims := accessClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, v.key, v.options...)
+	if err != nil {
+		return user.Principal{}, users.ErrInvalidToken.WithCause(err)
+	}
+
+	id, err := uuid.FromString(claims.Subject)
+	if err != nil {
+		return user.Principal{}, users.ErrInvalidToken.WithCause(errors.Wrap(err, "subject is not a user id"))
+	}
+
+	return user.Principal{UserID: id, Scopes: claims.scopes()}, nil
+}
+
+func (v *JWTVerifier) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.keys.Secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		if key, ok := v.keys.RSA[kid]; ok {
+			return key, nil
+		}
+	case jwt.SigningMethodEdDSA.Alg():
+		if key, ok := v.keys.Ed25519[kid]; ok {
+			return key, nil
+		}
+	}
+
+	return nil, errors.Errorf("no %v key with kid %q", token.Method.Alg(), kid)
+}
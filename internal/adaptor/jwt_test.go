@@ -0,0 +1,174 @@
+package adaptor
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+)
+
+const (
+	testIssuer   = "https://issuer.example"
+	testAudience = "notes"
+)
+
+var (
+	testSecret = []byte("0123456789abcdef0123456789abcdef")
+	testUserID = uuid.FromStringOrNil("0190f1f4-6d0e-7c2c-8f4b-000000000001")
+)
+
+func testClaims() accessClaims {
+	now := time.Now()
+	return accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Subject:   testUserID.String(),
+			Audience:  jwt.ClaimStrings{testAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Scope: user.ScopeNotesRead,
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, claims accessClaims, key any) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	return signed
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519 key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal rsa key: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	all := JWTKeys{
+		Secret:  testSecret,
+		RSA:     map[string]*rsa.PublicKey{"rsa-1": &rsaKey.PublicKey},
+		Ed25519: map[string]ed25519.PublicKey{"ed-1": edPublic},
+	}
+	rsaOnly := JWTKeys{RSA: all.RSA}
+
+	expired := testClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	withinLeeway := testClaims()
+	withinLeeway.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-jwtLeeway / 2))
+	noExpiry := testClaims()
+	noExpiry.ExpiresAt = nil
+	wrongIssuer := testClaims()
+	wrongIssuer.Issuer = "https://other.example"
+	wrongAudience := testClaims()
+	wrongAudience.Audience = jwt.ClaimStrings{"other"}
+	badSubject := testClaims()
+	badSubject.Subject = "not-a-uuid"
+	scp := testClaims()
+	scp.Scope = ""
+	scp.Scp = []string{user.ScopeNotesRead, user.ScopeNotesWrite}
+
+	tests := []struct {
+		name   string
+		keys   JWTKeys
+		token  string
+		scopes []string
+	}{
+		{name: "HS256", keys: all, token: sign(t, jwt.SigningMethodHS256, "", testClaims(), testSecret), scopes: []string{user.ScopeNotesRead}},
+		{name: "RS256", keys: all, token: sign(t, jwt.SigningMethodRS256, "rsa-1", testClaims(), rsaKey), scopes: []string{user.ScopeNotesRead}},
+		{name: "EdDSA", keys: all, token: sign(t, jwt.SigningMethodEdDSA, "ed-1", testClaims(), edPrivate), scopes: []string{user.ScopeNotesRead}},
+		{name: "ScpArray", keys: all, token: sign(t, jwt.SigningMethodHS256, "", scp, testSecret), scopes: scp.Scp},
+		{name: "ExpiredWithinLeeway", keys: all, token: sign(t, jwt.SigningMethodHS256, "", withinLeeway, testSecret), scopes: []string{user.ScopeNotesRead}},
+		{name: "Expired", keys: all, token: sign(t, jwt.SigningMethodHS256, "", expired, testSecret)},
+		{name: "NoExpiry", keys: all, token: sign(t, jwt.SigningMethodHS256, "", noExpiry, testSecret)},
+		{name: "WrongIssuer", keys: all, token: sign(t, jwt.SigningMethodHS256, "", wrongIssuer, testSecret)},
+		{name: "WrongAudience", keys: all, token: sign(t, jwt.SigningMethodHS256, "", wrongAudience, testSecret)},
+		{name: "BadSubject", keys: all, token: sign(t, jwt.SigningMethodHS256, "", badSubject, testSecret)},
+		{name: "WrongSecret", keys: all, token: sign(t, jwt.SigningMethodHS256, "", testClaims(), []byte("another secret of the same size!"))},
+		{name: "WrongRSAKey", keys: all, token: sign(t, jwt.SigningMethodRS256, "rsa-1", testClaims(), otherKey)},
+		{name: "UnknownKid", keys: all, token: sign(t, jwt.SigningMethodRS256, "rsa-2", testClaims(), rsaKey)},
+		{name: "MissingKid", keys: all, token: sign(t, jwt.SigningMethodRS256, "", testClaims(), rsaKey)},
+		// Подпись HS256 открытым ключом RSA: без HS256-секрета алгоритм не
+		// разрешён, с секретом проверяется секретом, а не ключом RSA.
+		{name: "AlgorithmConfusion", keys: rsaOnly, token: sign(t, jwt.SigningMethodHS256, "rsa-1", testClaims(), publicPEM)},
+		{name: "AlgorithmConfusionWithSecret", keys: all, token: sign(t, jwt.SigningMethodHS256, "rsa-1", testClaims(), publicPEM)},
+		{name: "AlgNone", keys: all, token: sign(t, jwt.SigningMethodNone, "", testClaims(), jwt.UnsafeAllowNoneSignatureType)},
+		{name: "NotAToken", keys: all, token: "not.a.token"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := NewJWTVerifier(tt.keys, testIssuer, testAudience).Verify(tt.token)
+			if tt.scopes == nil {
+				if !errors.Is(err, users.ErrInvalidToken) {
+					t.Fatalf("Verify error = %v, want %v", err, users.ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if principal.UserID != testUserID {
+				t.Errorf("UserID = %v, want %v", principal.UserID, testUserID)
+			}
+			if len(principal.Scopes) != len(tt.scopes) {
+				t.Fatalf("Scopes = %q, want %q", principal.Scopes, tt.scopes)
+			}
+			for i := range tt.scopes {
+				if principal.Scopes[i] != tt.scopes[i] {
+					t.Errorf("Scopes = %q, want %q", principal.Scopes, tt.scopes)
+				}
+			}
+		})
+	}
+}
+
+func TestJWTIssuerRoundTrip(t *testing.T) {
+	issued, err := NewJWTIssuer(testSecret, time.Hour, testIssuer, testAudience).Issue(user.User{ID: testUserID})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	principal, err := NewJWTVerifier(JWTKeys{Secret: testSecret}, testIssuer, testAudience).Verify(issued.AccessToken)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if principal.UserID != testUserID {
+		t.Errorf("UserID = %v, want %v", principal.UserID, testUserID)
+	}
+	for _, scope := range user.DefaultScopes {
+		if !principal.HasScope(scope) {
+			t.Errorf("Scopes = %q, want %v", principal.Scopes, scope)
+		}
+	}
+}
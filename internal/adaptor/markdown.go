@@ -0,0 +1,121 @@
+package adaptor
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/pkg/errors"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// MarkdownRenderer разбирает CommonMark с расширениями GFM (таблицы, списки
+// задач, зачёркивание, автоссылки). HTML из текста заметки пропускается
+// goldmark как есть и затем очищается bluemonday, поэтому безопасная
+// разметка сохраняется, а скрипты и обработчики событий удаляются.
+// Безопасен для одновременного использования.
+type MarkdownRenderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+}
+
+func NewMarkdownRenderer() *MarkdownRenderer {
+	policy := bluemonday.UGCPolicy()
+	// Якоря заголовков, на них ссылается оглавление.
+	policy.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	// Флажки списков задач GFM.
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return &MarkdownRenderer{
+		markdown: goldmark.New(
+			goldmark.WithExtensions(extension.GFM),
+			goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+			goldmark.WithRendererOptions(html.WithUnsafe()),
+		),
+		policy: policy,
+	}
+}
+
+// Render возвращает очищенный HTML и заголовки текста по порядку.
+func (r *MarkdownRenderer) Render(markdown string) (string, []note.Heading, error) {
+	source := []byte(markdown)
+	ids := &headingIDs{used: map[string]bool{}}
+	doc := r.markdown.Parser().Parse(text.NewReader(source), parser.WithContext(parser.NewContext(parser.WithIDs(ids))))
+
+	toc := []note.Heading{}
+	err := ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		h := note.Heading{Level: heading.Level, Text: string(heading.Text(source))}
+		if id, ok := heading.AttributeString("id"); ok {
+			if id, ok := id.([]byte); ok {
+				h.ID = string(id)
+			}
+		}
+		toc = append(toc, h)
+
+		return ast.WalkSkipChildren, nil
+	})
+	if err != nil {
+		return "", nil, errors.Wrap(err, "collect headings")
+	}
+
+	var buf bytes.Buffer
+	if err := r.markdown.Renderer().Render(&buf, source, doc); err != nil {
+		return "", nil, errors.Wrap(err, "render markdown")
+	}
+
+	return string(r.policy.SanitizeBytes(buf.Bytes())), toc, nil
+}
+
+// headingIDs якоря заголовков из букв и цифр любого алфавита: стандартный
+// генератор goldmark отбрасывает не-ASCII, и русские заголовки получали бы
+// одинаковые якоря. Повторы получают суффикс -1, -2 и т.д.
+type headingIDs struct {
+	used map[string]bool
+}
+
+func (ids *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	var b strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(string(value)) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			separate = true
+			continue
+		}
+		if separate && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		separate = false
+		b.WriteRune(r)
+	}
+
+	base := b.String()
+	if base == "" {
+		base = "heading"
+	}
+	id := base
+	for i := 1; ids.used[id]; i++ {
+		id = fmt.Sprintf("%v-%v", base, i)
+	}
+	ids.used[id] = true
+
+	return []byte(id)
+}
+
+func (ids *headingIDs) Put(value []byte) {
+	ids.used[string(value)] = true
+}
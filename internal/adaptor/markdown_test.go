@@ -0,0 +1,115 @@
+package adaptor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+)
+
+func TestMarkdownRendererSanitizes(t *testing.T) {
+	tests := []struct {
+		name      string
+		markdown  string
+		forbidden []string
+		want      []string
+	}{
+		{
+			name:      "Script",
+			markdown:  "text\n\n<script>alert(1)</script>\n\nafter",
+			forbidden: []string{"<script", "alert(1)"},
+			want:      []string{"<p>text</p>", "<p>after</p>"},
+		},
+		{
+			name:      "JavascriptLink",
+			markdown:  "[click](javascript:alert(1)) and <a href=\"javascript:alert(2)\">raw</a>",
+			forbidden: []string{"javascript:"},
+			want:      []string{"click", "raw"},
+		},
+		{
+			name:      "EventHandler",
+			markdown:  "<img src=\"https://example.com/a.png\" onerror=\"alert(1)\">\n\n<p onclick=\"alert(2)\">p</p>",
+			forbidden: []string{"onerror", "onclick", "alert"},
+			want:      []string{`<img src="https://example.com/a.png"`},
+		},
+		{
+			name:      "Iframe",
+			markdown:  "<iframe src=\"https://evil.example\"></iframe>\n\nok",
+			forbidden: []string{"<iframe", "evil.example"},
+			want:      []string{"<p>ok</p>"},
+		},
+		{
+			name:      "NonCheckboxInput",
+			markdown:  "<input type=\"text\" autofocus onfocus=\"alert(1)\">",
+			forbidden: []string{`type="text"`, "onfocus", "autofocus"},
+		},
+		{
+			name:     "SafeMarkup",
+			markdown: "**bold** and [link](https://example.com)\n\n| a | b |\n|---|---|\n| 1 | 2 |",
+			want:     []string{"<strong>bold</strong>", `<a href="https://example.com" rel="nofollow">link</a>`, "<table>", "<td>1</td>"},
+		},
+	}
+
+	renderer := NewMarkdownRenderer()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			html, _, err := renderer.Render(tt.markdown)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			for _, s := range tt.forbidden {
+				if strings.Contains(html, s) {
+					t.Errorf("Render = %q, contains %q", html, s)
+				}
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(html, s) {
+					t.Errorf("Render = %q, want %q", html, s)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownRendererTaskList(t *testing.T) {
+	html, _, err := NewMarkdownRenderer().Render("- [x] done\n- [ ] todo")
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	for _, want := range []string{
+		`<input checked="" disabled="" type="checkbox"> done`,
+		`<input disabled="" type="checkbox"> todo`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Render = %q, want %q", html, want)
+		}
+	}
+}
+
+func TestMarkdownRendererHeadings(t *testing.T) {
+	html, toc, err := NewMarkdownRenderer().Render("# Заметки\n\n## Заметки\n\n## Заметки\n\n### Hello, World!\n\n## !!!")
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	want := []note.Heading{
+		{Level: 1, ID: "заметки", Text: "Заметки"},
+		{Level: 2, ID: "заметки-1", Text: "Заметки"},
+		{Level: 2, ID: "заметки-2", Text: "Заметки"},
+		{Level: 3, ID: "hello-world", Text: "Hello, World!"},
+		{Level: 2, ID: "heading", Text: "!!!"},
+	}
+	if len(toc) != len(want) {
+		t.Fatalf("TOC = %+v, want %+v", toc, want)
+	}
+	for i := range want {
+		if toc[i] != want[i] {
+			t.Errorf("TOC[%v] = %+v, want %+v", i, toc[i], want[i])
+		}
+		if anchor := `id="` + want[i].ID + `"`; !strings.Contains(html, anchor) {
+			t.Errorf("Render = %q, want %q", html, anchor)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package adaptor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+// MemoryAPITokenStore хранит API-токены в памяти процесса вместе с MemoryUserStore.
+type MemoryAPITokenStore struct {
+	mu     sync.RWMutex
+	tokens map[uuid.UUID]user.APIToken
+	log    *zap.Logger
+}
+
+func NewMemoryAPITokenStore(logger *zap.Logger) *MemoryAPITokenStore {
+	return &MemoryAPITokenStore{
+		tokens: map[uuid.UUID]user.APIToken{},
+		log:    logger,
+	}
+}
+
+func (s *MemoryAPITokenStore) Create(ctx context.Context, t user.APIToken) error {
+	s.log.Debug("saving api token", zap.Any("tokenID", t.ID))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[t.ID] = copyAPIToken(t)
+
+	return nil
+}
+
+func (s *MemoryAPITokenStore) List(ctx context.Context, userID uuid.UUID) ([]user.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := []user.APIToken{}
+	for _, t := range s.tokens {
+		if t.UserID == userID {
+			list = append(list, copyAPIToken(t))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].ID.String() > list[j].ID.String()
+	})
+
+	return list, nil
+}
+
+func (s *MemoryAPITokenStore) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	s.log.Debug("deleting api token", zap.Any("tokenID", id))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[id]
+	if !ok || t.UserID != userID {
+		return tokens.NotFound
+	}
+	delete(s.tokens, id)
+
+	return nil
+}
+
+func (s *MemoryAPITokenStore) GetByHash(ctx context.Context, hash []byte) (user.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, t := range s.tokens {
+		if string(t.Hash) == string(hash) {
+			return copyAPIToken(t), nil
+		}
+	}
+
+	return user.APIToken{}, tokens.NotFound
+}
+
+func (s *MemoryAPITokenStore) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[id]
+	if !ok {
+		return tokens.NotFound
+	}
+	t.LastUsedAt = &at
+	s.tokens[id] = t
+
+	return nil
+}
+
+func copyAPIToken(t user.APIToken) user.APIToken {
+	t.Scopes = copyTags(t.Scopes)
+	return t
+}
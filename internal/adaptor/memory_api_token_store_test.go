@@ -0,0 +1,16 @@
+package adaptor
+
+import (
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens/storetest"
+	"go.uber.org/zap"
+)
+
+func TestMemoryAPITokenStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, owners ...uuid.UUID) tokens.Store {
+		return NewMemoryAPITokenStore(zap.NewNop())
+	})
+}
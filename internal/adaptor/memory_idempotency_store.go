@@ -0,0 +1,119 @@
+package adaptor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency"
+	"go.uber.org/zap"
+)
+
+type idempotencyKey struct {
+	userID uuid.UUID
+	key    string
+}
+
+// MemoryIdempotencyStore хранит ключи идемпотентности в памяти процесса.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[idempotencyKey]idempotency.Record
+	log     *zap.Logger
+}
+
+func NewMemoryIdempotencyStore(logger *zap.Logger) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: map[idempotencyKey]idempotency.Record{},
+		log:     logger,
+	}
+}
+
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, record idempotency.Record) error {
+	s.log.Debug("reserving idempotency key", zap.String("key", record.Key))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKey{userID: record.UserID, key: record.Key}
+	if current, ok := s.records[id]; ok && current.ExpiresAt.After(record.CreatedAt) {
+		return idempotency.ErrKeyExists
+	}
+	record.RequestHash = append([]byte{}, record.RequestHash...)
+	record.Response = nil
+	s.records[id] = record
+
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Get(ctx context.Context, userID uuid.UUID, key string) (idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[idempotencyKey{userID: userID, key: key}]
+	if !ok {
+		return idempotency.Record{}, idempotency.NotFound
+	}
+	if record.Response != nil {
+		record.Response = copyResponse(*record.Response)
+	}
+
+	return record, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, userID uuid.UUID, key string, response idempotency.Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKey{userID: userID, key: key}
+	record, ok := s.records[id]
+	if !ok {
+		return idempotency.NotFound
+	}
+	record.Response = copyResponse(response)
+	s.records[id] = record
+
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKey{userID: userID, key: key}
+	record, ok := s.records[id]
+	if !ok || record.Response != nil {
+		return idempotency.NotFound
+	}
+	delete(s.records, id)
+
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) PurgeExpired(ctx context.Context, before time.Time) (uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count uint
+	for id, record := range s.records {
+		if !record.ExpiresAt.After(before) {
+			delete(s.records, id)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func copyResponse(response idempotency.Response) *idempotency.Response {
+	header := make(map[string]string, len(response.Header))
+	for name, value := range response.Header {
+		header[name] = value
+	}
+
+	return &idempotency.Response{
+		Status: response.Status,
+		Header: header,
+		Body:   append([]byte{}, response.Body...),
+	}
+}
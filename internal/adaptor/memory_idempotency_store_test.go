@@ -0,0 +1,16 @@
+package adaptor
+
+import (
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency/storetest"
+	"go.uber.org/zap"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, owners ...uuid.UUID) idempotency.Store {
+		return NewMemoryIdempotencyStore(zap.NewNop())
+	})
+}
@@ -0,0 +1,891 @@
+package adaptor
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+// MemoryNoteStore хранит заметки в памяти процесса. Подходит для локального
+// запуска и тестов, данные пропадают при перезапуске.
+type MemoryNoteStore struct {
+	mu    sync.RWMutex
+	notes map[uuid.UUID]note.Note
+	// shares права на заметки: ID заметки -> ID пользователя -> право.
+	shares map[uuid.UUID]map[uuid.UUID]note.Share
+	links  map[uuid.UUID]note.Link
+	// revisions история заметки по возрастанию номера ревизии.
+	revisions map[uuid.UUID][]note.Revision
+	notebooks map[uuid.UUID]note.Notebook
+	log       *zap.Logger
+}
+
+func NewMemoryNoteStore(logger *zap.Logger) *MemoryNoteStore {
+	return &MemoryNoteStore{
+		notes:     map[uuid.UUID]note.Note{},
+		shares:    map[uuid.UUID]map[uuid.UUID]note.Share{},
+		links:     map[uuid.UUID]note.Link{},
+		revisions: map[uuid.UUID][]note.Revision{},
+		notebooks: map[uuid.UUID]note.Notebook{},
+		log:       logger,
+	}
+}
+
+func (s *MemoryNoteStore) Create(ctx context.Context, n note.Note) error {
+	s.log.Debug("saving note", zap.Any("note", n))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createNote(n)
+}
+
+// createNote, patchNote и deleteNote вызываются под блокировкой записи и
+// при ошибке ничего не меняют.
+func (s *MemoryNoteStore) createNote(n note.Note) error {
+	if _, ok := s.notes[n.ID]; ok {
+		return notes.ErrAlreadyExists
+	}
+	n.Version = 1
+	n.UpdatedAt = n.CreatedAt
+	s.notes[n.ID] = copyNote(n)
+	s.addRevision(n, n.OwnerID, n.CreatedAt)
+
+	return nil
+}
+
+func (s *MemoryNoteStore) Update(ctx context.Context, args notes.UpdateArgs) error {
+	return s.Patch(ctx, args.Patch())
+}
+
+func (s *MemoryNoteStore) Patch(ctx context.Context, args notes.PatchArgs) error {
+	s.log.Debug("updating note", zap.Any("args", args))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.patchNote(args)
+}
+
+func (s *MemoryNoteStore) patchNote(args notes.PatchArgs) error {
+	n, ok := s.live(args.ID)
+	if !ok {
+		return notes.NotFound
+	}
+	if args.Version != 0 && args.Version != n.Version {
+		return notes.ErrVersionMismatch
+	}
+
+	n.Version++
+	n.UpdatedAt = args.UpdatedAt
+	if args.Label != nil {
+		n.Label = *args.Label
+	}
+	if args.Body != nil {
+		n.Body = *args.Body
+	}
+	if args.Tags != nil {
+		n.Tags = copyTags(*args.Tags)
+	}
+	s.notes[args.ID] = n
+	s.addRevision(n, args.UserID, args.UpdatedAt)
+
+	return nil
+}
+
+// addRevision вызывается под блокировкой записи.
+func (s *MemoryNoteStore) addRevision(n note.Note, authorID uuid.UUID, at time.Time) {
+	s.revisions[n.ID] = append(s.revisions[n.ID], note.Revision{
+		NoteID:    n.ID,
+		Rev:       uint(len(s.revisions[n.ID]) + 1),
+		Label:     n.Label,
+		Body:      n.Body,
+		Tags:      copyTags(n.Tags),
+		AuthorID:  authorID,
+		CreatedAt: at,
+	})
+}
+
+func (s *MemoryNoteStore) Delete(ctx context.Context, ownerID uuid.UUID, targets []notes.DeleteTarget, deletedAt time.Time) error {
+	s.log.Debug("deleting notes", zap.Any("targets", targets))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Версии проверяются до изменений, чтобы при ошибке ничего не удалить.
+	for _, target := range targets {
+		n, ok := s.live(target.ID)
+		if ok && n.OwnerID == ownerID && target.Version != 0 && target.Version != n.Version {
+			return notes.ErrVersionMismatch
+		}
+	}
+	for _, target := range targets {
+		if err := s.deleteNote(ownerID, target.ID, 0, deletedAt); err != nil && !errors.Is(err, notes.NotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryNoteStore) DeleteOne(ctx context.Context, ownerID, id uuid.UUID, version uint, deletedAt time.Time) error {
+	s.log.Debug("deleting note", zap.Any("noteID", id), zap.Uint("version", version))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.deleteNote(ownerID, id, version, deletedAt)
+}
+
+func (s *MemoryNoteStore) deleteNote(ownerID, id uuid.UUID, version uint, deletedAt time.Time) error {
+	n, ok := s.live(id)
+	if !ok || n.OwnerID != ownerID {
+		return notes.NotFound
+	}
+	if version != 0 && version != n.Version {
+		return notes.ErrVersionMismatch
+	}
+	n.DeletedAt = &deletedAt
+	s.notes[id] = n
+
+	return nil
+}
+
+// Batch выполняет изменения под одной блокировкой. Без continueOnError
+// удавшиеся изменения откатываются по сохранённым прежним состояниям.
+func (s *MemoryNoteStore) Batch(ctx context.Context, writes []notes.BatchWrite, continueOnError bool) ([]error, error) {
+	s.log.Debug("applying batch", zap.Int("count", len(writes)), zap.Bool("continueOnError", continueOnError))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type undo struct {
+		id        uuid.UUID
+		note      note.Note
+		existed   bool
+		revisions int
+	}
+	undos := []undo{}
+	rollback := func() {
+		for i := len(undos) - 1; i >= 0; i-- {
+			u := undos[i]
+			if u.existed {
+				s.notes[u.id] = u.note
+			} else {
+				delete(s.notes, u.id)
+			}
+			if u.revisions > 0 {
+				s.revisions[u.id] = s.revisions[u.id][:u.revisions]
+			} else {
+				delete(s.revisions, u.id)
+			}
+		}
+	}
+
+	errs := make([]error, len(writes))
+	for i, write := range writes {
+		var id uuid.UUID
+		var apply func() error
+		switch {
+		case write.Create != nil:
+			id, apply = write.Create.ID, func() error { return s.createNote(*write.Create) }
+		case write.Patch != nil:
+			id, apply = write.Patch.ID, func() error { return s.patchNote(*write.Patch) }
+		case write.Delete != nil:
+			d := write.Delete
+			id, apply = d.ID, func() error { return s.deleteNote(d.OwnerID, d.ID, d.Version, d.DeletedAt) }
+		default:
+			rollback()
+			return errs, errors.New("empty batch write")
+		}
+
+		previous, existed := s.notes[id]
+		undos = append(undos, undo{id: id, note: previous, existed: existed, revisions: len(s.revisions[id])})
+
+		if errs[i] = apply(); errs[i] != nil && !continueOnError {
+			rollback()
+			return errs, nil
+		}
+	}
+
+	return errs, nil
+}
+
+func (s *MemoryNoteStore) Restore(ctx context.Context, ownerID, id uuid.UUID) error {
+	s.log.Debug("restoring note", zap.Any("noteID", id))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt == nil {
+		return notes.NotFound
+	}
+	n.DeletedAt = nil
+	s.notes[id] = n
+
+	return nil
+}
+
+func (s *MemoryNoteStore) Purge(ctx context.Context, ownerID, id uuid.UUID) error {
+	s.log.Debug("purging note", zap.Any("noteID", id))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt == nil {
+		return notes.NotFound
+	}
+	s.remove(id)
+
+	return nil
+}
+
+func (s *MemoryNoteStore) PurgeDeleted(ctx context.Context, before time.Time) (uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count uint
+	for id, n := range s.notes {
+		if n.DeletedAt != nil && n.DeletedAt.Before(before) {
+			s.remove(id)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// live заметка, если она есть и не в корзине. Вызывается под блокировкой.
+func (s *MemoryNoteStore) live(id uuid.UUID) (note.Note, bool) {
+	n, ok := s.notes[id]
+	if !ok || n.DeletedAt != nil {
+		return note.Note{}, false
+	}
+
+	return n, true
+}
+
+// remove удаляет заметку со всеми связанными данными, вызывается под блокировкой записи.
+func (s *MemoryNoteStore) remove(id uuid.UUID) {
+	delete(s.notes, id)
+	delete(s.shares, id)
+	delete(s.links, id)
+	delete(s.revisions, id)
+}
+
+func (s *MemoryNoteStore) GetByID(ctx context.Context, id uuid.UUID) (note.Note, error) {
+	s.log.Debug("getting note by ID", zap.Any("noteID", id))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, ok := s.live(id)
+	if !ok {
+		return note.Note{}, notes.NotFound
+	}
+
+	return copyNote(n), nil
+}
+
+func (s *MemoryNoteStore) Query(ctx context.Context, args notes.ListArgs) ([]note.Note, error) {
+	s.log.Debug("getting notes with pagination and order", zap.Any("args", args))
+
+	list := s.filtered(args.Filter)
+	sort.SliceStable(list, func(i, j int) bool {
+		return compareNotes(list[i], list[j], args) < 0
+	})
+
+	if args.Keyset != nil {
+		list = afterKeyset(list, args)
+	}
+
+	list = paginate(list, args.Offset, args.Limit)
+	if len(list) == 0 {
+		return []note.Note{}, notes.NotFound
+	}
+
+	return list, nil
+}
+
+func (s *MemoryNoteStore) Count(ctx context.Context, filter notes.Filter) (uint, error) {
+	return uint(len(s.filtered(filter))), nil
+}
+
+func (s *MemoryNoteStore) ListTags(ctx context.Context, ownerID uuid.UUID) ([]note.Tag, error) {
+	return countTags(s.filtered(notes.Filter{OwnerID: ownerID})), nil
+}
+
+func (s *MemoryNoteStore) RenameTag(ctx context.Context, ownerID uuid.UUID, from, to string) (uint, error) {
+	s.log.Debug("renaming tag", zap.String("from", from), zap.String("to", to))
+
+	return s.rewriteTags(ownerID, func(tags []string) ([]string, bool) {
+		return renameTag(tags, from, to)
+	}), nil
+}
+
+func (s *MemoryNoteStore) DeleteTag(ctx context.Context, ownerID uuid.UUID, tag string) (uint, error) {
+	s.log.Debug("deleting tag", zap.String("tag", tag))
+
+	return s.rewriteTags(ownerID, func(tags []string) ([]string, bool) {
+		return removeTag(tags, tag)
+	}), nil
+}
+
+func (s *MemoryNoteStore) rewriteTags(ownerID uuid.UUID, rewrite func(tags []string) ([]string, bool)) uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count uint
+	now := time.Now()
+	for id, n := range s.notes {
+		if n.OwnerID != ownerID || n.DeletedAt != nil {
+			continue
+		}
+		tags, changed := rewrite(n.Tags)
+		if !changed {
+			continue
+		}
+		n.Tags = tags
+		n.UpdatedAt = now
+		n.Version++
+		s.notes[id] = n
+		s.addRevision(n, ownerID, now)
+		count++
+	}
+
+	return count
+}
+
+// compareNotes порядок заметок в списке с учётом направления сортировки, id - второй ключ.
+// Закреплённые заметки идут первыми при любом направлении.
+func compareNotes(a, b note.Note, args notes.ListArgs) int {
+	if a.Pinned != b.Pinned {
+		if a.Pinned {
+			return -1
+		}
+		return 1
+	}
+
+	var cmp int
+	switch args.SortBy {
+	case notes.SortFieldDate:
+		cmp = compareTime(a.CreatedAt, b.CreatedAt)
+	case notes.SortFieldUpdated:
+		cmp = compareTime(a.UpdatedAt, b.UpdatedAt)
+	default:
+		cmp = strings.Compare(a.Label, b.Label)
+	}
+	if cmp == 0 {
+		cmp = strings.Compare(a.ID.String(), b.ID.String())
+	}
+	if args.SortDirection == notes.SortDirectionDesc {
+		cmp = -cmp
+	}
+
+	return cmp
+}
+
+// afterKeyset оставляет заметки после позиции курсора, а для Backward - перед ней
+// в обратном порядке, как их вернул бы SQL-запрос с перевёрнутой сортировкой.
+func afterKeyset(sorted []note.Note, args notes.ListArgs) []note.Note {
+	position := note.Note{
+		ID:        args.Keyset.ID,
+		Label:     args.Keyset.Label,
+		CreatedAt: args.Keyset.CreatedAt,
+		UpdatedAt: args.Keyset.UpdatedAt,
+		Pinned:    args.Keyset.Pinned,
+	}
+
+	result := []note.Note{}
+	if !args.Keyset.Backward {
+		for _, n := range sorted {
+			if compareNotes(n, position, args) > 0 {
+				result = append(result, n)
+			}
+		}
+		return result
+	}
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if compareNotes(sorted[i], position, args) < 0 {
+			result = append(result, sorted[i])
+		}
+	}
+
+	return result
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func paginate(list []note.Note, offset, limit uint) []note.Note {
+	if offset >= uint(len(list)) {
+		return []note.Note{}
+	}
+	list = list[offset:]
+
+	if limit > 0 && limit < uint(len(list)) {
+		list = list[:limit]
+	}
+
+	return list
+}
+
+func copyNote(n note.Note) note.Note {
+	n.Tags = copyTags(n.Tags)
+	n.NotebookID = copyID(n.NotebookID)
+	if n.DeletedAt != nil {
+		deletedAt := *n.DeletedAt
+		n.DeletedAt = &deletedAt
+	}
+	return n
+}
+
+func copyID(id *uuid.UUID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+
+	result := *id
+	return &result
+}
+
+func copyTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	result := make([]string, len(tags))
+	copy(result, tags)
+
+	return result
+}
+
+func (s *MemoryNoteStore) Search(ctx context.Context, args notes.SearchArgs) ([]note.FoundNote, error) {
+	s.log.Debug("searching notes", zap.Any("args", args))
+
+	list := s.filtered(notes.Filter{OwnerID: args.OwnerID})
+
+	return paginateFound(searchNotes(list, args), args.Offset, args.Limit), nil
+}
+
+func (s *MemoryNoteStore) SearchCount(ctx context.Context, args notes.SearchArgs) (uint, error) {
+	list := s.filtered(notes.Filter{OwnerID: args.OwnerID})
+
+	return uint(len(searchNotes(list, args))), nil
+}
+
+func (s *MemoryNoteStore) Access(ctx context.Context, userID, noteID uuid.UUID) (note.Permission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, ok := s.live(noteID)
+	if !ok {
+		return "", notes.NotFound
+	}
+	if n.OwnerID == userID {
+		return note.PermissionOwner, nil
+	}
+	if share, ok := s.shares[noteID][userID]; ok {
+		return share.Permission, nil
+	}
+
+	return "", notes.NotFound
+}
+
+func (s *MemoryNoteStore) Share(ctx context.Context, share note.Share) error {
+	s.log.Debug("sharing note", zap.Any("share", share))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.live(share.NoteID); !ok {
+		return notes.NotFound
+	}
+	if s.shares[share.NoteID] == nil {
+		s.shares[share.NoteID] = map[uuid.UUID]note.Share{}
+	}
+	if existing, ok := s.shares[share.NoteID][share.UserID]; ok {
+		share.CreatedAt = existing.CreatedAt
+	}
+	s.shares[share.NoteID][share.UserID] = share
+
+	return nil
+}
+
+func (s *MemoryNoteStore) Unshare(ctx context.Context, noteID, userID uuid.UUID) error {
+	s.log.Debug("unsharing note", zap.Any("noteID", noteID), zap.Any("userID", userID))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.shares[noteID][userID]; !ok {
+		return notes.ErrShareNotFound
+	}
+	delete(s.shares[noteID], userID)
+
+	return nil
+}
+
+func (s *MemoryNoteStore) ListShares(ctx context.Context, noteID uuid.UUID) ([]note.Share, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := []note.Share{}
+	for _, share := range s.shares[noteID] {
+		list = append(list, share)
+	}
+	sortShares(list)
+
+	return list, nil
+}
+
+func (s *MemoryNoteStore) SaveLink(ctx context.Context, link note.Link) error {
+	s.log.Debug("saving link", zap.Any("noteID", link.NoteID))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.live(link.NoteID); !ok {
+		return notes.NotFound
+	}
+	s.links[link.NoteID] = link
+
+	return nil
+}
+
+func (s *MemoryNoteStore) GetLink(ctx context.Context, noteID uuid.UUID) (note.Link, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	link, ok := s.links[noteID]
+	if !ok {
+		return note.Link{}, notes.ErrLinkNotFound
+	}
+	link.OwnerID = s.notes[noteID].OwnerID
+
+	return link, nil
+}
+
+func (s *MemoryNoteStore) GetLinkByToken(ctx context.Context, token string) (note.Link, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, link := range s.links {
+		if link.Token != token {
+			continue
+		}
+		n, ok := s.live(link.NoteID)
+		if !ok {
+			break
+		}
+		link.OwnerID = n.OwnerID
+		return link, nil
+	}
+
+	return note.Link{}, notes.ErrLinkNotFound
+}
+
+func (s *MemoryNoteStore) DeleteLink(ctx context.Context, noteID uuid.UUID) error {
+	s.log.Debug("deleting link", zap.Any("noteID", noteID))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.links[noteID]; !ok {
+		return notes.ErrLinkNotFound
+	}
+	delete(s.links, noteID)
+
+	return nil
+}
+
+func (s *MemoryNoteStore) ListRevisions(ctx context.Context, noteID uuid.UUID) ([]note.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := s.revisions[noteID]
+	list := make([]note.Revision, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		revision := history[i]
+		revision.Tags = copyTags(revision.Tags)
+		list = append(list, revision)
+	}
+
+	return list, nil
+}
+
+func (s *MemoryNoteStore) GetRevision(ctx context.Context, noteID uuid.UUID, rev uint) (note.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := s.revisions[noteID]
+	if rev == 0 || rev > uint(len(history)) {
+		return note.Revision{}, notes.ErrRevisionNotFound
+	}
+	revision := history[rev-1]
+	revision.Tags = copyTags(revision.Tags)
+
+	return revision, nil
+}
+
+func (s *MemoryNoteStore) filtered(filter notes.Filter) []note.Note {
+	var inNotebooks map[uuid.UUID]bool
+	if filter.NotebookID != uuid.Nil {
+		inNotebooks = s.notebookScope(filter.NotebookID, filter.Recursive)
+	}
+
+	list := []note.Note{}
+	for _, n := range s.all() {
+		if filter.SharedWith != uuid.Nil && !s.sharedWith(n.ID, filter.SharedWith) {
+			continue
+		}
+		if inNotebooks != nil && (n.NotebookID == nil || !inNotebooks[*n.NotebookID]) {
+			continue
+		}
+		if matchFilter(n, filter) {
+			list = append(list, n)
+		}
+	}
+
+	return list
+}
+
+func (s *MemoryNoteStore) sharedWith(noteID, userID uuid.UUID) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.shares[noteID][userID]
+	return ok
+}
+
+func (s *MemoryNoteStore) all() []note.Note {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]note.Note, 0, len(s.notes))
+	for _, n := range s.notes {
+		list = append(list, copyNote(n))
+	}
+
+	return list
+}
+
+func (s *MemoryNoteStore) CreateNotebook(ctx context.Context, notebook note.Notebook) error {
+	s.log.Debug("saving notebook", zap.Any("notebook", notebook))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notebook.ParentID = copyID(notebook.ParentID)
+	s.notebooks[notebook.ID] = notebook
+
+	return nil
+}
+
+func (s *MemoryNoteStore) GetNotebook(ctx context.Context, ownerID, id uuid.UUID) (note.Notebook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	notebook, ok := s.notebooks[id]
+	if !ok || notebook.OwnerID != ownerID {
+		return note.Notebook{}, notes.ErrNotebookNotFound
+	}
+	notebook.ParentID = copyID(notebook.ParentID)
+
+	return notebook, nil
+}
+
+func (s *MemoryNoteStore) ListNotebooks(ctx context.Context, ownerID uuid.UUID) ([]note.Notebook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := []note.Notebook{}
+	for _, notebook := range s.notebooks {
+		if notebook.OwnerID == ownerID {
+			notebook.ParentID = copyID(notebook.ParentID)
+			list = append(list, notebook)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].ID.String() < list[j].ID.String()
+	})
+
+	return list, nil
+}
+
+func (s *MemoryNoteStore) UpdateNotebook(ctx context.Context, notebook note.Notebook) error {
+	s.log.Debug("updating notebook", zap.Any("notebook", notebook))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.notebooks[notebook.ID]
+	if !ok || current.OwnerID != notebook.OwnerID {
+		return notes.ErrNotebookNotFound
+	}
+	current.Name = notebook.Name
+	current.ParentID = copyID(notebook.ParentID)
+	current.UpdatedAt = notebook.UpdatedAt
+	s.notebooks[notebook.ID] = current
+
+	return nil
+}
+
+func (s *MemoryNoteStore) DeleteNotebook(ctx context.Context, ownerID, id uuid.UUID, policy notes.NotebookDeletePolicy, at time.Time) error {
+	s.log.Debug("deleting notebook", zap.Any("notebookID", id), zap.String("policy", string(policy)))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notebook, ok := s.notebooks[id]
+	if !ok || notebook.OwnerID != ownerID {
+		return notes.ErrNotebookNotFound
+	}
+
+	scope := s.notebookScopeLocked(id, policy == notes.NotebookDeleteTrash)
+	for noteID, n := range s.notes {
+		if n.NotebookID == nil || !scope[*n.NotebookID] {
+			continue
+		}
+		switch {
+		case n.DeletedAt != nil:
+			n.NotebookID = nil
+		case policy == notes.NotebookDeleteTrash:
+			n.NotebookID = nil
+			n.DeletedAt = &at
+		default:
+			n.NotebookID = copyID(notebook.ParentID)
+			n.UpdatedAt = at
+			n.Version++
+		}
+		s.notes[noteID] = n
+	}
+
+	for childID, child := range s.notebooks {
+		if child.ParentID != nil && *child.ParentID == id && !scope[childID] {
+			child.ParentID = copyID(notebook.ParentID)
+			child.UpdatedAt = at
+			s.notebooks[childID] = child
+		}
+	}
+	for notebookID := range scope {
+		delete(s.notebooks, notebookID)
+	}
+
+	return nil
+}
+
+func (s *MemoryNoteStore) MoveNotes(ctx context.Context, ownerID uuid.UUID, ids []uuid.UUID, notebookID uuid.UUID, at time.Time) (uint, error) {
+	s.log.Debug("moving notes", zap.Any("note ids", ids), zap.Any("notebookID", notebookID))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var target *uuid.UUID
+	if notebookID != uuid.Nil {
+		if _, ok := s.notebooks[notebookID]; !ok {
+			return 0, notes.ErrNotebookNotFound
+		}
+		target = &notebookID
+	}
+
+	var count uint
+	for _, id := range ids {
+		n, ok := s.live(id)
+		if !ok || n.OwnerID != ownerID || sameNotebook(n.NotebookID, target) {
+			continue
+		}
+		n.NotebookID = copyID(target)
+		n.UpdatedAt = at
+		n.Version++
+		s.notes[id] = n
+		count++
+	}
+
+	return count, nil
+}
+
+func (s *MemoryNoteStore) SetFlag(ctx context.Context, id uuid.UUID, flag notes.Flag, value bool, version uint, at time.Time) error {
+	s.log.Debug("setting note flag", zap.Any("noteID", id), zap.String("flag", string(flag)), zap.Bool("value", value))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.live(id)
+	if !ok {
+		return notes.NotFound
+	}
+	if version != 0 && version != n.Version {
+		return notes.ErrVersionMismatch
+	}
+
+	switch flag {
+	case notes.FlagPinned:
+		n.Pinned = value
+	case notes.FlagArchived:
+		n.Archived = value
+	case notes.FlagFavorite:
+		n.Favorite = value
+	default:
+		_, err := flagColumn(flag)
+		return err
+	}
+	n.UpdatedAt = at
+	n.Version++
+	s.notes[id] = n
+
+	return nil
+}
+
+func sameNotebook(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func (s *MemoryNoteStore) notebookScope(id uuid.UUID, recursive bool) map[uuid.UUID]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.notebookScopeLocked(id, recursive)
+}
+
+// notebookScopeLocked блокнот id и, если recursive, все вложенные в него.
+// Вызывается под блокировкой.
+func (s *MemoryNoteStore) notebookScopeLocked(id uuid.UUID, recursive bool) map[uuid.UUID]bool {
+	scope := map[uuid.UUID]bool{id: true}
+	for changed := recursive; changed; {
+		changed = false
+		for childID, child := range s.notebooks {
+			if child.ParentID != nil && scope[*child.ParentID] && !scope[childID] {
+				scope[childID] = true
+				changed = true
+			}
+		}
+	}
+
+	return scope
+}
@@ -0,0 +1,16 @@
+package adaptor
+
+import (
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes/storetest"
+	"go.uber.org/zap"
+)
+
+func TestMemoryNoteStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, owners ...uuid.UUID) notes.Store {
+		return NewMemoryNoteStore(zap.NewNop())
+	})
+}
@@ -0,0 +1,66 @@
+package adaptor
+
+import (
+	"context"
+	"sync"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+// MemoryUserStore хранит пользователей в памяти процесса вместе с MemoryNoteStore.
+type MemoryUserStore struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]user.User
+	log   *zap.Logger
+}
+
+func NewMemoryUserStore(logger *zap.Logger) *MemoryUserStore {
+	return &MemoryUserStore{
+		users: map[uuid.UUID]user.User{},
+		log:   logger,
+	}
+}
+
+func (s *MemoryUserStore) Create(ctx context.Context, u user.User) error {
+	s.log.Debug("saving user", zap.Any("userID", u.ID))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.users {
+		if existing.Email == u.Email {
+			return users.ErrEmailTaken
+		}
+	}
+	s.users[u.ID] = u
+
+	return nil
+}
+
+func (s *MemoryUserStore) GetByID(ctx context.Context, id uuid.UUID) (user.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return user.User{}, users.NotFound
+	}
+
+	return u, nil
+}
+
+func (s *MemoryUserStore) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+
+	return user.User{}, users.NotFound
+}
@@ -0,0 +1,15 @@
+package adaptor
+
+import (
+	"testing"
+
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/action/users/storetest"
+	"go.uber.org/zap"
+)
+
+func TestMemoryUserStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) users.Store {
+		return NewMemoryUserStore(zap.NewNop())
+	})
+}
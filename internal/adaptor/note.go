@@ -8,29 +8,53 @@ import (
 )
 
 type Note struct {
-	ID        uuid.UUID `db:"id"`
-	Label     string    `db:"label"`
-	Body      string    `db:"body"`
-	Tags      []string  `db:"tags"`
-	CreatedAt time.Time `db:"created_at"`
+	ID         uuid.UUID  `db:"id"`
+	OwnerID    uuid.UUID  `db:"owner_id"`
+	Label      string     `db:"label"`
+	Body       string     `db:"body"`
+	Tags       []string   `db:"tags"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+	Version    uint       `db:"version"`
+	DeletedAt  *time.Time `db:"deleted_at"`
+	NotebookID *uuid.UUID `db:"notebook_id"`
+	Pinned     bool       `db:"pinned"`
+	Archived   bool       `db:"archived"`
+	Favorite   bool       `db:"favorite"`
 }
 
 func NoteFromEntity(entity note.Note) (Note, error) {
 	return Note{
-		ID:        entity.ID,
-		Label:     entity.Label,
-		Body:      entity.Body,
-		Tags:      entity.Tags,
-		CreatedAt: entity.CreatedAt,
+		ID:         entity.ID,
+		OwnerID:    entity.OwnerID,
+		Label:      entity.Label,
+		Body:       entity.Body,
+		Tags:       entity.Tags,
+		CreatedAt:  entity.CreatedAt,
+		UpdatedAt:  entity.UpdatedAt,
+		Version:    entity.Version,
+		DeletedAt:  entity.DeletedAt,
+		NotebookID: entity.NotebookID,
+		Pinned:     entity.Pinned,
+		Archived:   entity.Archived,
+		Favorite:   entity.Favorite,
 	}, nil
 }
 
 func NoteToEntity(noteAdapter Note) (note.Note, error) {
 	return note.Note{
-		ID:        noteAdapter.ID,
-		Label:     noteAdapter.Label,
-		Body:      noteAdapter.Body,
-		Tags:      noteAdapter.Tags,
-		CreatedAt: noteAdapter.CreatedAt,
+		ID:         noteAdapter.ID,
+		OwnerID:    noteAdapter.OwnerID,
+		Label:      noteAdapter.Label,
+		Body:       noteAdapter.Body,
+		Tags:       noteAdapter.Tags,
+		CreatedAt:  noteAdapter.CreatedAt,
+		UpdatedAt:  noteAdapter.UpdatedAt,
+		Version:    noteAdapter.Version,
+		DeletedAt:  noteAdapter.DeletedAt,
+		NotebookID: noteAdapter.NotebookID,
+		Pinned:     noteAdapter.Pinned,
+		Archived:   noteAdapter.Archived,
+		Favorite:   noteAdapter.Favorite,
 	}, nil
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
@@ -15,6 +17,37 @@ import (
 
 const NoteTable = "notes"
 
+// ShareTable права пользователей на чужие заметки.
+const ShareTable = "note_shares"
+
+// LinkTable публичные ссылки на заметки.
+const LinkTable = "note_links"
+
+// RevisionTable снимки заметок после каждого изменения.
+const RevisionTable = "note_revisions"
+
+// NotebookTable блокноты, в которые владелец раскладывает заметки.
+const NotebookTable = "notebooks"
+
+// noteColumns колонки в порядке, который ожидают функции сканирования заметок.
+const noteColumns = "id, owner_id, label, body, tags, created_at, updated_at, version, deleted_at, notebook_id, pinned, archived, favorite"
+
+// searchConfig конфигурация полнотекстового поиска postgres, должна совпадать с миграцией 02.
+const searchConfig = "simple"
+
+// headlineText текст заметки для ts_headline с экранированными &, < и >:
+// во фрагменте остаётся только разметка выделения. Сущности парсер считает
+// отдельными лексемами, поэтому фрагмент не разрывает их.
+const headlineText = `replace(replace(replace(
+	coalesce(label, '') || ' ' || coalesce(body, ''),
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+
+// pgUniqueViolation код ошибки postgres при нарушении уникальности.
+const pgUniqueViolation = "23505"
+
+// pgForeignKeyViolation код ошибки postgres, если строка ссылается на несуществующую.
+const pgForeignKeyViolation = "23503"
+
 type NoteStore struct {
 	db  *sql.DB
 	log *zap.Logger
@@ -27,39 +60,26 @@ func NewNoteStore(db *sql.DB, logger *zap.Logger) *NoteStore {
 	}
 }
 
-func (s *NoteStore) CreateTable(ctx context.Context) error {
-	s.log.Debug("creating table", zap.Any("table", NoteTable))
+func (s *NoteStore) Create(ctx context.Context, note note.Note) error {
+	s.log.Debug("saving note", zap.Any("note", note))
 
-	query := fmt.Sprintf(
-		`CREATE TABLE IF NOT EXISTS %v (
-			id UUID PRIMARY KEY NOT NULL,
-			label TEXT NOT NULL,
-			body TEXT,
-			tags TEXT[],
-			created_at timestamptz NOT NULL
-		);`,
-		NoteTable,
-	)
-	result, err := s.db.Exec(query)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		s.log.Error("create table", zap.Error(err))
-		return errors.Wrapf(err, "create table %v", NoteTable)
+		return errors.Wrap(err, "begin transaction")
 	}
+	defer tx.Rollback()
 
-	n, err := result.RowsAffected()
-	if err != nil {
-		errors.WithMessage(err, "Failed during getting rows affected")
+	if err := s.createNote(ctx, tx, note); err != nil {
+		return err
 	}
-	s.log.Debug("created table", zap.Any("rows", n))
-	return nil
 
+	return errors.Wrap(tx.Commit(), "commit note")
 }
 
-func (s *NoteStore) Create(ctx context.Context, note note.Note) error {
-	s.log.Debug("saving note", zap.Any("note", note))
-
+func (s *NoteStore) createNote(ctx context.Context, tx *sql.Tx, note note.Note) error {
 	query := fmt.Sprintf(
-		`INSERT INTO %v (id, label, body, tags, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		`INSERT INTO %v (id, owner_id, label, body, tags, created_at, updated_at, notebook_id, pinned, archived, favorite)
+		VALUES ($1, $2, $3, $4, $5, $6, $6, $7, $8, $9, $10)`,
 		NoteTable,
 	)
 
@@ -68,97 +88,266 @@ func (s *NoteStore) Create(ctx context.Context, note note.Note) error {
 		return errors.WithMessage(err, "convert note to data for database")
 	}
 
-	result, err := s.db.Exec(
+	_, err = tx.ExecContext(
+		ctx,
 		query,
 		data.ID,
+		data.OwnerID,
 		data.Label,
 		data.Body,
 		pq.Array(data.Tags),
 		data.CreatedAt,
+		data.NotebookID,
+		data.Pinned,
+		data.Archived,
+		data.Favorite,
 	)
 	if err != nil {
 		s.log.Debug("failed save to new note to db", zap.Any("err", err))
+		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == pgUniqueViolation {
+			return notes.ErrAlreadyExists.WithCause(err)
+		}
+		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == pgForeignKeyViolation && data.NotebookID != nil {
+			return notes.ErrNotebookNotFound.WithCause(err)
+		}
 		return errors.Wrap(err, "save note to database")
 	}
 
-	n, err := result.RowsAffected()
+	return insertRevision(ctx, tx, data.ID, data.OwnerID, data.CreatedAt)
+}
+
+func (s *NoteStore) Update(ctx context.Context, args notes.UpdateArgs) error {
+	return s.Patch(ctx, args.Patch())
+}
+
+func (s *NoteStore) Patch(ctx context.Context, args notes.PatchArgs) error {
+	s.log.Debug("updating note", zap.Any("args", args))
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		errors.WithMessage(err, "Failed during getting rows affected")
+		return errors.Wrap(err, "begin transaction")
 	}
-	s.log.Debug("created", zap.Any("rows", n))
+	defer tx.Rollback()
 
-	return nil
+	if err := s.patchNote(ctx, tx, args); err != nil {
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "commit note")
 }
 
-func (s *NoteStore) Update(ctx context.Context, args notes.UpdateArgs) error {
-	s.log.Debug("updating note", zap.Any("args", args))
+func (s *NoteStore) patchNote(ctx context.Context, tx *sql.Tx, args notes.PatchArgs) error {
+	sets, params := []string{}, []any{}
+	set := func(column string, value any) {
+		params = append(params, value)
+		sets = append(sets, fmt.Sprintf("%v = $%v", column, len(params)))
+	}
+	if args.Label != nil {
+		set("label", *args.Label)
+	}
+	if args.Body != nil {
+		set("body", *args.Body)
+	}
+	if args.Tags != nil {
+		set("tags", pq.Array(*args.Tags))
+	}
+	set("updated_at", args.UpdatedAt)
+	params = append(params, args.ID, args.Version)
 
 	query := fmt.Sprintf(
-		`UPDATE %v SET
-			label = $1,
-			body = $2,
-			tags = $3
-			WHERE id = $4
-		`,
-		NoteTable,
+		`UPDATE %v SET %v, version = version + 1
+		WHERE id = $%v AND ($%v = 0 OR version = $%[4]v) AND deleted_at IS NULL`,
+		NoteTable, strings.Join(sets, ", "), len(params)-1, len(params),
 	)
 
-	result, err := s.db.Exec(
-		query,
-		args.Label,
-		args.Body,
-		pq.Array(args.Tags),
-		args.ID,
-	)
+	result, err := tx.ExecContext(ctx, query, params...)
 	if err != nil {
 		s.log.Debug("failed update note to db", zap.Any("err", err))
 		return errors.Wrap(err, "update note to database")
 	}
+	if notFoundIfNoRows(result) != nil {
+		return noteMissingOrChanged(ctx, tx, `id = $1`, args.ID)
+	}
 
+	return insertRevision(ctx, tx, args.ID, args.UserID, args.UpdatedAt)
+}
+
+// noteMissingOrChanged объясняет, почему условное изменение не затронуло
+// заметку: её нет, она в корзине или версия уже другая.
+func noteMissingOrChanged(ctx context.Context, tx *sql.Tx, condition string, params ...any) error {
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %v WHERE %v AND deleted_at IS NULL)`, NoteTable, condition)
+	if err := tx.QueryRowContext(ctx, query, params...).Scan(&exists); err != nil {
+		return errors.Wrap(err, "check note exists")
+	}
+	if !exists {
+		return notes.NotFound
+	}
+
+	return notes.ErrVersionMismatch
+}
+
+// insertRevision сохраняет текущее содержимое заметки следующей ревизией.
+// UPDATE в той же транзакции блокирует строку заметки, поэтому номера
+// ревизий при параллельных изменениях не повторяются.
+func insertRevision(ctx context.Context, tx *sql.Tx, noteID, authorID uuid.UUID, at time.Time) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %[1]v (note_id, rev, label, body, tags, author_id, created_at)
+		SELECT id, COALESCE((SELECT MAX(rev) FROM %[1]v WHERE note_id = $1), 0) + 1, label, COALESCE(body, ''), tags, $2, $3
+		FROM %[2]v WHERE id = $1`,
+		RevisionTable, NoteTable,
+	)
+
+	_, err := tx.ExecContext(ctx, query, noteID, uuid.NullUUID{UUID: authorID, Valid: authorID != uuid.Nil}, at)
+
+	return errors.Wrap(err, "save note revision")
+}
+
+// notFoundIfNoRows возвращает notes.NotFound, если запрос не затронул ни одной строки.
+func notFoundIfNoRows(result sql.Result) error {
 	count, err := result.RowsAffected()
 	if err != nil {
-		errors.WithMessage(err, "Failed during getting rows affected")
+		return errors.Wrap(err, "Failed during getting rows affected")
+	}
+	if count == 0 {
+		return notes.NotFound
 	}
-	s.log.Debug("updated", zap.Any("count", count))
 
 	return nil
 }
 
-func (s *NoteStore) Delete(ctx context.Context, noteIDs []uuid.UUID) error {
-	s.log.Debug("deleting note by ids", zap.Any("note ids", noteIDs))
+// Delete переносит заметки владельца в корзину. Заметки, которые уже там,
+// не меняются.
+func (s *NoteStore) Delete(ctx context.Context, ownerID uuid.UUID, targets []notes.DeleteTarget, deletedAt time.Time) error {
+	s.log.Debug("deleting notes", zap.Any("targets", targets))
 
-	idString := make([]string, len(noteIDs))
-	for key, value := range noteIDs {
-		idString[key] = value.String()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
 	}
+	defer tx.Rollback()
+
+	for _, target := range targets {
+		err := deleteNote(ctx, tx, ownerID, target.ID, target.Version, deletedAt)
+		if err != nil && !errors.Is(err, notes.NotFound) {
+			return err
+		}
+	}
+
+	return errors.Wrap(tx.Commit(), "commit delete notes")
+}
+
+func (s *NoteStore) DeleteOne(ctx context.Context, ownerID, id uuid.UUID, version uint, deletedAt time.Time) error {
+	s.log.Debug("deleting note", zap.Any("noteID", id), zap.Uint("version", version))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := deleteNote(ctx, tx, ownerID, id, version, deletedAt); err != nil {
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "commit delete note")
+}
 
+func deleteNote(ctx context.Context, tx *sql.Tx, ownerID, id uuid.UUID, version uint, deletedAt time.Time) error {
 	query := fmt.Sprintf(
-		`DELETE FROM %v WHERE id=ANY($1::uuid[])`,
+		`UPDATE %v SET deleted_at = $4
+		WHERE id = $1 AND owner_id = $2 AND ($3 = 0 OR version = $3) AND deleted_at IS NULL`,
 		NoteTable,
 	)
+	result, err := tx.ExecContext(ctx, query, id, ownerID, version, deletedAt)
+	if err != nil {
+		return errors.Wrap(err, "delete note")
+	}
+	if notFoundIfNoRows(result) != nil {
+		return noteMissingOrChanged(ctx, tx, `id = $1 AND owner_id = $2`, id, ownerID)
+	}
+
+	return nil
+}
 
-	result, err := s.db.Exec(query, pq.Array(idString))
+// Batch выполняет изменения в одной транзакции. С continueOnError каждое
+// изменение обёрнуто в точку сохранения, и ошибка откатывает только его.
+func (s *NoteStore) Batch(ctx context.Context, writes []notes.BatchWrite, continueOnError bool) ([]error, error) {
+	s.log.Debug("applying batch", zap.Int("count", len(writes)), zap.Bool("continueOnError", continueOnError))
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		s.log.Debug("Failed delete by notes id", zap.Any("error", err))
-		return errors.Wrap(err, "delete notes by ids")
+		return nil, errors.Wrap(err, "begin transaction")
 	}
+	defer tx.Rollback()
 
-	count, err := result.RowsAffected()
+	apply := func(write notes.BatchWrite) error {
+		switch {
+		case write.Create != nil:
+			return s.createNote(ctx, tx, *write.Create)
+		case write.Patch != nil:
+			return s.patchNote(ctx, tx, *write.Patch)
+		case write.Delete != nil:
+			d := write.Delete
+			return deleteNote(ctx, tx, d.OwnerID, d.ID, d.Version, d.DeletedAt)
+		}
+		return errors.New("empty batch write")
+	}
+
+	errs, err := applyBatch(ctx, tx, writes, continueOnError, apply)
+	if err != nil || !committable(errs, continueOnError) {
+		return errs, err
+	}
+
+	return errs, errors.Wrap(tx.Commit(), "commit batch")
+}
+
+func (s *NoteStore) Restore(ctx context.Context, ownerID, id uuid.UUID) error {
+	s.log.Debug("restoring note", zap.Any("noteID", id))
+
+	query := fmt.Sprintf(
+		`UPDATE %v SET deleted_at = NULL WHERE id = $1 AND owner_id = $2 AND deleted_at IS NOT NULL`,
+		NoteTable,
+	)
+	result, err := s.db.ExecContext(ctx, query, id, ownerID)
 	if err != nil {
-		errors.WithMessage(err, "Failed during getting rows affected")
+		return errors.Wrap(err, "restore note")
 	}
 
-	s.log.Debug("deleted", zap.Any("count", count))
+	return notFoundIfNoRows(result)
+}
 
-	return nil
+// Purge удаляет заметку из корзины навсегда. Права, ссылки и ревизии
+// удаляются каскадом.
+func (s *NoteStore) Purge(ctx context.Context, ownerID, id uuid.UUID) error {
+	s.log.Debug("purging note", zap.Any("noteID", id))
+
+	query := fmt.Sprintf(
+		`DELETE FROM %v WHERE id = $1 AND owner_id = $2 AND deleted_at IS NOT NULL`,
+		NoteTable,
+	)
+	result, err := s.db.ExecContext(ctx, query, id, ownerID)
+	if err != nil {
+		return errors.Wrap(err, "purge note")
+	}
+
+	return notFoundIfNoRows(result)
+}
+
+func (s *NoteStore) PurgeDeleted(ctx context.Context, before time.Time) (uint, error) {
+	query := fmt.Sprintf(`DELETE FROM %v WHERE deleted_at < $1`, NoteTable)
+
+	count, err := s.execCount(ctx, query, before)
+
+	return count, errors.WithMessage(err, "purge deleted notes")
 }
 
 func (s *NoteStore) GetByID(ctx context.Context, id uuid.UUID) (note.Note, error) {
 	s.log.Debug("getting note by ID", zap.Any("noteID", id))
 
 	query := fmt.Sprintf(
-		`SELECT * FROM %v WHERE id = $1::uuid`,
-		NoteTable,
+		`SELECT %v FROM %v WHERE id = $1::uuid AND deleted_at IS NULL`,
+		noteColumns, NoteTable,
 	)
 
 	rows, err := s.db.Query(query, id)
@@ -171,7 +360,7 @@ func (s *NoteStore) GetByID(ctx context.Context, id uuid.UUID) (note.Note, error
 
 	for rows.Next() {
 		note := Note{}
-		err = rows.Scan(&note.ID, &note.Label, &note.Body, pq.Array(&note.Tags), &note.CreatedAt)
+		err = rows.Scan(&note.ID, &note.OwnerID, &note.Label, &note.Body, pq.Array(&note.Tags), &note.CreatedAt, &note.UpdatedAt, &note.Version, &note.DeletedAt, &note.NotebookID, &note.Pinned, &note.Archived, &note.Favorite)
 		if err != nil {
 			s.log.Debug("scan line", zap.Error(err))
 			errors.WithMessage(err, "Failed during Scan rows to dest")
@@ -181,7 +370,7 @@ func (s *NoteStore) GetByID(ctx context.Context, id uuid.UUID) (note.Note, error
 	}
 
 	if len(listNotes) == 0 {
-		return note.Note{}, errors.New("Not Found")
+		return note.Note{}, notes.NotFound
 	}
 
 	firstNote := listNotes[0]
@@ -196,24 +385,32 @@ func (s *NoteStore) GetByID(ctx context.Context, id uuid.UUID) (note.Note, error
 func (s *NoteStore) Query(ctx context.Context, args notes.ListArgs) ([]note.Note, error) {
 	s.log.Debug("getting notes with pagination and order", zap.Any("args", args))
 
-	order, limit, offset := s.getPaginationParams(args)
+	order, limit, offset := orderAndPagination(args)
+
+	conditions, params := pgConditions(args.Filter, nil)
+	if args.Keyset != nil {
+		var keyset string
+		keyset, params = pgKeyset(args, params)
+		conditions = append(conditions, keyset)
+	}
+	where := whereClause(conditions)
 
 	query := fmt.Sprintf(
-		`SELECT * FROM %v ORDER BY %v LIMIT %v OFFSET %v`,
-		NoteTable, order, limit, offset,
+		`SELECT %v FROM %v %v ORDER BY %v LIMIT %v OFFSET %v`,
+		noteColumns, NoteTable, where, order, limit, offset,
 	)
 
-	rows, err := s.db.Query(query)
+	rows, err := s.db.QueryContext(ctx, query, params...)
 	if err != nil {
 		return []note.Note{}, errors.Wrap(err, "failed during get notes")
 	}
 	defer rows.Close()
 
-	notes := []note.Note{}
+	list := []note.Note{}
 
 	for rows.Next() {
 		note := Note{}
-		err = rows.Scan(&note.ID, &note.Label, &note.Body, pq.Array(&note.Tags), &note.CreatedAt)
+		err = rows.Scan(&note.ID, &note.OwnerID, &note.Label, &note.Body, pq.Array(&note.Tags), &note.CreatedAt, &note.UpdatedAt, &note.Version, &note.DeletedAt, &note.NotebookID, &note.Pinned, &note.Archived, &note.Favorite)
 		if err != nil {
 			s.log.Debug("scan line", zap.Error(err))
 			errors.WithMessage(err, "Failed during Scan rows to dest")
@@ -224,27 +421,37 @@ func (s *NoteStore) Query(ctx context.Context, args notes.ListArgs) ([]note.Note
 			s.log.Debug("convert to entity", zap.Error(err))
 			continue
 		}
-		notes = append(notes, n)
+		list = append(list, n)
 	}
 
-	if len(notes) == 0 {
-		return []note.Note{}, errors.New("Not Found")
+	if len(list) == 0 {
+		return []note.Note{}, notes.NotFound
 	}
 
-	return notes, nil
+	return list, nil
 }
 
-func (s *NoteStore) getPaginationParams(args notes.ListArgs) (order, limit string, offset uint) {
-	orderBy := "label"
-	if args.SortBy == notes.SortFieldDate {
-		orderBy = "created_at"
-	}
+// orderAndPagination общая для SQL-хранилищ часть запроса списка.
+// id добавлен в сортировку, чтобы порядок при равных ключах был стабильным,
+// закреплённые заметки всегда идут первыми.
+func orderAndPagination(args notes.ListArgs) (order, limit string, offset uint) {
+	orderBy := sortColumn(args.SortBy)
 
+	desc := args.SortDirection == notes.SortDirectionDesc
+	if args.Keyset != nil && args.Keyset.Backward {
+		// Страница назад читается в обратном порядке от курсора.
+		desc = !desc
+	}
 	direction := "ASC"
-	if args.SortDirection == notes.SortDirectionDesc {
+	if desc {
 		direction = "DESC"
 	}
-	order = fmt.Sprintf("%v %v", orderBy, direction)
+	// Закреплённые заметки первыми независимо от направления.
+	pinned := "DESC"
+	if args.Keyset != nil && args.Keyset.Backward {
+		pinned = "ASC"
+	}
+	order = fmt.Sprintf("pinned %[3]v, %[1]v %[2]v, id %[2]v", orderBy, direction, pinned)
 
 	limit = "ALL"
 	if args.Limit > 0 {
@@ -256,19 +463,569 @@ func (s *NoteStore) getPaginationParams(args notes.ListArgs) (order, limit strin
 	return order, limit, offset
 }
 
-func (s *NoteStore) Count(ctx context.Context) (uint, error) {
-	s.log.Debug("counting notes")
+func (s *NoteStore) Count(ctx context.Context, filter notes.Filter) (uint, error) {
+	s.log.Debug("counting notes", zap.Any("filter", filter))
 
+	where, params := pgWhere(filter, nil)
 	query := fmt.Sprintf(
-		`SELECT COUNT(*) FROM %v`,
-		NoteTable,
+		`SELECT COUNT(*) FROM %v %v`,
+		NoteTable, where,
 	)
 
 	count := new(uint)
-	err := s.db.QueryRow(query).Scan(count)
+	err := s.db.QueryRowContext(ctx, query, params...).Scan(count)
 	if err != nil {
 		return 0, errors.WithMessage(err, "count notes")
 	}
 
 	return *count, nil
 }
+
+func (s *NoteStore) Search(ctx context.Context, args notes.SearchArgs) ([]note.FoundNote, error) {
+	s.log.Debug("searching notes", zap.Any("args", args))
+
+	limit := "ALL"
+	if args.Limit > 0 {
+		limit = fmt.Sprintf("%v", args.Limit)
+	}
+
+	query := fmt.Sprintf(
+		`SELECT %[1]v,
+			ts_rank(search, q) AS rank,
+			ts_headline('%[3]v', %[6]v, q,
+				'MaxFragments=2, MaxWords=30, MinWords=10') AS snippet
+		FROM %[2]v, websearch_to_tsquery('%[3]v', $1) q
+		WHERE search @@ q AND owner_id = $2 AND deleted_at IS NULL
+		ORDER BY rank DESC, id
+		LIMIT %[4]v OFFSET %[5]v`,
+		noteColumns, NoteTable, searchConfig, limit, args.Offset, headlineText,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, args.Query, args.OwnerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed during search notes")
+	}
+	defer rows.Close()
+
+	result := []note.FoundNote{}
+	for rows.Next() {
+		data := Note{}
+		found := note.FoundNote{}
+		err = rows.Scan(&data.ID, &data.OwnerID, &data.Label, &data.Body, pq.Array(&data.Tags), &data.CreatedAt, &data.UpdatedAt, &data.Version, &data.DeletedAt, &data.NotebookID, &data.Pinned, &data.Archived, &data.Favorite, &found.Rank, &found.Snippet)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed during Scan rows to dest")
+		}
+		found.Note, err = NoteToEntity(data)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed during convert note to entity")
+		}
+		result = append(result, found)
+	}
+
+	return result, errors.Wrap(rows.Err(), "failed during read found notes")
+}
+
+func (s *NoteStore) SearchCount(ctx context.Context, args notes.SearchArgs) (uint, error) {
+	s.log.Debug("counting found notes", zap.String("query", args.Query))
+
+	var count uint
+	err := s.db.QueryRowContext(ctx,
+		fmt.Sprintf(
+			`SELECT COUNT(*) FROM %v WHERE search @@ websearch_to_tsquery('%v', $1) AND owner_id = $2 AND deleted_at IS NULL`,
+			NoteTable, searchConfig,
+		),
+		args.Query, args.OwnerID,
+	).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "count found notes")
+	}
+
+	return count, nil
+}
+
+func (s *NoteStore) ListTags(ctx context.Context, ownerID uuid.UUID) ([]note.Tag, error) {
+	s.log.Debug("listing tags", zap.Any("ownerID", ownerID))
+
+	query := fmt.Sprintf(
+		`SELECT tag, COUNT(DISTINCT id) AS count
+		FROM %v, unnest(tags) AS tag
+		WHERE owner_id = $1 AND deleted_at IS NULL
+		GROUP BY tag
+		ORDER BY count DESC, tag`,
+		NoteTable,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list tags")
+	}
+	defer rows.Close()
+
+	tags := []note.Tag{}
+	for rows.Next() {
+		tag := note.Tag{}
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, errors.Wrap(err, "scan tag")
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, errors.Wrap(rows.Err(), "read tags")
+}
+
+// RenameTag одним запросом: если новый тег у заметки уже есть, старый просто удаляется.
+func (s *NoteStore) RenameTag(ctx context.Context, ownerID uuid.UUID, from, to string) (uint, error) {
+	s.log.Debug("renaming tag", zap.String("from", from), zap.String("to", to))
+
+	query := fmt.Sprintf(
+		`UPDATE %v SET tags = CASE
+			WHEN $2 = ANY(tags) THEN array_remove(tags, $1)
+			ELSE array_replace(tags, $1, $2)
+		END, updated_at = $4, version = version + 1
+		WHERE $1 = ANY(tags) AND owner_id = $3 AND deleted_at IS NULL`,
+		NoteTable,
+	)
+
+	return s.execCount(ctx, withRevisions(query, "$3", "$4"), from, to, ownerID, time.Now())
+}
+
+func (s *NoteStore) DeleteTag(ctx context.Context, ownerID uuid.UUID, tag string) (uint, error) {
+	s.log.Debug("deleting tag", zap.String("tag", tag))
+
+	query := fmt.Sprintf(
+		`UPDATE %v SET tags = array_remove(tags, $1), updated_at = $3, version = version + 1
+		WHERE $1 = ANY(tags) AND owner_id = $2 AND deleted_at IS NULL`,
+		NoteTable,
+	)
+
+	return s.execCount(ctx, withRevisions(query, "$2", "$3"), tag, ownerID, time.Now())
+}
+
+// withRevisions дополняет UPDATE заметок записью ревизии для каждой изменённой
+// заметки тем же запросом. Число затронутых строк совпадает с числом заметок.
+func withRevisions(update, author, at string) string {
+	return fmt.Sprintf(
+		`WITH changed AS (%[2]v RETURNING id, label, body, tags)
+		INSERT INTO %[1]v (note_id, rev, label, body, tags, author_id, created_at)
+		SELECT c.id, COALESCE((SELECT MAX(r.rev) FROM %[1]v r WHERE r.note_id = c.id), 0) + 1,
+			c.label, COALESCE(c.body, ''), c.tags, %[3]v, %[4]v
+		FROM changed c`,
+		RevisionTable, update, author, at,
+	)
+}
+
+func (s *NoteStore) execCount(ctx context.Context, query string, params ...any) (uint, error) {
+	result, err := s.db.ExecContext(ctx, query, params...)
+	if err != nil {
+		return 0, errors.Wrap(err, "exec query")
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed during getting rows affected")
+	}
+
+	return uint(count), nil
+}
+
+func (s *NoteStore) Access(ctx context.Context, userID, noteID uuid.UUID) (note.Permission, error) {
+	query := fmt.Sprintf(
+		`SELECT CASE WHEN n.owner_id = $1 THEN '%v' ELSE sh.permission END
+		FROM %v n
+		LEFT JOIN %v sh ON sh.note_id = n.id AND sh.user_id = $1
+		WHERE n.id = $2 AND n.deleted_at IS NULL AND (n.owner_id = $1 OR sh.user_id IS NOT NULL)`,
+		note.PermissionOwner, NoteTable, ShareTable,
+	)
+
+	var permission note.Permission
+	err := s.db.QueryRowContext(ctx, query, userID, noteID).Scan(&permission)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", notes.NotFound
+	}
+
+	return permission, errors.Wrap(err, "check note access")
+}
+
+func (s *NoteStore) Share(ctx context.Context, share note.Share) error {
+	s.log.Debug("sharing note", zap.Any("share", share))
+
+	query := fmt.Sprintf(
+		`INSERT INTO %v (note_id, user_id, permission, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (note_id, user_id) DO UPDATE SET permission = EXCLUDED.permission`,
+		ShareTable,
+	)
+
+	_, err := s.db.ExecContext(ctx, query, share.NoteID, share.UserID, share.Permission, share.CreatedAt)
+	if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == pgForeignKeyViolation {
+		return notes.NotFound.WithCause(err)
+	}
+
+	return errors.Wrap(err, "share note")
+}
+
+func (s *NoteStore) Unshare(ctx context.Context, noteID, userID uuid.UUID) error {
+	s.log.Debug("unsharing note", zap.Any("noteID", noteID), zap.Any("userID", userID))
+
+	query := fmt.Sprintf(`DELETE FROM %v WHERE note_id = $1 AND user_id = $2`, ShareTable)
+
+	result, err := s.db.ExecContext(ctx, query, noteID, userID)
+	if err != nil {
+		return errors.Wrap(err, "unshare note")
+	}
+	if err := notFoundIfNoRows(result); err != nil {
+		return notes.ErrShareNotFound
+	}
+
+	return nil
+}
+
+func (s *NoteStore) ListShares(ctx context.Context, noteID uuid.UUID) ([]note.Share, error) {
+	query := fmt.Sprintf(
+		`SELECT note_id, user_id, permission, created_at FROM %v WHERE note_id = $1 ORDER BY created_at, user_id`,
+		ShareTable,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, noteID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list shares")
+	}
+	defer rows.Close()
+
+	list := []note.Share{}
+	for rows.Next() {
+		var share note.Share
+		if err := rows.Scan(&share.NoteID, &share.UserID, &share.Permission, &share.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, "scan share")
+		}
+		list = append(list, share)
+	}
+
+	return list, errors.Wrap(rows.Err(), "read shares")
+}
+
+func (s *NoteStore) SaveLink(ctx context.Context, link note.Link) error {
+	s.log.Debug("saving link", zap.Any("noteID", link.NoteID))
+
+	query := fmt.Sprintf(
+		`INSERT INTO %v (note_id, token, created_at, expires_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (note_id) DO UPDATE SET token = EXCLUDED.token, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at`,
+		LinkTable,
+	)
+
+	_, err := s.db.ExecContext(ctx, query, link.NoteID, link.Token, link.CreatedAt, link.ExpiresAt)
+	if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == pgForeignKeyViolation {
+		return notes.NotFound.WithCause(err)
+	}
+
+	return errors.Wrap(err, "save link")
+}
+
+func (s *NoteStore) GetLink(ctx context.Context, noteID uuid.UUID) (note.Link, error) {
+	return s.getLink(ctx, "l.note_id = $1", noteID)
+}
+
+func (s *NoteStore) GetLinkByToken(ctx context.Context, token string) (note.Link, error) {
+	return s.getLink(ctx, "l.token = $1", token)
+}
+
+func (s *NoteStore) getLink(ctx context.Context, condition string, param any) (note.Link, error) {
+	query := fmt.Sprintf(
+		`SELECT l.note_id, n.owner_id, l.token, l.created_at, l.expires_at
+		FROM %v l JOIN %v n ON n.id = l.note_id
+		WHERE %v AND n.deleted_at IS NULL`,
+		LinkTable, NoteTable, condition,
+	)
+
+	var (
+		link      note.Link
+		expiresAt sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx, query, param).Scan(&link.NoteID, &link.OwnerID, &link.Token, &link.CreatedAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Link{}, notes.ErrLinkNotFound
+	}
+	if err != nil {
+		return note.Link{}, errors.Wrap(err, "get link")
+	}
+	if expiresAt.Valid {
+		link.ExpiresAt = &expiresAt.Time
+	}
+
+	return link, nil
+}
+
+func (s *NoteStore) DeleteLink(ctx context.Context, noteID uuid.UUID) error {
+	s.log.Debug("deleting link", zap.Any("noteID", noteID))
+
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %v WHERE note_id = $1`, LinkTable), noteID)
+	if err != nil {
+		return errors.Wrap(err, "delete link")
+	}
+	if err := notFoundIfNoRows(result); err != nil {
+		return notes.ErrLinkNotFound
+	}
+
+	return nil
+}
+
+// revisionColumns колонки в порядке, который ожидает scanRevision.
+const revisionColumns = "note_id, rev, label, body, tags, author_id, created_at"
+
+func (s *NoteStore) ListRevisions(ctx context.Context, noteID uuid.UUID) ([]note.Revision, error) {
+	query := fmt.Sprintf(
+		`SELECT %v FROM %v WHERE note_id = $1 ORDER BY rev DESC`,
+		revisionColumns, RevisionTable,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, noteID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list revisions")
+	}
+	defer rows.Close()
+
+	list := []note.Revision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, revision)
+	}
+
+	return list, errors.Wrap(rows.Err(), "read revisions")
+}
+
+func (s *NoteStore) GetRevision(ctx context.Context, noteID uuid.UUID, rev uint) (note.Revision, error) {
+	query := fmt.Sprintf(
+		`SELECT %v FROM %v WHERE note_id = $1 AND rev = $2`,
+		revisionColumns, RevisionTable,
+	)
+
+	revision, err := scanRevision(s.db.QueryRowContext(ctx, query, noteID, rev))
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Revision{}, notes.ErrRevisionNotFound
+	}
+
+	return revision, err
+}
+
+func scanRevision(row rowScanner) (note.Revision, error) {
+	var (
+		revision note.Revision
+		authorID uuid.NullUUID
+	)
+	err := row.Scan(&revision.NoteID, &revision.Rev, &revision.Label, &revision.Body, pq.Array(&revision.Tags), &authorID, &revision.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Revision{}, err
+	}
+	if err != nil {
+		return note.Revision{}, errors.Wrap(err, "scan revision")
+	}
+	revision.AuthorID = authorID.UUID
+
+	return revision, nil
+}
+
+// notebookColumns колонки в порядке, который ожидает scanNotebook.
+const notebookColumns = "id, owner_id, parent_id, name, created_at, updated_at"
+
+func (s *NoteStore) CreateNotebook(ctx context.Context, notebook note.Notebook) error {
+	s.log.Debug("saving notebook", zap.Any("notebook", notebook))
+
+	query := fmt.Sprintf(
+		`INSERT INTO %v (%v) VALUES ($1, $2, $3, $4, $5, $6)`,
+		NotebookTable, notebookColumns,
+	)
+
+	_, err := s.db.ExecContext(ctx, query, notebook.ID, notebook.OwnerID, notebook.ParentID, notebook.Name, notebook.CreatedAt, notebook.UpdatedAt)
+	if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == pgForeignKeyViolation {
+		return notes.ErrNotebookNotFound.WithCause(err)
+	}
+
+	return errors.Wrap(err, "save notebook")
+}
+
+func (s *NoteStore) GetNotebook(ctx context.Context, ownerID, id uuid.UUID) (note.Notebook, error) {
+	query := fmt.Sprintf(
+		`SELECT %v FROM %v WHERE id = $1 AND owner_id = $2`,
+		notebookColumns, NotebookTable,
+	)
+
+	notebook, err := scanNotebook(s.db.QueryRowContext(ctx, query, id, ownerID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Notebook{}, notes.ErrNotebookNotFound
+	}
+
+	return notebook, err
+}
+
+func (s *NoteStore) ListNotebooks(ctx context.Context, ownerID uuid.UUID) ([]note.Notebook, error) {
+	query := fmt.Sprintf(
+		`SELECT %v FROM %v WHERE owner_id = $1 ORDER BY name, id`,
+		notebookColumns, NotebookTable,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list notebooks")
+	}
+	defer rows.Close()
+
+	list := []note.Notebook{}
+	for rows.Next() {
+		notebook, err := scanNotebook(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, notebook)
+	}
+
+	return list, errors.Wrap(rows.Err(), "read notebooks")
+}
+
+func (s *NoteStore) UpdateNotebook(ctx context.Context, notebook note.Notebook) error {
+	s.log.Debug("updating notebook", zap.Any("notebook", notebook))
+
+	query := fmt.Sprintf(
+		`UPDATE %v SET name = $3, parent_id = $4, updated_at = $5 WHERE id = $1 AND owner_id = $2`,
+		NotebookTable,
+	)
+
+	result, err := s.db.ExecContext(ctx, query, notebook.ID, notebook.OwnerID, notebook.Name, notebook.ParentID, notebook.UpdatedAt)
+	if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == pgForeignKeyViolation {
+		return notes.ErrNotebookNotFound.WithCause(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "update notebook")
+	}
+	if err := notFoundIfNoRows(result); err != nil {
+		return notes.ErrNotebookNotFound
+	}
+
+	return nil
+}
+
+// DeleteNotebook полагается на внешние ключи: вложенные блокноты удаляются
+// каскадом, а заметки в корзине из удалённых блокнотов оказываются в корне.
+func (s *NoteStore) DeleteNotebook(ctx context.Context, ownerID, id uuid.UUID, policy notes.NotebookDeletePolicy, at time.Time) error {
+	s.log.Debug("deleting notebook", zap.Any("notebookID", id), zap.String("policy", string(policy)))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	var parentID uuid.NullUUID
+	query := fmt.Sprintf(`SELECT parent_id FROM %v WHERE id = $1 AND owner_id = $2 FOR UPDATE`, NotebookTable)
+	err = tx.QueryRowContext(ctx, query, id, ownerID).Scan(&parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notes.ErrNotebookNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "get notebook to delete")
+	}
+
+	if policy == notes.NotebookDeleteTrash {
+		query = fmt.Sprintf(
+			`UPDATE %v SET deleted_at = $2, notebook_id = NULL WHERE %v AND deleted_at IS NULL`,
+			NoteTable, notebookCondition(true, "$1::uuid"),
+		)
+		if _, err := tx.ExecContext(ctx, query, id, at); err != nil {
+			return errors.Wrap(err, "trash notebook notes")
+		}
+	} else {
+		query = fmt.Sprintf(
+			`UPDATE %v SET notebook_id = $2, updated_at = $3, version = version + 1
+			WHERE notebook_id = $1 AND deleted_at IS NULL`,
+			NoteTable,
+		)
+		if _, err := tx.ExecContext(ctx, query, id, parentID, at); err != nil {
+			return errors.Wrap(err, "move notebook notes")
+		}
+
+		query = fmt.Sprintf(`UPDATE %v SET parent_id = $2, updated_at = $3 WHERE parent_id = $1`, NotebookTable)
+		if _, err := tx.ExecContext(ctx, query, id, parentID, at); err != nil {
+			return errors.Wrap(err, "move nested notebooks")
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %v WHERE id = $1`, NotebookTable), id); err != nil {
+		return errors.Wrap(err, "delete notebook")
+	}
+
+	return errors.Wrap(tx.Commit(), "commit delete notebook")
+}
+
+// MoveNotes не трогает заметки, которые уже лежат в нужном блокноте.
+func (s *NoteStore) MoveNotes(ctx context.Context, ownerID uuid.UUID, ids []uuid.UUID, notebookID uuid.UUID, at time.Time) (uint, error) {
+	s.log.Debug("moving notes", zap.Any("note ids", ids), zap.Any("notebookID", notebookID))
+
+	idString := make([]string, len(ids))
+	for key, value := range ids {
+		idString[key] = value.String()
+	}
+
+	query := fmt.Sprintf(
+		`UPDATE %v SET notebook_id = $3::uuid, updated_at = $4, version = version + 1
+		WHERE id = ANY($1::uuid[]) AND owner_id = $2 AND deleted_at IS NULL AND notebook_id IS DISTINCT FROM $3::uuid`,
+		NoteTable,
+	)
+
+	count, err := s.execCount(ctx, query, pq.Array(idString), ownerID, uuid.NullUUID{UUID: notebookID, Valid: notebookID != uuid.Nil}, at)
+	if pgErr, ok := errors.Cause(err).(*pq.Error); ok && pgErr.Code == pgForeignKeyViolation {
+		return 0, notes.ErrNotebookNotFound.WithCause(err)
+	}
+
+	return count, errors.WithMessage(err, "move notes")
+}
+
+func (s *NoteStore) SetFlag(ctx context.Context, id uuid.UUID, flag notes.Flag, value bool, version uint, at time.Time) error {
+	s.log.Debug("setting note flag", zap.Any("noteID", id), zap.String("flag", string(flag)), zap.Bool("value", value))
+
+	column, err := flagColumn(flag)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(
+		`UPDATE %v SET %v = $1, updated_at = $2, version = version + 1
+		WHERE id = $3 AND ($4 = 0 OR version = $4) AND deleted_at IS NULL`,
+		NoteTable, column,
+	)
+
+	result, err := tx.ExecContext(ctx, query, value, at, id, version)
+	if err != nil {
+		return errors.Wrap(err, "set note flag")
+	}
+	if notFoundIfNoRows(result) != nil {
+		return noteMissingOrChanged(ctx, tx, `id = $1`, id)
+	}
+
+	return errors.Wrap(tx.Commit(), "commit note flag")
+}
+
+func scanNotebook(row rowScanner) (note.Notebook, error) {
+	var (
+		notebook note.Notebook
+		parentID uuid.NullUUID
+	)
+	err := row.Scan(&notebook.ID, &notebook.OwnerID, &parentID, &notebook.Name, &notebook.CreatedAt, &notebook.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Notebook{}, err
+	}
+	if err != nil {
+		return note.Notebook{}, errors.Wrap(err, "scan notebook")
+	}
+	if parentID.Valid {
+		notebook.ParentID = &parentID.UUID
+	}
+
+	return notebook, nil
+}
@@ -0,0 +1,19 @@
+package adaptor
+
+import (
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes/storetest"
+	"go.uber.org/zap"
+)
+
+func TestNoteStore(t *testing.T) {
+	db := openTestPostgres(t)
+
+	storetest.Run(t, func(t *testing.T, owners ...uuid.UUID) notes.Store {
+		resetTables(t, db, owners...)
+		return NewNoteStore(db, zap.NewNop())
+	})
+}
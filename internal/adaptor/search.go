@@ -0,0 +1,160 @@
+package adaptor
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+)
+
+// snippetRadius сколько символов показывать вокруг первого совпадения.
+const snippetRadius = 60
+
+// searchNotes простой поиск для хранилищ без полнотекстового индекса:
+// заметка подходит, если каждое слово запроса встречается в метке или тексте
+// без учёта регистра. Совпадения в метке весят вдвое больше.
+func searchNotes(list []note.Note, args notes.SearchArgs) []note.FoundNote {
+	terms := searchTerms(args.Query)
+	if len(terms) == 0 {
+		return []note.FoundNote{}
+	}
+
+	found := []note.FoundNote{}
+	for _, n := range list {
+		label := strings.ToLower(n.Label)
+		body := strings.ToLower(n.Body)
+
+		var rank float32
+		matched := true
+		for _, term := range terms {
+			inLabel := strings.Count(label, term)
+			inBody := strings.Count(body, term)
+			if inLabel+inBody == 0 {
+				matched = false
+				break
+			}
+			rank += float32(2*inLabel + inBody)
+		}
+		if !matched {
+			continue
+		}
+
+		found = append(found, note.FoundNote{
+			Note:    n,
+			Rank:    rank,
+			Snippet: snippet(n.Label+" "+n.Body, terms),
+		})
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Rank != found[j].Rank {
+			return found[i].Rank > found[j].Rank
+		}
+		return found[i].ID.String() < found[j].ID.String()
+	})
+
+	return found
+}
+
+func paginateFound(list []note.FoundNote, offset, limit uint) []note.FoundNote {
+	if offset >= uint(len(list)) {
+		return []note.FoundNote{}
+	}
+	list = list[offset:]
+
+	if limit > 0 && limit < uint(len(list)) {
+		list = list[:limit]
+	}
+
+	return list
+}
+
+func searchTerms(query string) []string {
+	terms := []string{}
+	for _, field := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"'
+	}) {
+		if field = strings.TrimLeft(field, "-"); field != "" {
+			terms = append(terms, field)
+		}
+	}
+
+	return terms
+}
+
+// snippet вырезает фрагмент вокруг первого совпадения и выделяет все совпадения тегом <b>,
+// как это делает ts_headline в postgres. Текст заметки экранируется, единственная
+// разметка во фрагменте - выделение.
+func snippet(text string, terms []string) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	first := -1
+	for _, term := range terms {
+		if i := indexRunes(lower, []rune(term), 0); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	if first < 0 {
+		first = 0
+	}
+
+	start, end := first-snippetRadius, first+snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	for i := start; i < end; {
+		matched := 0
+		for _, term := range terms {
+			t := []rune(term)
+			if hasRunesAt(lower[:end], t, i) && len(t) > matched {
+				matched = len(t)
+			}
+		}
+		if matched == 0 {
+			b.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+		b.WriteString("<b>")
+		b.WriteString(html.EscapeString(string(runes[i : i+matched])))
+		b.WriteString("</b>")
+		i += matched
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func indexRunes(s, sub []rune, from int) int {
+	for i := from; i+len(sub) <= len(s); i++ {
+		if hasRunesAt(s, sub, i) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func hasRunesAt(s, sub []rune, at int) bool {
+	if at+len(sub) > len(s) {
+		return false
+	}
+	for j := range sub {
+		if s[at+j] != sub[j] {
+			return false
+		}
+	}
+
+	return true
+}
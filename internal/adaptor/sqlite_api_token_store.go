@@ -0,0 +1,168 @@
+package adaptor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+// SQLiteAPITokenStore хранит scopes строкой через пробел, время - в наносекундах.
+type SQLiteAPITokenStore struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+func NewSQLiteAPITokenStore(db *sql.DB, logger *zap.Logger) *SQLiteAPITokenStore {
+	return &SQLiteAPITokenStore{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (s *SQLiteAPITokenStore) CreateTable(ctx context.Context) error {
+	s.log.Debug("creating table", zap.Any("table", APITokenTable))
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %v (
+			id TEXT PRIMARY KEY NOT NULL,
+			user_id TEXT NOT NULL REFERENCES %v (id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			scopes TEXT NOT NULL,
+			token_hash BLOB NOT NULL UNIQUE,
+			created_at INTEGER NOT NULL,
+			expires_at INTEGER,
+			last_used_at INTEGER
+		)`,
+		APITokenTable, UserTable,
+	)
+	if _, err := s.db.ExecContext(ctx, query); err != nil {
+		return errors.Wrapf(err, "create table %v", APITokenTable)
+	}
+
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS api_tokens_user_id_idx ON %v (user_id)`, APITokenTable))
+
+	return errors.Wrap(err, "create api tokens index")
+}
+
+func (s *SQLiteAPITokenStore) Create(ctx context.Context, t user.APIToken) error {
+	s.log.Debug("saving api token", zap.Any("tokenID", t.ID))
+
+	query := fmt.Sprintf(`INSERT INTO %v (%v) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, APITokenTable, apiTokenColumns)
+
+	_, err := s.db.ExecContext(ctx, query,
+		t.ID.String(), t.UserID.String(), t.Name, strings.Join(t.Scopes, " "), t.Hash,
+		t.CreatedAt.UnixNano(), unixNanoOrNull(t.ExpiresAt), unixNanoOrNull(t.LastUsedAt))
+
+	return errors.Wrap(err, "save api token to database")
+}
+
+func (s *SQLiteAPITokenStore) List(ctx context.Context, userID uuid.UUID) ([]user.APIToken, error) {
+	query := fmt.Sprintf(
+		`SELECT %v FROM %v WHERE user_id = ? ORDER BY created_at DESC, id DESC`,
+		apiTokenColumns, APITokenTable,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, userID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "list api tokens")
+	}
+	defer rows.Close()
+
+	list := []user.APIToken{}
+	for rows.Next() {
+		t, err := scanSQLiteAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+
+	return list, errors.Wrap(rows.Err(), "read api tokens")
+}
+
+func (s *SQLiteAPITokenStore) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	s.log.Debug("deleting api token", zap.Any("tokenID", id))
+
+	query := fmt.Sprintf(`DELETE FROM %v WHERE id = ? AND user_id = ?`, APITokenTable)
+
+	result, err := s.db.ExecContext(ctx, query, id.String(), userID.String())
+	if err != nil {
+		return errors.Wrap(err, "delete api token")
+	}
+
+	return tokenAffected(result)
+}
+
+func (s *SQLiteAPITokenStore) GetByHash(ctx context.Context, hash []byte) (user.APIToken, error) {
+	query := fmt.Sprintf(`SELECT %v FROM %v WHERE token_hash = ?`, apiTokenColumns, APITokenTable)
+
+	t, err := scanSQLiteAPIToken(s.db.QueryRowContext(ctx, query, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.APIToken{}, tokens.NotFound
+	}
+
+	return t, err
+}
+
+func (s *SQLiteAPITokenStore) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := fmt.Sprintf(`UPDATE %v SET last_used_at = ? WHERE id = ?`, APITokenTable)
+
+	result, err := s.db.ExecContext(ctx, query, at.UnixNano(), id.String())
+	if err != nil {
+		return errors.Wrap(err, "touch api token")
+	}
+
+	return tokenAffected(result)
+}
+
+func scanSQLiteAPIToken(row interface{ Scan(...any) error }) (user.APIToken, error) {
+	var (
+		t                     user.APIToken
+		id, userID, scopes    string
+		createdAt             int64
+		expiresAt, lastUsedAt sql.NullInt64
+	)
+
+	err := row.Scan(&id, &userID, &t.Name, &scopes, &t.Hash, &createdAt, &expiresAt, &lastUsedAt)
+	if err != nil {
+		return user.APIToken{}, errors.Wrap(err, "scan api token")
+	}
+
+	if t.ID, err = uuid.FromString(id); err != nil {
+		return user.APIToken{}, errors.Wrap(err, "parse api token id")
+	}
+	if t.UserID, err = uuid.FromString(userID); err != nil {
+		return user.APIToken{}, errors.Wrap(err, "parse api token user id")
+	}
+	t.Scopes = strings.Fields(scopes)
+	t.CreatedAt = time.Unix(0, createdAt)
+	t.ExpiresAt = timeOrNil(expiresAt)
+	t.LastUsedAt = timeOrNil(lastUsedAt)
+
+	return t, nil
+}
+
+func unixNanoOrNull(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+func timeOrNil(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+
+	t := time.Unix(0, v.Int64)
+	return &t
+}
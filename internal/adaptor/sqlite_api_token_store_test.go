@@ -0,0 +1,22 @@
+package adaptor
+
+import (
+	"context"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens/storetest"
+	"go.uber.org/zap"
+)
+
+func TestSQLiteAPITokenStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, owners ...uuid.UUID) tokens.Store {
+		store := NewSQLiteAPITokenStore(openTestSQLite(t, owners...), zap.NewNop())
+		if err := store.CreateTable(context.Background()); err != nil {
+			t.Fatalf("create table: %v", err)
+		}
+
+		return store
+	})
+}
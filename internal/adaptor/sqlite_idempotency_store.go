@@ -0,0 +1,142 @@
+package adaptor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency"
+	"go.uber.org/zap"
+)
+
+// SQLiteIdempotencyStore хранит заголовки ответа JSON-строкой, время - в наносекундах.
+type SQLiteIdempotencyStore struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+func NewSQLiteIdempotencyStore(db *sql.DB, logger *zap.Logger) *SQLiteIdempotencyStore {
+	return &SQLiteIdempotencyStore{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (s *SQLiteIdempotencyStore) CreateTable(ctx context.Context) error {
+	s.log.Debug("creating table", zap.Any("table", IdempotencyTable))
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %v (
+			user_id TEXT NOT NULL REFERENCES %v (id) ON DELETE CASCADE,
+			key TEXT NOT NULL,
+			request_hash BLOB NOT NULL,
+			status INTEGER,
+			headers TEXT,
+			body BLOB,
+			created_at INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL,
+			PRIMARY KEY (user_id, key)
+		)`,
+		IdempotencyTable, UserTable,
+	)
+	if _, err := s.db.ExecContext(ctx, query); err != nil {
+		return errors.Wrapf(err, "create table %v", IdempotencyTable)
+	}
+
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON %v (expires_at)`, IdempotencyTable))
+
+	return errors.Wrap(err, "create idempotency keys index")
+}
+
+func (s *SQLiteIdempotencyStore) Reserve(ctx context.Context, record idempotency.Record) error {
+	s.log.Debug("reserving idempotency key", zap.String("key", record.Key))
+
+	query := fmt.Sprintf(
+		`INSERT INTO %[1]v (user_id, key, request_hash, created_at, expires_at) VALUES (?1, ?2, ?3, ?4, ?5)
+		ON CONFLICT (user_id, key) DO UPDATE SET
+			request_hash = excluded.request_hash, status = NULL, headers = NULL, body = NULL,
+			created_at = excluded.created_at, expires_at = excluded.expires_at
+		WHERE %[1]v.expires_at <= excluded.created_at`,
+		IdempotencyTable,
+	)
+
+	result, err := s.db.ExecContext(ctx, query, record.UserID.String(), record.Key, record.RequestHash,
+		record.CreatedAt.UnixNano(), record.ExpiresAt.UnixNano())
+	if err != nil {
+		return errors.Wrap(err, "reserve idempotency key")
+	}
+
+	return keyReserved(result)
+}
+
+func (s *SQLiteIdempotencyStore) Get(ctx context.Context, userID uuid.UUID, key string) (idempotency.Record, error) {
+	query := fmt.Sprintf(`SELECT %v FROM %v WHERE user_id = ? AND key = ?`, idempotencyColumns, IdempotencyTable)
+
+	var (
+		r                    idempotency.Record
+		id                   string
+		status               sql.NullInt64
+		headers              sql.NullString
+		body                 []byte
+		createdAt, expiresAt int64
+	)
+	err := s.db.QueryRowContext(ctx, query, userID.String(), key).
+		Scan(&id, &r.Key, &r.RequestHash, &status, &headers, &body, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return idempotency.Record{}, idempotency.NotFound
+	}
+	if err != nil {
+		return idempotency.Record{}, errors.Wrap(err, "get idempotency key")
+	}
+
+	r.UserID, err = uuid.FromString(id)
+	if err != nil {
+		return idempotency.Record{}, errors.Wrap(err, "parse user id")
+	}
+	r.CreatedAt, r.ExpiresAt = time.Unix(0, createdAt).UTC(), time.Unix(0, expiresAt).UTC()
+	r.Response, err = storedResponse(status, []byte(headers.String), body)
+
+	return r, err
+}
+
+func (s *SQLiteIdempotencyStore) Complete(ctx context.Context, userID uuid.UUID, key string, response idempotency.Response) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return errors.Wrap(err, "marshal response headers")
+	}
+
+	query := fmt.Sprintf(`UPDATE %v SET status = ?3, headers = ?4, body = ?5 WHERE user_id = ?1 AND key = ?2`, IdempotencyTable)
+
+	result, err := s.db.ExecContext(ctx, query, userID.String(), key, response.Status, string(headers), response.Body)
+	if err != nil {
+		return errors.Wrap(err, "complete idempotency key")
+	}
+
+	return keyAffected(result)
+}
+
+func (s *SQLiteIdempotencyStore) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	query := fmt.Sprintf(`DELETE FROM %v WHERE user_id = ? AND key = ? AND status IS NULL`, IdempotencyTable)
+
+	result, err := s.db.ExecContext(ctx, query, userID.String(), key)
+	if err != nil {
+		return errors.Wrap(err, "release idempotency key")
+	}
+
+	return keyAffected(result)
+}
+
+func (s *SQLiteIdempotencyStore) PurgeExpired(ctx context.Context, before time.Time) (uint, error) {
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %v WHERE expires_at <= ?`, IdempotencyTable), before.UnixNano())
+	if err != nil {
+		return 0, errors.Wrap(err, "purge idempotency keys")
+	}
+
+	count, err := result.RowsAffected()
+
+	return uint(count), errors.Wrap(err, "rows affected")
+}
@@ -0,0 +1,1244 @@
+package adaptor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteNoteStore хранит заметки во встроенной базе SQLite.
+// Теги лежат в JSON-массиве, created_at - в наносекундах Unix.
+type SQLiteNoteStore struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+func NewSQLiteNoteStore(db *sql.DB, logger *zap.Logger) *SQLiteNoteStore {
+	return &SQLiteNoteStore{
+		db:  db,
+		log: logger,
+	}
+}
+
+// CreateTable создаёт схему. Миграции из пакета migrations рассчитаны на postgres,
+// поэтому SQLite поднимает свою схему сам при старте.
+func (s *SQLiteNoteStore) CreateTable(ctx context.Context) error {
+	s.log.Debug("creating table", zap.Any("table", NoteTable))
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %v (
+			id TEXT PRIMARY KEY NOT NULL,
+			owner_id TEXT,
+			label TEXT NOT NULL,
+			body TEXT,
+			tags TEXT,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER,
+			version INTEGER NOT NULL DEFAULT 1,
+			deleted_at INTEGER
+		)`,
+		NoteTable,
+	)
+	_, err := s.db.ExecContext(ctx, query)
+	if err != nil {
+		return errors.Wrapf(err, "create table %v", NoteTable)
+	}
+
+	// Базы, созданные до появления владельцев, получают колонку без значения:
+	// такие заметки никому не видны.
+	err = addSQLiteColumn(ctx, s.db, NoteTable, "owner_id", "TEXT")
+	if err != nil {
+		return err
+	}
+
+	err = addSQLiteColumn(ctx, s.db, NoteTable, "version", "INTEGER NOT NULL DEFAULT 1")
+	if err != nil {
+		return err
+	}
+
+	// Заметки, которые не менялись с момента появления колонки, считаются
+	// изменёнными в момент создания.
+	err = addSQLiteColumn(ctx, s.db, NoteTable, "updated_at", "INTEGER")
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf(`UPDATE %v SET updated_at = created_at WHERE updated_at IS NULL`, NoteTable))
+	if err != nil {
+		return errors.Wrap(err, "backfill updated_at")
+	}
+
+	err = addSQLiteColumn(ctx, s.db, NoteTable, "deleted_at", "INTEGER")
+	if err != nil {
+		return err
+	}
+
+	err = addSQLiteColumn(ctx, s.db, NoteTable, "notebook_id", "TEXT")
+	if err != nil {
+		return err
+	}
+
+	for _, column := range []string{"pinned", "archived", "favorite"} {
+		err = addSQLiteColumn(ctx, s.db, NoteTable, column, "INTEGER NOT NULL DEFAULT 0")
+		if err != nil {
+			return err
+		}
+	}
+
+	query = fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %v (
+			id TEXT PRIMARY KEY NOT NULL,
+			owner_id TEXT NOT NULL,
+			parent_id TEXT,
+			name TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL
+		)`,
+		NotebookTable,
+	)
+	if _, err = s.db.ExecContext(ctx, query); err != nil {
+		return errors.Wrapf(err, "create table %v", NotebookTable)
+	}
+
+	for _, index := range []string{
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS notebooks_owner_id_idx ON %v (owner_id)`, NotebookTable),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS notebooks_parent_id_idx ON %v (parent_id)`, NotebookTable),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS notes_notebook_id_idx ON %v (notebook_id)`, NoteTable),
+	} {
+		if _, err = s.db.ExecContext(ctx, index); err != nil {
+			return errors.Wrap(err, "create notebook index")
+		}
+	}
+
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS notes_owner_id_idx ON %v (owner_id)`, NoteTable))
+	if err != nil {
+		return errors.Wrap(err, "create owner index")
+	}
+
+	query = fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %v (
+			note_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			permission TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (note_id, user_id)
+		)`,
+		ShareTable,
+	)
+	if _, err = s.db.ExecContext(ctx, query); err != nil {
+		return errors.Wrapf(err, "create table %v", ShareTable)
+	}
+
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS note_shares_user_id_idx ON %v (user_id)`, ShareTable))
+	if err != nil {
+		return errors.Wrap(err, "create shares index")
+	}
+
+	query = fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %v (
+			note_id TEXT PRIMARY KEY NOT NULL,
+			token TEXT NOT NULL UNIQUE,
+			created_at INTEGER NOT NULL,
+			expires_at INTEGER
+		)`,
+		LinkTable,
+	)
+	if _, err = s.db.ExecContext(ctx, query); err != nil {
+		return errors.Wrapf(err, "create table %v", LinkTable)
+	}
+
+	query = fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %v (
+			note_id TEXT NOT NULL,
+			rev INTEGER NOT NULL,
+			label TEXT NOT NULL,
+			body TEXT NOT NULL,
+			tags TEXT,
+			author_id TEXT,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (note_id, rev)
+		)`,
+		RevisionTable,
+	)
+	if _, err = s.db.ExecContext(ctx, query); err != nil {
+		return errors.Wrapf(err, "create table %v", RevisionTable)
+	}
+
+	// Заметки, созданные до появления истории, получают первую ревизию с
+	// текущим содержимым.
+	query = fmt.Sprintf(
+		`INSERT INTO %[1]v (note_id, rev, label, body, tags, author_id, created_at)
+		SELECT id, 1, label, COALESCE(body, ''), tags, owner_id, created_at FROM %[2]v
+		WHERE id NOT IN (SELECT note_id FROM %[1]v)`,
+		RevisionTable, NoteTable,
+	)
+	_, err = s.db.ExecContext(ctx, query)
+
+	return errors.Wrap(err, "backfill note revisions")
+}
+
+// addSQLiteColumn добавляет колонку, если её ещё нет. SQLite не поддерживает
+// ADD COLUMN IF NOT EXISTS.
+func addSQLiteColumn(ctx context.Context, db *sql.DB, table, column, definition string) error {
+	var count int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		return errors.Wrapf(err, "inspect table %v", table)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %v ADD COLUMN %v %v`, table, column, definition))
+
+	return errors.Wrapf(err, "add column %v.%v", table, column)
+}
+
+func (s *SQLiteNoteStore) Create(ctx context.Context, n note.Note) error {
+	s.log.Debug("saving note", zap.Any("note", n))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := s.createNote(ctx, tx, n); err != nil {
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "commit note")
+}
+
+func (s *SQLiteNoteStore) createNote(ctx context.Context, tx *sql.Tx, n note.Note) error {
+	tags, err := marshalTags(n.Tags)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		`INSERT INTO %v (id, owner_id, label, body, tags, created_at, updated_at, notebook_id, pinned, archived, favorite)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6, ?7, ?8, ?9, ?10)`,
+		NoteTable,
+	)
+	_, err = tx.ExecContext(ctx, query, n.ID.String(), n.OwnerID.String(), n.Label, n.Body, tags, n.CreatedAt.UnixNano(), nullUUID(n.NotebookID),
+		n.Pinned, n.Archived, n.Favorite)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return notes.ErrAlreadyExists.WithCause(err)
+	}
+	if err != nil {
+		return errors.Wrap(err, "save note to database")
+	}
+
+	return insertSQLiteRevision(ctx, tx, n.ID, n.OwnerID, n.CreatedAt)
+}
+
+func (s *SQLiteNoteStore) Update(ctx context.Context, args notes.UpdateArgs) error {
+	return s.Patch(ctx, args.Patch())
+}
+
+func (s *SQLiteNoteStore) Patch(ctx context.Context, args notes.PatchArgs) error {
+	s.log.Debug("updating note", zap.Any("args", args))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := s.patchNote(ctx, tx, args); err != nil {
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "commit note")
+}
+
+func (s *SQLiteNoteStore) patchNote(ctx context.Context, tx *sql.Tx, args notes.PatchArgs) error {
+	sets, params := []string{}, []any{}
+	set := func(column string, value any) {
+		params = append(params, value)
+		sets = append(sets, fmt.Sprintf("%v = ?%v", column, len(params)))
+	}
+	if args.Label != nil {
+		set("label", *args.Label)
+	}
+	if args.Body != nil {
+		set("body", *args.Body)
+	}
+	if args.Tags != nil {
+		tags, err := marshalTags(*args.Tags)
+		if err != nil {
+			return err
+		}
+		set("tags", tags)
+	}
+	set("updated_at", args.UpdatedAt.UnixNano())
+	params = append(params, args.ID.String(), args.Version)
+
+	query := fmt.Sprintf(
+		`UPDATE %v SET %v, version = version + 1
+		WHERE id = ?%v AND (?%v = 0 OR version = ?%[4]v) AND deleted_at IS NULL`,
+		NoteTable, strings.Join(sets, ", "), len(params)-1, len(params),
+	)
+	result, err := tx.ExecContext(ctx, query, params...)
+	if err != nil {
+		return errors.Wrap(err, "update note to database")
+	}
+	if notFoundIfNoRows(result) != nil {
+		return noteMissingOrChanged(ctx, tx, `id = ?`, args.ID.String())
+	}
+
+	return insertSQLiteRevision(ctx, tx, args.ID, args.UserID, args.UpdatedAt)
+}
+
+// insertSQLiteRevision сохраняет текущее содержимое заметки следующей ревизией.
+func insertSQLiteRevision(ctx context.Context, tx *sql.Tx, noteID, authorID uuid.UUID, at time.Time) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %[1]v (note_id, rev, label, body, tags, author_id, created_at)
+		SELECT id, COALESCE((SELECT MAX(rev) FROM %[1]v WHERE note_id = ?1), 0) + 1, label, COALESCE(body, ''), tags, ?2, ?3
+		FROM %[2]v WHERE id = ?1`,
+		RevisionTable, NoteTable,
+	)
+
+	author := sql.NullString{String: authorID.String(), Valid: authorID != uuid.Nil}
+	_, err := tx.ExecContext(ctx, query, noteID.String(), author, at.UnixNano())
+
+	return errors.Wrap(err, "save note revision")
+}
+
+func (s *SQLiteNoteStore) Delete(ctx context.Context, ownerID uuid.UUID, targets []notes.DeleteTarget, deletedAt time.Time) error {
+	s.log.Debug("deleting notes", zap.Any("targets", targets))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	for _, target := range targets {
+		err := deleteSQLiteNote(ctx, tx, ownerID, target.ID, target.Version, deletedAt)
+		if err != nil && !errors.Is(err, notes.NotFound) {
+			return err
+		}
+	}
+
+	return errors.Wrap(tx.Commit(), "commit delete notes")
+}
+
+func (s *SQLiteNoteStore) DeleteOne(ctx context.Context, ownerID, id uuid.UUID, version uint, deletedAt time.Time) error {
+	s.log.Debug("deleting note", zap.Any("noteID", id), zap.Uint("version", version))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := deleteSQLiteNote(ctx, tx, ownerID, id, version, deletedAt); err != nil {
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "commit delete note")
+}
+
+func deleteSQLiteNote(ctx context.Context, tx *sql.Tx, ownerID, id uuid.UUID, version uint, deletedAt time.Time) error {
+	query := fmt.Sprintf(
+		`UPDATE %v SET deleted_at = ?4
+		WHERE id = ?1 AND owner_id = ?2 AND (?3 = 0 OR version = ?3) AND deleted_at IS NULL`,
+		NoteTable,
+	)
+	result, err := tx.ExecContext(ctx, query, id.String(), ownerID.String(), version, deletedAt.UnixNano())
+	if err != nil {
+		return errors.Wrap(err, "delete note")
+	}
+	if notFoundIfNoRows(result) != nil {
+		return noteMissingOrChanged(ctx, tx, `id = ? AND owner_id = ?`, id.String(), ownerID.String())
+	}
+
+	return nil
+}
+
+// Batch выполняет изменения в одной транзакции, как NoteStore.Batch.
+func (s *SQLiteNoteStore) Batch(ctx context.Context, writes []notes.BatchWrite, continueOnError bool) ([]error, error) {
+	s.log.Debug("applying batch", zap.Int("count", len(writes)), zap.Bool("continueOnError", continueOnError))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	apply := func(write notes.BatchWrite) error {
+		switch {
+		case write.Create != nil:
+			return s.createNote(ctx, tx, *write.Create)
+		case write.Patch != nil:
+			return s.patchNote(ctx, tx, *write.Patch)
+		case write.Delete != nil:
+			d := write.Delete
+			return deleteSQLiteNote(ctx, tx, d.OwnerID, d.ID, d.Version, d.DeletedAt)
+		}
+		return errors.New("empty batch write")
+	}
+
+	errs, err := applyBatch(ctx, tx, writes, continueOnError, apply)
+	if err != nil || !committable(errs, continueOnError) {
+		return errs, err
+	}
+
+	return errs, errors.Wrap(tx.Commit(), "commit batch")
+}
+
+func (s *SQLiteNoteStore) Restore(ctx context.Context, ownerID, id uuid.UUID) error {
+	s.log.Debug("restoring note", zap.Any("noteID", id))
+
+	query := fmt.Sprintf(
+		`UPDATE %v SET deleted_at = NULL WHERE id = ? AND owner_id = ? AND deleted_at IS NOT NULL`,
+		NoteTable,
+	)
+	result, err := s.db.ExecContext(ctx, query, id.String(), ownerID.String())
+	if err != nil {
+		return errors.Wrap(err, "restore note")
+	}
+
+	return notFoundIfNoRows(result)
+}
+
+func (s *SQLiteNoteStore) Purge(ctx context.Context, ownerID, id uuid.UUID) error {
+	s.log.Debug("purging note", zap.Any("noteID", id))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`DELETE FROM %v WHERE id = ? AND owner_id = ? AND deleted_at IS NOT NULL`, NoteTable)
+	result, err := tx.ExecContext(ctx, query, id.String(), ownerID.String())
+	if err != nil {
+		return errors.Wrap(err, "purge note")
+	}
+	if err := notFoundIfNoRows(result); err != nil {
+		return err
+	}
+	if err := deleteSQLiteNoteRows(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "commit purge note")
+}
+
+func (s *SQLiteNoteStore) PurgeDeleted(ctx context.Context, before time.Time) (uint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT id FROM %v WHERE deleted_at < ?`, NoteTable), before.UnixNano())
+	if err != nil {
+		return 0, errors.Wrap(err, "select deleted notes")
+	}
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, errors.Wrap(err, "scan deleted note id")
+		}
+		ids = append(ids, uuid.FromStringOrNil(id))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "read deleted notes")
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %v WHERE id = ?`, NoteTable)
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, query, id.String()); err != nil {
+			return 0, errors.Wrap(err, "purge deleted note")
+		}
+		if err := deleteSQLiteNoteRows(ctx, tx, id); err != nil {
+			return 0, err
+		}
+	}
+
+	return uint(len(ids)), errors.Wrap(tx.Commit(), "commit purge deleted notes")
+}
+
+// deleteSQLiteNoteRows удаляет права, ссылки и ревизии стёртой заметки.
+// Внешние ключи в SQLite выключены по умолчанию, поэтому это делается явно.
+func deleteSQLiteNoteRows(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	for _, table := range []string{ShareTable, LinkTable, RevisionTable} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %v WHERE note_id = ?`, table), id.String()); err != nil {
+			return errors.Wrapf(err, "delete from %v", table)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLiteNoteStore) GetByID(ctx context.Context, id uuid.UUID) (note.Note, error) {
+	s.log.Debug("getting note by ID", zap.Any("noteID", id))
+
+	query := fmt.Sprintf(
+		`SELECT %v FROM %v WHERE id = ? AND deleted_at IS NULL`,
+		noteColumns, NoteTable,
+	)
+
+	n, err := scanSQLiteNote(s.db.QueryRowContext(ctx, query, id.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Note{}, notes.NotFound
+	}
+	if err != nil {
+		return note.Note{}, errors.WithMessage(err, "failed during get note by ID")
+	}
+
+	return n, nil
+}
+
+func (s *SQLiteNoteStore) Query(ctx context.Context, args notes.ListArgs) ([]note.Note, error) {
+	s.log.Debug("getting notes with pagination and order", zap.Any("args", args))
+
+	order, _, offset := orderAndPagination(args)
+	limit := -1
+	if args.Limit > 0 {
+		limit = int(args.Limit)
+	}
+
+	conditions, params := sqliteConditions(args.Filter, nil)
+	if args.Keyset != nil {
+		var keyset string
+		keyset, params = sqliteKeyset(args, params)
+		conditions = append(conditions, keyset)
+	}
+	where := whereClause(conditions)
+
+	query := fmt.Sprintf(
+		`SELECT %v FROM %v %v ORDER BY %v LIMIT %v OFFSET %v`,
+		noteColumns, NoteTable, where, order, limit, offset,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return []note.Note{}, errors.Wrap(err, "failed during get notes")
+	}
+	defer rows.Close()
+
+	result := []note.Note{}
+	for rows.Next() {
+		n, err := scanSQLiteNote(rows)
+		if err != nil {
+			return []note.Note{}, err
+		}
+		result = append(result, n)
+	}
+	if err := rows.Err(); err != nil {
+		return []note.Note{}, errors.Wrap(err, "failed during read notes")
+	}
+
+	if len(result) == 0 {
+		return []note.Note{}, notes.NotFound
+	}
+
+	return result, nil
+}
+
+func (s *SQLiteNoteStore) Count(ctx context.Context, filter notes.Filter) (uint, error) {
+	s.log.Debug("counting notes", zap.Any("filter", filter))
+
+	where, params := sqliteWhere(filter, nil)
+
+	var count uint
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %v %v`, NoteTable, where), params...).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "count notes")
+	}
+
+	return count, nil
+}
+
+func (s *SQLiteNoteStore) ListTags(ctx context.Context, ownerID uuid.UUID) ([]note.Tag, error) {
+	s.log.Debug("listing tags", zap.Any("ownerID", ownerID))
+
+	query := fmt.Sprintf(
+		`SELECT tag.value, COUNT(DISTINCT n.id)
+		FROM %v n, json_each(n.tags) tag
+		WHERE n.owner_id = ? AND n.deleted_at IS NULL
+		GROUP BY tag.value`,
+		NoteTable,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, ownerID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "list tags")
+	}
+	defer rows.Close()
+
+	tags := []note.Tag{}
+	for rows.Next() {
+		tag := note.Tag{}
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, errors.Wrap(err, "scan tag")
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "read tags")
+	}
+	sortTags(tags)
+
+	return tags, nil
+}
+
+func (s *SQLiteNoteStore) RenameTag(ctx context.Context, ownerID uuid.UUID, from, to string) (uint, error) {
+	s.log.Debug("renaming tag", zap.String("from", from), zap.String("to", to))
+
+	return s.rewriteTags(ctx, notes.Filter{OwnerID: ownerID, TagsAll: []string{from}}, func(tags []string) ([]string, bool) {
+		return renameTag(tags, from, to)
+	})
+}
+
+func (s *SQLiteNoteStore) DeleteTag(ctx context.Context, ownerID uuid.UUID, tag string) (uint, error) {
+	s.log.Debug("deleting tag", zap.String("tag", tag))
+
+	return s.rewriteTags(ctx, notes.Filter{OwnerID: ownerID, TagsAll: []string{tag}}, func(tags []string) ([]string, bool) {
+		return removeTag(tags, tag)
+	})
+}
+
+// rewriteTags переписывает теги всех заметок, подходящих под filter, в одной
+// транзакции и сохраняет каждую изменённую заметку ревизией от имени владельца.
+func (s *SQLiteNoteStore) rewriteTags(ctx context.Context, filter notes.Filter, rewrite func(tags []string) ([]string, bool)) (uint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	where, params := sqliteWhere(filter, nil)
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT %v FROM %v %v`, noteColumns, NoteTable, where), params...)
+	if err != nil {
+		return 0, errors.Wrap(err, "select notes with tag")
+	}
+
+	list := []note.Note{}
+	for rows.Next() {
+		n, err := scanSQLiteNote(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		list = append(list, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "read notes with tag")
+	}
+
+	var count uint
+	now := time.Now()
+	for _, n := range list {
+		tags, changed := rewrite(n.Tags)
+		if !changed {
+			continue
+		}
+		data, err := marshalTags(tags)
+		if err != nil {
+			return 0, err
+		}
+		query := fmt.Sprintf(`UPDATE %v SET tags = ?, updated_at = ?, version = version + 1 WHERE id = ?`, NoteTable)
+		_, err = tx.ExecContext(ctx, query, data, now.UnixNano(), n.ID.String())
+		if err != nil {
+			return 0, errors.Wrap(err, "update note tags")
+		}
+		if err := insertSQLiteRevision(ctx, tx, n.ID, filter.OwnerID, now); err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	return count, errors.Wrap(tx.Commit(), "commit tags rewrite")
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSQLiteNote(row rowScanner) (note.Note, error) {
+	var (
+		id         string
+		ownerID    string
+		body       sql.NullString
+		tags       sql.NullString
+		createdAt  int64
+		updatedAt  int64
+		deletedAt  sql.NullInt64
+		notebookID sql.NullString
+		n          note.Note
+	)
+
+	err := row.Scan(&id, &ownerID, &n.Label, &body, &tags, &createdAt, &updatedAt, &n.Version, &deletedAt, &notebookID, &n.Pinned, &n.Archived, &n.Favorite)
+	if err != nil {
+		return note.Note{}, errors.Wrap(err, "scan note")
+	}
+
+	n.ID, err = uuid.FromString(id)
+	if err != nil {
+		return note.Note{}, errors.Wrap(err, "parse note id")
+	}
+	n.OwnerID, err = uuid.FromString(ownerID)
+	if err != nil {
+		return note.Note{}, errors.Wrap(err, "parse note owner id")
+	}
+	n.Body = body.String
+	n.CreatedAt = time.Unix(0, createdAt)
+	n.UpdatedAt = time.Unix(0, updatedAt)
+	n.DeletedAt = timeOrNil(deletedAt)
+	n.NotebookID, err = uuidOrNil(notebookID)
+	if err != nil {
+		return note.Note{}, errors.Wrap(err, "parse note notebook id")
+	}
+
+	if tags.Valid {
+		if err := json.Unmarshal([]byte(tags.String), &n.Tags); err != nil {
+			return note.Note{}, errors.Wrap(err, "parse note tags")
+		}
+	}
+
+	return n, nil
+}
+
+func marshalTags(tags []string) (sql.NullString, error) {
+	if tags == nil {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return sql.NullString{}, errors.Wrap(err, "marshal tags")
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// nullUUID пустой указатель записывается в SQLite как NULL.
+func nullUUID(id *uuid.UUID) sql.NullString {
+	if id == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: id.String(), Valid: true}
+}
+
+func uuidOrNil(v sql.NullString) (*uuid.UUID, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+
+	id, err := uuid.FromString(v.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+// Search в SQLite выполняется в памяти по всем заметкам: встроенная база
+// предназначена для локального запуска, где заметок немного.
+func (s *SQLiteNoteStore) Search(ctx context.Context, args notes.SearchArgs) ([]note.FoundNote, error) {
+	s.log.Debug("searching notes", zap.Any("args", args))
+
+	list, err := s.all(ctx, args.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginateFound(searchNotes(list, args), args.Offset, args.Limit), nil
+}
+
+func (s *SQLiteNoteStore) SearchCount(ctx context.Context, args notes.SearchArgs) (uint, error) {
+	list, err := s.all(ctx, args.OwnerID)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(len(searchNotes(list, args))), nil
+}
+
+func (s *SQLiteNoteStore) all(ctx context.Context, ownerID uuid.UUID) ([]note.Note, error) {
+	list, err := s.Query(ctx, notes.ListArgs{Filter: notes.Filter{OwnerID: ownerID}})
+	if errors.Is(err, notes.NotFound) {
+		return []note.Note{}, nil
+	}
+
+	return list, err
+}
+
+func (s *SQLiteNoteStore) Access(ctx context.Context, userID, noteID uuid.UUID) (note.Permission, error) {
+	query := fmt.Sprintf(
+		`SELECT CASE WHEN n.owner_id = ?1 THEN '%v' ELSE sh.permission END
+		FROM %v n
+		LEFT JOIN %v sh ON sh.note_id = n.id AND sh.user_id = ?1
+		WHERE n.id = ?2 AND n.deleted_at IS NULL AND (n.owner_id = ?1 OR sh.user_id IS NOT NULL)`,
+		note.PermissionOwner, NoteTable, ShareTable,
+	)
+
+	var permission note.Permission
+	err := s.db.QueryRowContext(ctx, query, userID.String(), noteID.String()).Scan(&permission)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", notes.NotFound
+	}
+
+	return permission, errors.Wrap(err, "check note access")
+}
+
+func (s *SQLiteNoteStore) Share(ctx context.Context, share note.Share) error {
+	s.log.Debug("sharing note", zap.Any("share", share))
+
+	if err := s.noteExists(ctx, share.NoteID); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		`INSERT INTO %v (note_id, user_id, permission, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (note_id, user_id) DO UPDATE SET permission = excluded.permission`,
+		ShareTable,
+	)
+	_, err := s.db.ExecContext(ctx, query, share.NoteID.String(), share.UserID.String(), share.Permission, share.CreatedAt.UnixNano())
+
+	return errors.Wrap(err, "share note")
+}
+
+func (s *SQLiteNoteStore) Unshare(ctx context.Context, noteID, userID uuid.UUID) error {
+	s.log.Debug("unsharing note", zap.Any("noteID", noteID), zap.Any("userID", userID))
+
+	query := fmt.Sprintf(`DELETE FROM %v WHERE note_id = ? AND user_id = ?`, ShareTable)
+
+	result, err := s.db.ExecContext(ctx, query, noteID.String(), userID.String())
+	if err != nil {
+		return errors.Wrap(err, "unshare note")
+	}
+	if err := notFoundIfNoRows(result); err != nil {
+		return notes.ErrShareNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLiteNoteStore) ListShares(ctx context.Context, noteID uuid.UUID) ([]note.Share, error) {
+	query := fmt.Sprintf(
+		`SELECT user_id, permission, created_at FROM %v WHERE note_id = ? ORDER BY created_at, user_id`,
+		ShareTable,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, noteID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "list shares")
+	}
+	defer rows.Close()
+
+	list := []note.Share{}
+	for rows.Next() {
+		var (
+			userID    string
+			createdAt int64
+		)
+		share := note.Share{NoteID: noteID}
+		if err := rows.Scan(&userID, &share.Permission, &createdAt); err != nil {
+			return nil, errors.Wrap(err, "scan share")
+		}
+		if share.UserID, err = uuid.FromString(userID); err != nil {
+			return nil, errors.Wrap(err, "parse share user id")
+		}
+		share.CreatedAt = time.Unix(0, createdAt)
+		list = append(list, share)
+	}
+
+	return list, errors.Wrap(rows.Err(), "read shares")
+}
+
+func (s *SQLiteNoteStore) SaveLink(ctx context.Context, link note.Link) error {
+	s.log.Debug("saving link", zap.Any("noteID", link.NoteID))
+
+	if err := s.noteExists(ctx, link.NoteID); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		`INSERT INTO %v (note_id, token, created_at, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (note_id) DO UPDATE SET token = excluded.token, created_at = excluded.created_at, expires_at = excluded.expires_at`,
+		LinkTable,
+	)
+	_, err := s.db.ExecContext(ctx, query,
+		link.NoteID.String(), link.Token, link.CreatedAt.UnixNano(), unixNanoOrNull(link.ExpiresAt))
+
+	return errors.Wrap(err, "save link")
+}
+
+func (s *SQLiteNoteStore) GetLink(ctx context.Context, noteID uuid.UUID) (note.Link, error) {
+	return s.getLink(ctx, "l.note_id = ?", noteID.String())
+}
+
+func (s *SQLiteNoteStore) GetLinkByToken(ctx context.Context, token string) (note.Link, error) {
+	return s.getLink(ctx, "l.token = ?", token)
+}
+
+func (s *SQLiteNoteStore) getLink(ctx context.Context, condition string, param any) (note.Link, error) {
+	query := fmt.Sprintf(
+		`SELECT l.note_id, n.owner_id, l.token, l.created_at, l.expires_at
+		FROM %v l JOIN %v n ON n.id = l.note_id
+		WHERE %v AND n.deleted_at IS NULL`,
+		LinkTable, NoteTable, condition,
+	)
+
+	var (
+		link            note.Link
+		noteID, ownerID string
+		createdAt       int64
+		expiresAt       sql.NullInt64
+	)
+	err := s.db.QueryRowContext(ctx, query, param).Scan(&noteID, &ownerID, &link.Token, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Link{}, notes.ErrLinkNotFound
+	}
+	if err != nil {
+		return note.Link{}, errors.Wrap(err, "get link")
+	}
+
+	if link.NoteID, err = uuid.FromString(noteID); err != nil {
+		return note.Link{}, errors.Wrap(err, "parse link note id")
+	}
+	if link.OwnerID, err = uuid.FromString(ownerID); err != nil {
+		return note.Link{}, errors.Wrap(err, "parse link owner id")
+	}
+	link.CreatedAt = time.Unix(0, createdAt)
+	link.ExpiresAt = timeOrNil(expiresAt)
+
+	return link, nil
+}
+
+func (s *SQLiteNoteStore) DeleteLink(ctx context.Context, noteID uuid.UUID) error {
+	s.log.Debug("deleting link", zap.Any("noteID", noteID))
+
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %v WHERE note_id = ?`, LinkTable), noteID.String())
+	if err != nil {
+		return errors.Wrap(err, "delete link")
+	}
+	if err := notFoundIfNoRows(result); err != nil {
+		return notes.ErrLinkNotFound
+	}
+
+	return nil
+}
+
+// noteExists возвращает notes.NotFound, если заметки нет или она в корзине.
+// Заменяет внешний ключ, который SQLite по умолчанию не проверяет.
+func (s *SQLiteNoteStore) noteExists(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %v WHERE id = ? AND deleted_at IS NULL)`, NoteTable)
+	err := s.db.QueryRowContext(ctx, query, id.String()).Scan(&exists)
+	if err != nil {
+		return errors.Wrap(err, "check note exists")
+	}
+	if !exists {
+		return notes.NotFound
+	}
+
+	return nil
+}
+
+func (s *SQLiteNoteStore) ListRevisions(ctx context.Context, noteID uuid.UUID) ([]note.Revision, error) {
+	query := fmt.Sprintf(
+		`SELECT %v FROM %v WHERE note_id = ? ORDER BY rev DESC`,
+		revisionColumns, RevisionTable,
+	)
+
+	rows, err := s.db.QueryContext(ctx, query, noteID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "list revisions")
+	}
+	defer rows.Close()
+
+	list := []note.Revision{}
+	for rows.Next() {
+		revision, err := scanSQLiteRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, revision)
+	}
+
+	return list, errors.Wrap(rows.Err(), "read revisions")
+}
+
+func (s *SQLiteNoteStore) GetRevision(ctx context.Context, noteID uuid.UUID, rev uint) (note.Revision, error) {
+	query := fmt.Sprintf(
+		`SELECT %v FROM %v WHERE note_id = ? AND rev = ?`,
+		revisionColumns, RevisionTable,
+	)
+
+	revision, err := scanSQLiteRevision(s.db.QueryRowContext(ctx, query, noteID.String(), rev))
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Revision{}, notes.ErrRevisionNotFound
+	}
+
+	return revision, err
+}
+
+func scanSQLiteRevision(row rowScanner) (note.Revision, error) {
+	var (
+		noteID    string
+		authorID  sql.NullString
+		tags      sql.NullString
+		createdAt int64
+		revision  note.Revision
+	)
+
+	err := row.Scan(&noteID, &revision.Rev, &revision.Label, &revision.Body, &tags, &authorID, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Revision{}, err
+	}
+	if err != nil {
+		return note.Revision{}, errors.Wrap(err, "scan revision")
+	}
+
+	revision.NoteID, err = uuid.FromString(noteID)
+	if err != nil {
+		return note.Revision{}, errors.Wrap(err, "parse revision note id")
+	}
+	if authorID.Valid {
+		// Автор может быть пустым у заметок без владельца.
+		revision.AuthorID = uuid.FromStringOrNil(authorID.String)
+	}
+	if tags.Valid {
+		if err := json.Unmarshal([]byte(tags.String), &revision.Tags); err != nil {
+			return note.Revision{}, errors.Wrap(err, "parse revision tags")
+		}
+	}
+	revision.CreatedAt = time.Unix(0, createdAt)
+
+	return revision, nil
+}
+
+func (s *SQLiteNoteStore) CreateNotebook(ctx context.Context, notebook note.Notebook) error {
+	s.log.Debug("saving notebook", zap.Any("notebook", notebook))
+
+	query := fmt.Sprintf(`INSERT INTO %v (%v) VALUES (?, ?, ?, ?, ?, ?)`, NotebookTable, notebookColumns)
+	_, err := s.db.ExecContext(ctx, query, notebook.ID.String(), notebook.OwnerID.String(), nullUUID(notebook.ParentID),
+		notebook.Name, notebook.CreatedAt.UnixNano(), notebook.UpdatedAt.UnixNano())
+
+	return errors.Wrap(err, "save notebook")
+}
+
+func (s *SQLiteNoteStore) GetNotebook(ctx context.Context, ownerID, id uuid.UUID) (note.Notebook, error) {
+	query := fmt.Sprintf(`SELECT %v FROM %v WHERE id = ? AND owner_id = ?`, notebookColumns, NotebookTable)
+
+	notebook, err := scanSQLiteNotebook(s.db.QueryRowContext(ctx, query, id.String(), ownerID.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Notebook{}, notes.ErrNotebookNotFound
+	}
+
+	return notebook, err
+}
+
+func (s *SQLiteNoteStore) ListNotebooks(ctx context.Context, ownerID uuid.UUID) ([]note.Notebook, error) {
+	query := fmt.Sprintf(`SELECT %v FROM %v WHERE owner_id = ? ORDER BY name, id`, notebookColumns, NotebookTable)
+
+	rows, err := s.db.QueryContext(ctx, query, ownerID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "list notebooks")
+	}
+	defer rows.Close()
+
+	list := []note.Notebook{}
+	for rows.Next() {
+		notebook, err := scanSQLiteNotebook(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, notebook)
+	}
+
+	return list, errors.Wrap(rows.Err(), "read notebooks")
+}
+
+func (s *SQLiteNoteStore) UpdateNotebook(ctx context.Context, notebook note.Notebook) error {
+	s.log.Debug("updating notebook", zap.Any("notebook", notebook))
+
+	query := fmt.Sprintf(`UPDATE %v SET name = ?, parent_id = ?, updated_at = ? WHERE id = ? AND owner_id = ?`, NotebookTable)
+	result, err := s.db.ExecContext(ctx, query, notebook.Name, nullUUID(notebook.ParentID), notebook.UpdatedAt.UnixNano(),
+		notebook.ID.String(), notebook.OwnerID.String())
+	if err != nil {
+		return errors.Wrap(err, "update notebook")
+	}
+	if err := notFoundIfNoRows(result); err != nil {
+		return notes.ErrNotebookNotFound
+	}
+
+	return nil
+}
+
+// DeleteNotebook делает явно то, что в postgres делают внешние ключи:
+// удаляет вложенные блокноты и убирает в корень заметки из корзины.
+func (s *SQLiteNoteStore) DeleteNotebook(ctx context.Context, ownerID, id uuid.UUID, policy notes.NotebookDeletePolicy, at time.Time) error {
+	s.log.Debug("deleting notebook", zap.Any("notebookID", id), zap.String("policy", string(policy)))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	var parentID sql.NullString
+	query := fmt.Sprintf(`SELECT parent_id FROM %v WHERE id = ? AND owner_id = ?`, NotebookTable)
+	err = tx.QueryRowContext(ctx, query, id.String(), ownerID.String()).Scan(&parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notes.ErrNotebookNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "get notebook to delete")
+	}
+
+	// scope блокнот или, при удалении в корзину, все вложенные в него.
+	scope := "= ?1"
+	if policy == notes.NotebookDeleteTrash {
+		scope = "IN (" + notebookTree("?1") + ")"
+		query = fmt.Sprintf(`UPDATE %v SET deleted_at = ?2 WHERE notebook_id %v AND deleted_at IS NULL`, NoteTable, scope)
+		if _, err := tx.ExecContext(ctx, query, id.String(), at.UnixNano()); err != nil {
+			return errors.Wrap(err, "trash notebook notes")
+		}
+	} else {
+		query = fmt.Sprintf(
+			`UPDATE %v SET notebook_id = ?2, updated_at = ?3, version = version + 1 WHERE notebook_id = ?1 AND deleted_at IS NULL`,
+			NoteTable,
+		)
+		if _, err := tx.ExecContext(ctx, query, id.String(), parentID, at.UnixNano()); err != nil {
+			return errors.Wrap(err, "move notebook notes")
+		}
+
+		query = fmt.Sprintf(`UPDATE %v SET parent_id = ?2, updated_at = ?3 WHERE parent_id = ?1`, NotebookTable)
+		if _, err := tx.ExecContext(ctx, query, id.String(), parentID, at.UnixNano()); err != nil {
+			return errors.Wrap(err, "move nested notebooks")
+		}
+	}
+
+	query = fmt.Sprintf(`UPDATE %v SET notebook_id = NULL WHERE notebook_id %v`, NoteTable, scope)
+	if _, err := tx.ExecContext(ctx, query, id.String()); err != nil {
+		return errors.Wrap(err, "detach notes")
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %v WHERE id %v`, NotebookTable, scope)
+	if _, err := tx.ExecContext(ctx, query, id.String()); err != nil {
+		return errors.Wrap(err, "delete notebook")
+	}
+
+	return errors.Wrap(tx.Commit(), "commit delete notebook")
+}
+
+func (s *SQLiteNoteStore) MoveNotes(ctx context.Context, ownerID uuid.UUID, ids []uuid.UUID, notebookID uuid.UUID, at time.Time) (uint, error) {
+	s.log.Debug("moving notes", zap.Any("note ids", ids), zap.Any("notebookID", notebookID))
+
+	var target *uuid.UUID
+	if notebookID != uuid.Nil {
+		target = &notebookID
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(
+		`UPDATE %v SET notebook_id = ?1, updated_at = ?2, version = version + 1
+		WHERE id = ?3 AND owner_id = ?4 AND deleted_at IS NULL AND notebook_id IS NOT ?1`,
+		NoteTable,
+	)
+
+	var count uint
+	for _, id := range ids {
+		result, err := tx.ExecContext(ctx, query, nullUUID(target), at.UnixNano(), id.String(), ownerID.String())
+		if err != nil {
+			return 0, errors.Wrap(err, "move note")
+		}
+		if notFoundIfNoRows(result) == nil {
+			count++
+		}
+	}
+
+	return count, errors.Wrap(tx.Commit(), "commit move notes")
+}
+
+func (s *SQLiteNoteStore) SetFlag(ctx context.Context, id uuid.UUID, flag notes.Flag, value bool, version uint, at time.Time) error {
+	s.log.Debug("setting note flag", zap.Any("noteID", id), zap.String("flag", string(flag)), zap.Bool("value", value))
+
+	column, err := flagColumn(flag)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(
+		`UPDATE %v SET %v = ?1, updated_at = ?2, version = version + 1
+		WHERE id = ?3 AND (?4 = 0 OR version = ?4) AND deleted_at IS NULL`,
+		NoteTable, column,
+	)
+
+	result, err := tx.ExecContext(ctx, query, value, at.UnixNano(), id.String(), version)
+	if err != nil {
+		return errors.Wrap(err, "set note flag")
+	}
+	if notFoundIfNoRows(result) != nil {
+		return noteMissingOrChanged(ctx, tx, `id = ?`, id.String())
+	}
+
+	return errors.Wrap(tx.Commit(), "commit note flag")
+}
+
+func scanSQLiteNotebook(row rowScanner) (note.Notebook, error) {
+	var (
+		id        string
+		ownerID   string
+		parentID  sql.NullString
+		createdAt int64
+		updatedAt int64
+		notebook  note.Notebook
+	)
+
+	err := row.Scan(&id, &ownerID, &parentID, &notebook.Name, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return note.Notebook{}, err
+	}
+	if err != nil {
+		return note.Notebook{}, errors.Wrap(err, "scan notebook")
+	}
+
+	if notebook.ID, err = uuid.FromString(id); err != nil {
+		return note.Notebook{}, errors.Wrap(err, "parse notebook id")
+	}
+	if notebook.OwnerID, err = uuid.FromString(ownerID); err != nil {
+		return note.Notebook{}, errors.Wrap(err, "parse notebook owner id")
+	}
+	if notebook.ParentID, err = uuidOrNil(parentID); err != nil {
+		return note.Notebook{}, errors.Wrap(err, "parse notebook parent id")
+	}
+	notebook.CreatedAt = time.Unix(0, createdAt)
+	notebook.UpdatedAt = time.Unix(0, updatedAt)
+
+	return notebook, nil
+}
@@ -0,0 +1,93 @@
+package adaptor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+type SQLiteUserStore struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+func NewSQLiteUserStore(db *sql.DB, logger *zap.Logger) *SQLiteUserStore {
+	return &SQLiteUserStore{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (s *SQLiteUserStore) CreateTable(ctx context.Context) error {
+	s.log.Debug("creating table", zap.Any("table", UserTable))
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %v (
+			id TEXT PRIMARY KEY NOT NULL,
+			email TEXT NOT NULL UNIQUE,
+			password_hash BLOB NOT NULL,
+			created_at INTEGER NOT NULL
+		)`,
+		UserTable,
+	)
+	_, err := s.db.ExecContext(ctx, query)
+
+	return errors.Wrapf(err, "create table %v", UserTable)
+}
+
+func (s *SQLiteUserStore) Create(ctx context.Context, u user.User) error {
+	s.log.Debug("saving user", zap.Any("userID", u.ID))
+
+	query := fmt.Sprintf(`INSERT INTO %v (%v) VALUES (?, ?, ?, ?)`, UserTable, userColumns)
+
+	_, err := s.db.ExecContext(ctx, query, u.ID.String(), u.Email, u.PasswordHash, u.CreatedAt.UnixNano())
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return users.ErrEmailTaken.WithCause(err)
+	}
+
+	return errors.Wrap(err, "save user to database")
+}
+
+func (s *SQLiteUserStore) GetByID(ctx context.Context, id uuid.UUID) (user.User, error) {
+	query := fmt.Sprintf(`SELECT %v FROM %v WHERE id = ?`, userColumns, UserTable)
+
+	return s.get(ctx, query, id.String())
+}
+
+func (s *SQLiteUserStore) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	query := fmt.Sprintf(`SELECT %v FROM %v WHERE email = ?`, userColumns, UserTable)
+
+	return s.get(ctx, query, email)
+}
+
+func (s *SQLiteUserStore) get(ctx context.Context, query string, param any) (user.User, error) {
+	var (
+		id        string
+		createdAt int64
+		u         user.User
+	)
+
+	err := s.db.QueryRowContext(ctx, query, param).Scan(&id, &u.Email, &u.PasswordHash, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.User{}, users.NotFound
+	}
+	if err != nil {
+		return user.User{}, errors.Wrap(err, "get user")
+	}
+
+	u.ID, err = uuid.FromString(id)
+	if err != nil {
+		return user.User{}, errors.Wrap(err, "parse user id")
+	}
+	u.CreatedAt = time.Unix(0, createdAt)
+
+	return u, nil
+}
@@ -0,0 +1,83 @@
+package adaptor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"github.com/victor8titov/rest-api-notes/internal/migrations"
+	"go.uber.org/zap"
+)
+
+// openTestPostgres подключается к NOTES_TEST_POSTGRES_DSN и применяет миграции.
+// Без переменной тест пропускается. Тесты postgres очищают все таблицы
+// сервиса, не указывайте базу с нужными данными.
+func openTestPostgres(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("NOTES_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("NOTES_TEST_POSTGRES_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := migrations.NewMigrator(db, zap.NewNop()).Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return db
+}
+
+// resetTables очищает данные и создаёт пользователей owners. Остальные
+// таблицы ссылаются на пользователей или заметки и очищаются каскадом.
+func resetTables(t *testing.T, db *sql.DB, owners ...uuid.UUID) {
+	t.Helper()
+
+	if _, err := db.ExecContext(context.Background(), fmt.Sprintf(`TRUNCATE %v, %v CASCADE`, UserTable, NoteTable)); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	createOwners(t, NewUserStore(db, zap.NewNop()), owners)
+}
+
+// openTestSQLite новая база во временном каталоге теста с таблицей
+// пользователей и пользователями owners.
+func openTestSQLite(t *testing.T, owners ...uuid.UUID) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	userStore := NewSQLiteUserStore(db, zap.NewNop())
+	if err := userStore.CreateTable(context.Background()); err != nil {
+		t.Fatalf("create users table: %v", err)
+	}
+	createOwners(t, userStore, owners)
+
+	return db
+}
+
+func createOwners(t *testing.T, store users.Store, owners []uuid.UUID) {
+	t.Helper()
+
+	for _, id := range owners {
+		u := user.User{ID: id, Email: id.String() + "@example.com", PasswordHash: []byte("hash"), CreatedAt: time.Now()}
+		if err := store.Create(context.Background(), u); err != nil {
+			t.Fatalf("create owner: %v", err)
+		}
+	}
+}
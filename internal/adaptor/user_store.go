@@ -0,0 +1,71 @@
+package adaptor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+const UserTable = "users"
+
+const userColumns = "id, email, password_hash, created_at"
+
+type UserStore struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+func NewUserStore(db *sql.DB, logger *zap.Logger) *UserStore {
+	return &UserStore{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (s *UserStore) Create(ctx context.Context, u user.User) error {
+	s.log.Debug("saving user", zap.Any("userID", u.ID))
+
+	query := fmt.Sprintf(
+		`INSERT INTO %v (%v) VALUES ($1, $2, $3, $4)`,
+		UserTable, userColumns,
+	)
+
+	_, err := s.db.ExecContext(ctx, query, u.ID, u.Email, u.PasswordHash, u.CreatedAt)
+	if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == pgUniqueViolation {
+		return users.ErrEmailTaken.WithCause(err)
+	}
+
+	return errors.Wrap(err, "save user to database")
+}
+
+func (s *UserStore) GetByID(ctx context.Context, id uuid.UUID) (user.User, error) {
+	query := fmt.Sprintf(`SELECT %v FROM %v WHERE id = $1`, userColumns, UserTable)
+
+	return s.get(ctx, query, id)
+}
+
+func (s *UserStore) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	query := fmt.Sprintf(`SELECT %v FROM %v WHERE email = $1`, userColumns, UserTable)
+
+	return s.get(ctx, query, email)
+}
+
+func (s *UserStore) get(ctx context.Context, query string, param any) (user.User, error) {
+	u := user.User{}
+	err := s.db.QueryRowContext(ctx, query, param).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.User{}, users.NotFound
+	}
+	if err != nil {
+		return user.User{}, errors.Wrap(err, "get user")
+	}
+
+	return u, nil
+}
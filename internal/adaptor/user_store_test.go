@@ -0,0 +1,18 @@
+package adaptor
+
+import (
+	"testing"
+
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/action/users/storetest"
+	"go.uber.org/zap"
+)
+
+func TestUserStore(t *testing.T) {
+	db := openTestPostgres(t)
+
+	storetest.Run(t, func(t *testing.T) users.Store {
+		resetTables(t, db)
+		return NewUserStore(db, zap.NewNop())
+	})
+}
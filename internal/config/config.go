@@ -0,0 +1,407 @@
+// Package config загружает настройки приложения.
+//
+// Источники в порядке возрастания приоритета: значения по умолчанию,
+// файл (YAML или JSON), переменные окружения NOTES_*, флаги командной строки.
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+const envPrefix = "NOTES_"
+
+type Config struct {
+	Database    DatabaseConfig    `yaml:"database" json:"database"`
+	HTTP        HTTPConfig        `yaml:"http" json:"http"`
+	Log         LogConfig         `yaml:"log" json:"log"`
+	Pagination  PaginationConfig  `yaml:"pagination" json:"pagination"`
+	Auth        AuthConfig        `yaml:"auth" json:"auth"`
+	Trash       TrashConfig       `yaml:"trash" json:"trash"`
+	Batch       BatchConfig       `yaml:"batch" json:"batch"`
+	Idempotency IdempotencyConfig `yaml:"idempotency" json:"idempotency"`
+	AutoMigrate bool              `yaml:"autoMigrate" json:"autoMigrate"`
+}
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+type DatabaseConfig struct {
+	// Driver хранилище заметок: postgres, sqlite или memory.
+	Driver string `yaml:"driver" json:"driver"`
+	// DSN строка подключения для postgres или путь к файлу для sqlite.
+	DSN string `yaml:"dsn" json:"dsn"`
+}
+
+type HTTPConfig struct {
+	Port            int      `yaml:"port" json:"port"`
+	CORSOrigins     []string `yaml:"corsOrigins" json:"corsOrigins"`
+	SwaggerHost     string   `yaml:"swaggerHost" json:"swaggerHost"`
+	ReadTimeout     Duration `yaml:"readTimeout" json:"readTimeout"`
+	WriteTimeout    Duration `yaml:"writeTimeout" json:"writeTimeout"`
+	IdleTimeout     Duration `yaml:"idleTimeout" json:"idleTimeout"`
+	ShutdownTimeout Duration `yaml:"shutdownTimeout" json:"shutdownTimeout"`
+	// ShutdownDelay пауза между переходом в not-ready и остановкой сервера,
+	// чтобы балансировщик успел убрать под из ротации.
+	ShutdownDelay Duration `yaml:"shutdownDelay" json:"shutdownDelay"`
+}
+
+type PaginationConfig struct {
+	// CursorSecret ключ подписи курсоров. Если пуст, генерируется при старте,
+	// и курсоры перестают действовать после перезапуска и между репликами.
+	CursorSecret string `yaml:"cursorSecret" json:"cursorSecret"`
+	// DefaultLimit размер страницы, если limit не указан.
+	DefaultLimit uint `yaml:"defaultLimit" json:"defaultLimit"`
+	// MaxLimit наибольший допустимый limit.
+	MaxLimit uint `yaml:"maxLimit" json:"maxLimit"`
+}
+
+type AuthConfig struct {
+	// JWTSecret ключ подписи токенов доступа. Если пуст, генерируется при старте,
+	// и после перезапуска всем придётся войти заново.
+	JWTSecret string `yaml:"jwtSecret" json:"jwtSecret"`
+	// JWTPublicKey открытый ключ RSA или Ed25519 в PEM для токенов внешнего
+	// издателя, подписанных RS256 или EdDSA.
+	JWTPublicKey string `yaml:"jwtPublicKey" json:"jwtPublicKey"`
+	// JWKSFile путь к локальному файлу JWKS с ключами внешнего издателя.
+	JWKSFile string `yaml:"jwksFile" json:"jwksFile"`
+	// Issuer и Audience, если заданы, проверяются у каждого токена и
+	// записываются в выданные сервисом токены.
+	Issuer   string `yaml:"issuer" json:"issuer"`
+	Audience string `yaml:"audience" json:"audience"`
+	// TokenTTL срок действия токена доступа.
+	TokenTTL Duration `yaml:"tokenTTL" json:"tokenTTL"`
+}
+
+type TrashConfig struct {
+	// Retention сколько удалённые заметки хранятся в корзине. 0 отключает
+	// автоматическую очистку.
+	Retention Duration `yaml:"retention" json:"retention"`
+	// PurgeInterval как часто искать заметки с истёкшим сроком.
+	PurgeInterval Duration `yaml:"purgeInterval" json:"purgeInterval"`
+}
+
+type BatchConfig struct {
+	// MaxOperations наибольшее число операций в одном запросе POST /note/batch.
+	MaxOperations uint `yaml:"maxOperations" json:"maxOperations"`
+}
+
+type IdempotencyConfig struct {
+	// TTL сколько хранится ответ на запрос с Idempotency-Key.
+	TTL Duration `yaml:"ttl" json:"ttl"`
+	// PurgeInterval как часто удалять истёкшие ключи.
+	PurgeInterval Duration `yaml:"purgeInterval" json:"purgeInterval"`
+	// MaxBodySize наибольший размер тела запроса с Idempotency-Key и
+	// сохраняемого ответа в байтах.
+	MaxBodySize uint `yaml:"maxBodySize" json:"maxBodySize"`
+}
+
+type LogConfig struct {
+	Level       string `yaml:"level" json:"level"`
+	Development bool   `yaml:"development" json:"development"`
+}
+
+// Default значения, с которыми приложение работает локально.
+func Default() Config {
+	return Config{
+		Database: DatabaseConfig{
+			Driver: DriverPostgres,
+			DSN:    "user=postgres password=postgres dbname=notesapp sslmode=disable host=127.0.0.1",
+		},
+		HTTP: HTTPConfig{
+			Port:            3000,
+			CORSOrigins:     []string{"http://localhost:3000"},
+			SwaggerHost:     "localhost:3000",
+			ReadTimeout:     Duration{10 * time.Second},
+			WriteTimeout:    Duration{30 * time.Second},
+			IdleTimeout:     Duration{120 * time.Second},
+			ShutdownTimeout: Duration{30 * time.Second},
+		},
+		Pagination: PaginationConfig{
+			DefaultLimit: 20,
+			MaxLimit:     100,
+		},
+		Auth: AuthConfig{
+			TokenTTL: Duration{24 * time.Hour},
+		},
+		Trash: TrashConfig{
+			Retention:     Duration{30 * 24 * time.Hour},
+			PurgeInterval: Duration{time.Hour},
+		},
+		Batch: BatchConfig{
+			MaxOperations: 500,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:           Duration{24 * time.Hour},
+			PurgeInterval: Duration{time.Hour},
+			MaxBodySize:   1 << 20,
+		},
+		Log: LogConfig{
+			Level:       "debug",
+			Development: true,
+		},
+	}
+}
+
+// Load собирает конфигурацию из всех источников и проверяет её.
+// Возвращает аргументы, оставшиеся после разбора флагов.
+func Load(args []string) (Config, []string, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("notes", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv(envPrefix+"CONFIG"), "path to YAML or JSON config file")
+	driver := fs.String("driver", "", "storage driver: postgres, sqlite or memory")
+	dsn := fs.String("dsn", "", "postgres connection string or sqlite file path")
+	port := fs.Int("port", 0, "http port")
+	logLevel := fs.String("log-level", "", "log level: debug, info, warn, error")
+	corsOrigins := fs.String("cors-origins", "", "comma separated list of allowed CORS origins")
+	swaggerHost := fs.String("swagger-host", "", "host shown in swagger documentation")
+	autoMigrate := fs.Bool("migrate", false, "apply pending migrations before starting server")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 0, "time to drain in-flight requests on shutdown")
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, errors.WithMessage(err, "parse flags")
+	}
+
+	if *configPath != "" {
+		if err := loadFile(*configPath, &cfg); err != nil {
+			return Config{}, nil, err
+		}
+	}
+
+	if err := loadEnv(&cfg); err != nil {
+		return Config{}, nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "driver":
+			cfg.Database.Driver = *driver
+		case "dsn":
+			cfg.Database.DSN = *dsn
+		case "port":
+			cfg.HTTP.Port = *port
+		case "log-level":
+			cfg.Log.Level = *logLevel
+		case "cors-origins":
+			cfg.HTTP.CORSOrigins = splitList(*corsOrigins)
+		case "swagger-host":
+			cfg.HTTP.SwaggerHost = *swaggerHost
+		case "migrate":
+			cfg.AutoMigrate = *autoMigrate
+		case "shutdown-timeout":
+			cfg.HTTP.ShutdownTimeout = Duration{*shutdownTimeout}
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, nil, err
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+// Validate проверяет все поля и возвращает список найденных ошибок.
+func (c Config) Validate() error {
+	problems := []string{}
+
+	switch c.Database.Driver {
+	case DriverPostgres, DriverSQLite:
+		if strings.TrimSpace(c.Database.DSN) == "" {
+			problems = append(problems, "database.dsn is required")
+		}
+	case DriverMemory:
+	default:
+		problems = append(problems, fmt.Sprintf("database.driver %q is unknown", c.Database.Driver))
+	}
+	if c.HTTP.Port <= 0 || c.HTTP.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("http.port %v is out of range 1-65535", c.HTTP.Port))
+	}
+	for _, d := range []struct {
+		name  string
+		value Duration
+	}{
+		{"http.readTimeout", c.HTTP.ReadTimeout},
+		{"http.writeTimeout", c.HTTP.WriteTimeout},
+		{"http.idleTimeout", c.HTTP.IdleTimeout},
+		{"http.shutdownTimeout", c.HTTP.ShutdownTimeout},
+		{"http.shutdownDelay", c.HTTP.ShutdownDelay},
+		{"auth.tokenTTL", c.Auth.TokenTTL},
+		{"trash.retention", c.Trash.Retention},
+		{"trash.purgeInterval", c.Trash.PurgeInterval},
+		{"idempotency.ttl", c.Idempotency.TTL},
+		{"idempotency.purgeInterval", c.Idempotency.PurgeInterval},
+	} {
+		if d.value.Duration < 0 {
+			problems = append(problems, fmt.Sprintf("%v must not be negative", d.name))
+		}
+	}
+	if c.Auth.TokenTTL.Duration == 0 {
+		problems = append(problems, "auth.tokenTTL must be positive")
+	}
+	if c.Trash.Retention.Duration > 0 && c.Trash.PurgeInterval.Duration == 0 {
+		problems = append(problems, "trash.purgeInterval must be positive")
+	}
+	if c.Pagination.MaxLimit == 0 {
+		problems = append(problems, "pagination.maxLimit must be positive")
+	}
+	if c.Pagination.DefaultLimit == 0 || c.Pagination.DefaultLimit > c.Pagination.MaxLimit {
+		problems = append(problems, fmt.Sprintf(
+			"pagination.defaultLimit %v is out of range 1-%v", c.Pagination.DefaultLimit, c.Pagination.MaxLimit,
+		))
+	}
+	if c.Idempotency.TTL.Duration == 0 {
+		problems = append(problems, "idempotency.ttl must be positive")
+	}
+	if c.Idempotency.PurgeInterval.Duration == 0 {
+		problems = append(problems, "idempotency.purgeInterval must be positive")
+	}
+	if c.Idempotency.MaxBodySize == 0 {
+		problems = append(problems, "idempotency.maxBodySize must be positive")
+	}
+	if c.Batch.MaxOperations == 0 {
+		problems = append(problems, "batch.maxOperations must be positive")
+	}
+	if _, err := zapcore.ParseLevel(c.Log.Level); err != nil {
+		problems = append(problems, fmt.Sprintf("log.level %q is unknown", c.Log.Level))
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid config: %v", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "read config file")
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, cfg)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	default:
+		return errors.Errorf("unsupported config file extension %q", filepath.Ext(path))
+	}
+
+	return errors.Wrapf(err, "parse config file %v", path)
+}
+
+func loadEnv(cfg *Config) error {
+	if v, ok := lookupEnv("DATABASE_DRIVER"); ok {
+		cfg.Database.Driver = v
+	}
+	if v, ok := lookupEnv("DATABASE_DSN"); ok {
+		cfg.Database.DSN = v
+	}
+	if v, ok := lookupEnv("HTTP_PORT"); ok {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %vHTTP_PORT", envPrefix)
+		}
+		cfg.HTTP.Port = port
+	}
+	if v, ok := lookupEnv("CORS_ORIGINS"); ok {
+		cfg.HTTP.CORSOrigins = splitList(v)
+	}
+	if v, ok := lookupEnv("SWAGGER_HOST"); ok {
+		cfg.HTTP.SwaggerHost = v
+	}
+	for name, d := range map[string]*Duration{
+		"HTTP_READ_TIMEOUT":          &cfg.HTTP.ReadTimeout,
+		"HTTP_WRITE_TIMEOUT":         &cfg.HTTP.WriteTimeout,
+		"HTTP_IDLE_TIMEOUT":          &cfg.HTTP.IdleTimeout,
+		"HTTP_SHUTDOWN_TIMEOUT":      &cfg.HTTP.ShutdownTimeout,
+		"HTTP_SHUTDOWN_DELAY":        &cfg.HTTP.ShutdownDelay,
+		"AUTH_TOKEN_TTL":             &cfg.Auth.TokenTTL,
+		"TRASH_RETENTION":            &cfg.Trash.Retention,
+		"TRASH_PURGE_INTERVAL":       &cfg.Trash.PurgeInterval,
+		"IDEMPOTENCY_TTL":            &cfg.Idempotency.TTL,
+		"IDEMPOTENCY_PURGE_INTERVAL": &cfg.Idempotency.PurgeInterval,
+	} {
+		if v, ok := lookupEnv(name); ok {
+			if err := d.UnmarshalText([]byte(v)); err != nil {
+				return errors.WithMessagef(err, "invalid %v%v", envPrefix, name)
+			}
+		}
+	}
+	if v, ok := lookupEnv("CURSOR_SECRET"); ok {
+		cfg.Pagination.CursorSecret = v
+	}
+	for name, value := range map[string]*string{
+		"AUTH_JWT_SECRET":     &cfg.Auth.JWTSecret,
+		"AUTH_JWT_PUBLIC_KEY": &cfg.Auth.JWTPublicKey,
+		"AUTH_JWKS_FILE":      &cfg.Auth.JWKSFile,
+		"AUTH_ISSUER":         &cfg.Auth.Issuer,
+		"AUTH_AUDIENCE":       &cfg.Auth.Audience,
+	} {
+		if v, ok := lookupEnv(name); ok {
+			*value = v
+		}
+	}
+	for name, limit := range map[string]*uint{
+		"PAGINATION_DEFAULT_LIMIT":  &cfg.Pagination.DefaultLimit,
+		"PAGINATION_MAX_LIMIT":      &cfg.Pagination.MaxLimit,
+		"BATCH_MAX_OPERATIONS":      &cfg.Batch.MaxOperations,
+		"IDEMPOTENCY_MAX_BODY_SIZE": &cfg.Idempotency.MaxBodySize,
+	} {
+		if v, ok := lookupEnv(name); ok {
+			value, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %v%v", envPrefix, name)
+			}
+			*limit = uint(value)
+		}
+	}
+	if v, ok := lookupEnv("LOG_LEVEL"); ok {
+		cfg.Log.Level = v
+	}
+	if v, ok := lookupEnv("LOG_DEVELOPMENT"); ok {
+		development, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %vLOG_DEVELOPMENT", envPrefix)
+		}
+		cfg.Log.Development = development
+	}
+	if v, ok := lookupEnv("AUTO_MIGRATE"); ok {
+		autoMigrate, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %vAUTO_MIGRATE", envPrefix)
+		}
+		cfg.AutoMigrate = autoMigrate
+	}
+
+	return nil
+}
+
+func lookupEnv(name string) (string, bool) {
+	return os.LookupEnv(envPrefix + name)
+}
+
+func splitList(value string) []string {
+	result := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Duration time.Duration, который в файле задаётся строкой вида "5s" или "1m30s".
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return errors.Wrapf(err, "parse duration %q", text)
+	}
+	d.Duration = value
+
+	return nil
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return errors.Wrap(err, "duration must be a string")
+	}
+
+	return d.UnmarshalText([]byte(text))
+}
@@ -0,0 +1,137 @@
+// Package apperr типизированные ошибки предметной области. Слой service
+// превращает их в ответы со стабильным машиночитаемым кодом.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind класс ошибки, определяет HTTP-статус.
+type Kind string
+
+const (
+	KindValidation         Kind = "validation"
+	KindNotFound           Kind = "not_found"
+	KindConflict           Kind = "conflict"
+	KindUnauthorized       Kind = "unauthorized"
+	KindForbidden          Kind = "forbidden"
+	KindPreconditionFailed Kind = "precondition_failed"
+	// KindPreconditionRequired запрос на изменение пришёл без обязательного условия.
+	KindPreconditionRequired Kind = "precondition_required"
+	KindUnprocessable        Kind = "unprocessable"
+	KindInternal             Kind = "internal"
+	// KindTooLarge тело запроса больше допустимого.
+	KindTooLarge Kind = "too_large"
+)
+
+// FieldError ошибка в одном поле запроса.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind Kind
+	// Code стабильный код для клиентов, например note_not_found.
+	Code    string
+	Message string
+	Fields  []FieldError
+	cause   error
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func PreconditionFailed(code, message string) *Error {
+	return New(KindPreconditionFailed, code, message)
+}
+
+func PreconditionRequired(code, message string) *Error {
+	return New(KindPreconditionRequired, code, message)
+}
+
+// Validation ошибка входных данных с перечнем неверных полей.
+func Validation(code, message string, fields ...FieldError) *Error {
+	err := New(KindValidation, code, message)
+	err.Fields = fields
+
+	return err
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	for i, f := range e.Fields {
+		if i == 0 {
+			msg += ":"
+		}
+		msg += fmt.Sprintf(" %v %v;", f.Field, f.Message)
+	}
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is ошибки с одинаковым кодом считаются одной ошибкой, даже если у копии
+// другие поля или причина. Так работают сравнения с объявленными переменными.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// WithCause копия ошибки с причиной для логов. Клиенту причина не показывается.
+func (e *Error) WithCause(cause error) *Error {
+	c := *e
+	c.cause = cause
+
+	return &c
+}
+
+// WithMessage копия ошибки с уточнённым сообщением для клиента.
+func (e *Error) WithMessage(message string) *Error {
+	c := *e
+	c.Message = message
+
+	return &c
+}
+
+// WithField копия ошибки с дополнительным неверным полем.
+func (e *Error) WithField(field, message string) *Error {
+	c := *e
+	c.Fields = append(append([]FieldError{}, e.Fields...), FieldError{Field: field, Message: message})
+
+	return &c
+}
+
+// As находит *Error в цепочке обёрток.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+
+	return nil, false
+}
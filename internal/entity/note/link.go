@@ -0,0 +1,22 @@
+package note
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Link публичная ссылка на заметку только для чтения. У заметки не больше
+// одной ссылки, новая заменяет старую.
+type Link struct {
+	NoteID uuid.UUID `json:"noteId"`
+	// OwnerID владелец заметки, от его имени ссылка открывает заметку.
+	OwnerID   uuid.UUID  `json:"-"`
+	Token     string     `json:"token"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (l Link) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
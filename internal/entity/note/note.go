@@ -1,27 +1,50 @@
 package note
 
 import (
-	"errors"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
 )
 
 // Note Сущность заметка
 type Note struct {
-	ID        uuid.UUID `json:"id"`
-	Label     string    `json:"label"`
-	Body      string    `json:"body"`
-	Tags      []string  `json:"tags"`
+	ID      uuid.UUID `json:"id"`
+	OwnerID uuid.UUID `json:"ownerId"`
+	Label   string    `json:"label"`
+	Body    string    `json:"body"`
+	Tags    []string  `json:"tags"`
+	// NotebookID блокнот заметки, nil - заметка лежит в корне.
+	NotebookID *uuid.UUID `json:"notebookId,omitempty"`
+	// Pinned закреплённые заметки идут в списке первыми при любой сортировке.
+	Pinned bool `json:"pinned"`
+	// Archived архивные заметки по умолчанию не попадают в список.
+	Archived  bool      `json:"archived"`
+	Favorite  bool      `json:"favorite"`
 	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt время последнего изменения, у новой заметки равно CreatedAt.
+	UpdatedAt time.Time `json:"updated_at"`
+	// Version растёт на единицу при каждом изменении, новая заметка получает 1.
+	Version uint `json:"version"`
+	// DeletedAt время переноса в корзину, только у удалённых заметок.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
+// Validate проверяет все поля сразу и возвращает apperr.Validation со списком ошибок.
 func (n Note) Validate() error {
+	fields := []apperr.FieldError{}
 	if n.ID == uuid.Nil {
-		return errors.New("note ID is required")
+		fields = append(fields, apperr.FieldError{Field: "id", Message: "is required"})
+	}
+	if n.OwnerID == uuid.Nil {
+		fields = append(fields, apperr.FieldError{Field: "ownerId", Message: "is required"})
 	}
 	if len(n.Label) == 0 {
-		return errors.New("note label is required")
+		fields = append(fields, apperr.FieldError{Field: "label", Message: "is required"})
+	}
+
+	if len(fields) > 0 {
+		return apperr.Validation("note_invalid", "note is invalid", fields...)
 	}
 
 	return nil
@@ -29,5 +52,46 @@ func (n Note) Validate() error {
 
 type ListNotes struct {
 	Notes []Note `json:"notes"`
-	Total uint   `json:"total"`
+	// Total общее число заметок, только если его запросили.
+	Total *uint `json:"total,omitempty"`
+	// NextCursor и PrevCursor курсоры соседних страниц, пустые на краях списка.
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
+// FoundNote заметка из результатов поиска.
+type FoundNote struct {
+	Note
+	// Rank релевантность, чем больше, тем выше в выдаче.
+	Rank float32 `json:"rank"`
+	// Snippet фрагмент текста в виде HTML: текст экранирован, совпадения выделены тегом <b>.
+	Snippet string `json:"snippet"`
+}
+
+type SearchNotes struct {
+	Notes []FoundNote `json:"notes"`
+	Total uint        `json:"total"`
+}
+
+// RenderedNote заметка с текстом, преобразованным из Markdown в HTML.
+type RenderedNote struct {
+	Note
+	// HTML текст заметки после очистки от опасной разметки.
+	HTML string `json:"html"`
+	// TOC оглавление: заголовки текста по порядку.
+	TOC []Heading `json:"toc"`
+}
+
+// Heading заголовок в тексте заметки.
+type Heading struct {
+	Level int `json:"level"`
+	// ID якорь заголовка в HTML, на него ссылается #ID.
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// Tag тег и число заметок с ним.
+type Tag struct {
+	Name  string `json:"name"`
+	Count uint   `json:"count"`
 }
@@ -0,0 +1,48 @@
+package note
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+)
+
+// MaxNotebookNameLength ограничение длины имени блокнота в символах.
+const MaxNotebookNameLength = 255
+
+// Notebook блокнот владельца. Блокноты вкладываются друг в друга через
+// ParentID, блокнот без родителя лежит в корне.
+type Notebook struct {
+	ID        uuid.UUID  `json:"id"`
+	OwnerID   uuid.UUID  `json:"ownerId"`
+	ParentID  *uuid.UUID `json:"parentId,omitempty"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Validate проверяет все поля сразу и возвращает apperr.Validation со списком ошибок.
+func (n Notebook) Validate() error {
+	fields := []apperr.FieldError{}
+	if n.ID == uuid.Nil {
+		fields = append(fields, apperr.FieldError{Field: "id", Message: "is required"})
+	}
+	if n.OwnerID == uuid.Nil {
+		fields = append(fields, apperr.FieldError{Field: "ownerId", Message: "is required"})
+	}
+	if len(n.Name) == 0 {
+		fields = append(fields, apperr.FieldError{Field: "name", Message: "is required"})
+	}
+	if len([]rune(n.Name)) > MaxNotebookNameLength {
+		fields = append(fields, apperr.FieldError{Field: "name", Message: "is too long"})
+	}
+	if n.ParentID != nil && *n.ParentID == n.ID {
+		fields = append(fields, apperr.FieldError{Field: "parentId", Message: "can not be the notebook itself"})
+	}
+
+	if len(fields) > 0 {
+		return apperr.Validation("notebook_invalid", "notebook is invalid", fields...)
+	}
+
+	return nil
+}
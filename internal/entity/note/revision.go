@@ -0,0 +1,34 @@
+package note
+
+import (
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Revision полный снимок заметки после создания или изменения. Номера
+// ревизий начинаются с 1 и растут на единицу в пределах заметки.
+type Revision struct {
+	NoteID   uuid.UUID `json:"noteId"`
+	Rev      uint      `json:"rev"`
+	Label    string    `json:"label"`
+	Body     string    `json:"body"`
+	Tags     []string  `json:"tags"`
+	AuthorID uuid.UUID `json:"authorId"`
+	// AuthorEmail заполняется при выдаче списка ревизий.
+	AuthorEmail string    `json:"authorEmail,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Text представление ревизии, по которому строится diff: заголовок, теги и
+// через пустую строку текст заметки.
+func (r Revision) Text() string {
+	var b strings.Builder
+	b.WriteString("label: " + r.Label + "\n")
+	b.WriteString("tags: " + strings.Join(r.Tags, ", ") + "\n")
+	b.WriteString("\n")
+	b.WriteString(r.Body)
+
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package note
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Permission право пользователя на заметку.
+type Permission string
+
+const (
+	PermissionRead  Permission = "read"
+	PermissionWrite Permission = "write"
+	// PermissionOwner есть только у владельца, выдать его другому нельзя.
+	PermissionOwner Permission = "owner"
+)
+
+var permissionRank = map[Permission]int{
+	PermissionRead:  1,
+	PermissionWrite: 2,
+	PermissionOwner: 3,
+}
+
+// Allows право p включает required: запись включает чтение, владелец может всё.
+func (p Permission) Allows(required Permission) bool {
+	return permissionRank[p] >= permissionRank[required] && permissionRank[required] > 0
+}
+
+// Grantable право можно выдать другому пользователю.
+func (p Permission) Grantable() bool {
+	return p == PermissionRead || p == PermissionWrite
+}
+
+// Share доступ пользователя к чужой заметке.
+type Share struct {
+	NoteID     uuid.UUID  `json:"noteId"`
+	UserID     uuid.UUID  `json:"userId"`
+	Email      string     `json:"email,omitempty"`
+	Permission Permission `json:"permission"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package user
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// APITokenPrefix отличает API-токены от JWT в заголовке Authorization.
+const APITokenPrefix = "ntk_"
+
+// APIToken долгоживущий токен для скриптов. Хранится только хеш секрета,
+// сам токен показывается один раз при создании.
+type APIToken struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"-"`
+	Name   string    `json:"name"`
+	Scopes []string  `json:"scopes"`
+	// Hash SHA-256 от токена.
+	Hash       []byte     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+func (t APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// IssuedAPIToken ответ на создание токена, единственный раз содержит сам токен.
+type IssuedAPIToken struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// KnownScopes scopes, которые можно выдать API-токену.
+var KnownScopes = []string{ScopeNotesRead, ScopeNotesWrite}
@@ -0,0 +1,71 @@
+package user
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+)
+
+// User Сущность пользователь, владелец заметок.
+type User struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+	// PasswordHash bcrypt-хеш пароля, наружу не отдаётся.
+	PasswordHash []byte    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (u User) Validate() error {
+	fields := []apperr.FieldError{}
+	if u.ID == uuid.Nil {
+		fields = append(fields, apperr.FieldError{Field: "id", Message: "is required"})
+	}
+	if len(u.Email) == 0 {
+		fields = append(fields, apperr.FieldError{Field: "email", Message: "is required"})
+	}
+	if len(u.PasswordHash) == 0 {
+		fields = append(fields, apperr.FieldError{Field: "password", Message: "is required"})
+	}
+
+	if len(fields) > 0 {
+		return apperr.Validation("user_invalid", "user is invalid", fields...)
+	}
+
+	return nil
+}
+
+// Token выданный при входе токен доступа.
+type Token struct {
+	AccessToken string    `json:"accessToken"`
+	TokenType   string    `json:"tokenType"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Scopes      []string  `json:"scopes"`
+}
+
+const (
+	ScopeNotesRead  = "notes:read"
+	ScopeNotesWrite = "notes:write"
+	// ScopeTokensManage управление API-токенами. API-токенам не выдаётся,
+	// чтобы утёкший токен не мог выпустить себе замену без срока.
+	ScopeTokensManage = "tokens:manage"
+)
+
+// DefaultScopes выдаются при входе по паролю.
+var DefaultScopes = []string{ScopeNotesRead, ScopeNotesWrite, ScopeTokensManage}
+
+// Principal аутентифицированный пользователь запроса и разрешённые ему действия.
+type Principal struct {
+	UserID uuid.UUID
+	Scopes []string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,19 @@
+package migrations
+
+func init() {
+	register(Step{
+		Version: 2,
+		Name:    "add full-text search to notes",
+		Up: exec(
+			`ALTER TABLE notes ADD COLUMN search tsvector GENERATED ALWAYS AS (
+				setweight(to_tsvector('simple', coalesce(label, '')), 'A') ||
+				setweight(to_tsvector('simple', coalesce(body, '')), 'B')
+			) STORED`,
+			`CREATE INDEX notes_search_idx ON notes USING GIN (search)`,
+		),
+		Down: exec(
+			`DROP INDEX IF EXISTS notes_search_idx`,
+			`ALTER TABLE notes DROP COLUMN IF EXISTS search`,
+		),
+	})
+}
@@ -0,0 +1,14 @@
+package migrations
+
+func init() {
+	register(Step{
+		Version: 3,
+		Name:    "add GIN index on note tags",
+		Up: exec(
+			`CREATE INDEX notes_tags_idx ON notes USING GIN (tags)`,
+		),
+		Down: exec(
+			`DROP INDEX IF EXISTS notes_tags_idx`,
+		),
+	})
+}
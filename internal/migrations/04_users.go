@@ -0,0 +1,19 @@
+package migrations
+
+func init() {
+	register(Step{
+		Version: 4,
+		Name:    "create users table",
+		Up: exec(
+			`CREATE TABLE users (
+				id uuid PRIMARY KEY,
+				email text NOT NULL UNIQUE,
+				password_hash bytea NOT NULL,
+				created_at timestamptz NOT NULL
+			)`,
+		),
+		Down: exec(
+			`DROP TABLE IF EXISTS users`,
+		),
+	})
+}
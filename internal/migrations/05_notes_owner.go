@@ -0,0 +1,18 @@
+package migrations
+
+func init() {
+	// Существующие заметки остаются без владельца и никому не видны,
+	// пока им не назначат owner_id вручную.
+	register(Step{
+		Version: 5,
+		Name:    "add owner to notes",
+		Up: exec(
+			`ALTER TABLE notes ADD COLUMN owner_id uuid REFERENCES users (id) ON DELETE CASCADE`,
+			`CREATE INDEX notes_owner_id_idx ON notes (owner_id, created_at)`,
+		),
+		Down: exec(
+			`DROP INDEX IF EXISTS notes_owner_id_idx`,
+			`ALTER TABLE notes DROP COLUMN IF EXISTS owner_id`,
+		),
+	})
+}
@@ -0,0 +1,24 @@
+package migrations
+
+func init() {
+	register(Step{
+		Version: 6,
+		Name:    "create api tokens table",
+		Up: exec(
+			`CREATE TABLE api_tokens (
+				id uuid PRIMARY KEY,
+				user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+				name text NOT NULL,
+				scopes text[] NOT NULL,
+				token_hash bytea NOT NULL UNIQUE,
+				created_at timestamptz NOT NULL,
+				expires_at timestamptz,
+				last_used_at timestamptz
+			)`,
+			`CREATE INDEX api_tokens_user_id_idx ON api_tokens (user_id, created_at)`,
+		),
+		Down: exec(
+			`DROP TABLE IF EXISTS api_tokens`,
+		),
+	})
+}
@@ -0,0 +1,21 @@
+package migrations
+
+func init() {
+	register(Step{
+		Version: 7,
+		Name:    "create note shares table",
+		Up: exec(
+			`CREATE TABLE note_shares (
+				note_id uuid NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+				user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+				permission text NOT NULL CHECK (permission IN ('read', 'write')),
+				created_at timestamptz NOT NULL,
+				PRIMARY KEY (note_id, user_id)
+			)`,
+			`CREATE INDEX note_shares_user_id_idx ON note_shares (user_id)`,
+		),
+		Down: exec(
+			`DROP TABLE IF EXISTS note_shares`,
+		),
+	})
+}
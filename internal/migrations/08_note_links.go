@@ -0,0 +1,19 @@
+package migrations
+
+func init() {
+	register(Step{
+		Version: 8,
+		Name:    "create note links table",
+		Up: exec(
+			`CREATE TABLE note_links (
+				note_id uuid PRIMARY KEY REFERENCES notes (id) ON DELETE CASCADE,
+				token text NOT NULL UNIQUE,
+				created_at timestamptz NOT NULL,
+				expires_at timestamptz
+			)`,
+		),
+		Down: exec(
+			`DROP TABLE IF EXISTS note_links`,
+		),
+	})
+}
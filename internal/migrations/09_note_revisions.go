@@ -0,0 +1,27 @@
+package migrations
+
+func init() {
+	register(Step{
+		Version: 9,
+		Name:    "create note revisions table",
+		Up: exec(
+			`CREATE TABLE note_revisions (
+				note_id uuid NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+				rev integer NOT NULL,
+				label text NOT NULL,
+				body text NOT NULL,
+				tags text[],
+				author_id uuid REFERENCES users (id) ON DELETE SET NULL,
+				created_at timestamptz NOT NULL,
+				PRIMARY KEY (note_id, rev)
+			)`,
+			// Существующие заметки получают первую ревизию с текущим содержимым,
+			// иначе первое изменение затёрло бы его без следа.
+			`INSERT INTO note_revisions (note_id, rev, label, body, tags, author_id, created_at)
+				SELECT id, 1, label, COALESCE(body, ''), tags, owner_id, created_at FROM notes`,
+		),
+		Down: exec(
+			`DROP TABLE IF EXISTS note_revisions`,
+		),
+	})
+}
@@ -0,0 +1,14 @@
+package migrations
+
+func init() {
+	register(Step{
+		Version: 10,
+		Name:    "add notes version",
+		Up: exec(
+			`ALTER TABLE notes ADD COLUMN version integer NOT NULL DEFAULT 1`,
+		),
+		Down: exec(
+			`ALTER TABLE notes DROP COLUMN IF EXISTS version`,
+		),
+	})
+}
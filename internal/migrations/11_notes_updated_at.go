@@ -0,0 +1,19 @@
+package migrations
+
+func init() {
+	// Существующие заметки считаются изменёнными в момент создания.
+	register(Step{
+		Version: 11,
+		Name:    "add notes updated_at",
+		Up: exec(
+			`ALTER TABLE notes ADD COLUMN updated_at timestamptz`,
+			`UPDATE notes SET updated_at = created_at`,
+			`ALTER TABLE notes ALTER COLUMN updated_at SET NOT NULL`,
+			`CREATE INDEX notes_owner_updated_at_idx ON notes (owner_id, updated_at)`,
+		),
+		Down: exec(
+			`DROP INDEX IF EXISTS notes_owner_updated_at_idx`,
+			`ALTER TABLE notes DROP COLUMN IF EXISTS updated_at`,
+		),
+	})
+}
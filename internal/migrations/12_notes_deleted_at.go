@@ -0,0 +1,18 @@
+package migrations
+
+func init() {
+	// Откат стирает заметки из корзины: без колонки они снова стали бы видны.
+	register(Step{
+		Version: 12,
+		Name:    "add notes deleted_at",
+		Up: exec(
+			`ALTER TABLE notes ADD COLUMN deleted_at timestamptz`,
+			`CREATE INDEX notes_deleted_at_idx ON notes (deleted_at) WHERE deleted_at IS NOT NULL`,
+		),
+		Down: exec(
+			`DELETE FROM notes WHERE deleted_at IS NOT NULL`,
+			`DROP INDEX IF EXISTS notes_deleted_at_idx`,
+			`ALTER TABLE notes DROP COLUMN IF EXISTS deleted_at`,
+		),
+	})
+}
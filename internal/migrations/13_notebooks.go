@@ -0,0 +1,30 @@
+package migrations
+
+func init() {
+	// Удаление блокнота удаляет вложенные блокноты, а заметки из них
+	// оказываются в корне. Действие удаления сначала само переносит или
+	// отправляет в корзину заметки по выбранной политике.
+	register(Step{
+		Version: 13,
+		Name:    "create notebooks table",
+		Up: exec(
+			`CREATE TABLE notebooks (
+				id uuid PRIMARY KEY,
+				owner_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+				parent_id uuid REFERENCES notebooks (id) ON DELETE CASCADE,
+				name text NOT NULL,
+				created_at timestamptz NOT NULL,
+				updated_at timestamptz NOT NULL
+			)`,
+			`CREATE INDEX notebooks_owner_id_idx ON notebooks (owner_id)`,
+			`CREATE INDEX notebooks_parent_id_idx ON notebooks (parent_id)`,
+			`ALTER TABLE notes ADD COLUMN notebook_id uuid REFERENCES notebooks (id) ON DELETE SET NULL`,
+			`CREATE INDEX notes_notebook_id_idx ON notes (notebook_id)`,
+		),
+		Down: exec(
+			`DROP INDEX IF EXISTS notes_notebook_id_idx`,
+			`ALTER TABLE notes DROP COLUMN IF EXISTS notebook_id`,
+			`DROP TABLE IF EXISTS notebooks`,
+		),
+	})
+}
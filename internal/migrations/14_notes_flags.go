@@ -0,0 +1,24 @@
+package migrations
+
+func init() {
+	// Закреплённые заметки сортируются первыми в каждом списке владельца,
+	// архивные по умолчанию из списка исключаются.
+	register(Step{
+		Version: 14,
+		Name:    "add notes pinned, archived and favorite",
+		Up: exec(
+			`ALTER TABLE notes
+				ADD COLUMN pinned boolean NOT NULL DEFAULT false,
+				ADD COLUMN archived boolean NOT NULL DEFAULT false,
+				ADD COLUMN favorite boolean NOT NULL DEFAULT false`,
+			`CREATE INDEX notes_owner_pinned_idx ON notes (owner_id, pinned DESC) WHERE deleted_at IS NULL`,
+		),
+		Down: exec(
+			`DROP INDEX IF EXISTS notes_owner_pinned_idx`,
+			`ALTER TABLE notes
+				DROP COLUMN IF EXISTS pinned,
+				DROP COLUMN IF EXISTS archived,
+				DROP COLUMN IF EXISTS favorite`,
+		),
+	})
+}
@@ -0,0 +1,27 @@
+package migrations
+
+func init() {
+	// Ответы на запросы с Idempotency-Key хранятся до expires_at, истёкшие
+	// записи удаляет фоновая очистка.
+	register(Step{
+		Version: 15,
+		Name:    "create idempotency keys table",
+		Up: exec(
+			`CREATE TABLE idempotency_keys (
+				user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+				key text NOT NULL,
+				request_hash bytea NOT NULL,
+				status integer,
+				headers jsonb,
+				body bytea,
+				created_at timestamptz NOT NULL,
+				expires_at timestamptz NOT NULL,
+				PRIMARY KEY (user_id, key)
+			)`,
+			`CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at)`,
+		),
+		Down: exec(
+			`DROP TABLE IF EXISTS idempotency_keys`,
+		),
+	})
+}
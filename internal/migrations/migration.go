@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// Step одна версия схемы базы данных. Up и Down выполняются внутри транзакции.
+type Step struct {
+	Version uint
+	Name    string
+	Up      func(ctx context.Context, tx *sql.Tx) error
+	Down    func(ctx context.Context, tx *sql.Tx) error
+}
+
+// Status состояние одной миграции.
+type Status struct {
+	Version   uint       `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+var registry []Step
+
+// register добавляет шаг в реестр, вызывается из init() файлов миграций.
+func register(step Step) {
+	for _, s := range registry {
+		if s.Version == step.Version {
+			panic("migrations: duplicate version " + step.Name)
+		}
+	}
+
+	registry = append(registry, step)
+	sort.Slice(registry, func(i, j int) bool {
+		return registry[i].Version < registry[j].Version
+	})
+}
+
+// Steps возвращает зарегистрированные миграции по возрастанию версии.
+func Steps() []Step {
+	steps := make([]Step, len(registry))
+	copy(steps, registry)
+
+	return steps
+}
+
+func exec(queries ...string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		for _, query := range queries {
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,238 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const MigrationTable = "schema_migrations"
+
+// lockKey ключ pg_advisory_lock, чтобы несколько реплик не мигрировали одновременно.
+const lockKey int64 = 0x6e6f746573 // "notes"
+
+var ErrUnknownVersion = errors.New("unknown migration version")
+
+type Migrator struct {
+	db    *sql.DB
+	log   *zap.Logger
+	steps []Step
+}
+
+func NewMigrator(db *sql.DB, log *zap.Logger) *Migrator {
+	return &Migrator{
+		db:    db,
+		log:   log,
+		steps: Steps(),
+	}
+}
+
+// Up применяет все ещё не применённые миграции.
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.steps) == 0 {
+		return nil
+	}
+
+	return m.To(ctx, m.steps[len(m.steps)-1].Version)
+}
+
+// Down откатывает последнюю применённую миграцию.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.steps) - 1; i >= 0; i-- {
+			if _, ok := applied[m.steps[i].Version]; ok {
+				return m.run(ctx, conn, m.steps[i], false)
+			}
+		}
+
+		m.log.Info("nothing to roll back")
+		return nil
+	})
+}
+
+// To приводит схему к указанной версии, применяя или откатывая шаги.
+// Версия 0 означает пустую схему.
+func (m *Migrator) To(ctx context.Context, version uint) error {
+	if version != 0 && !m.known(version) {
+		return errors.WithMessagef(ErrUnknownVersion, "version %v", version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.steps) - 1; i >= 0; i-- {
+			step := m.steps[i]
+			if _, ok := applied[step.Version]; ok && step.Version > version {
+				if err := m.run(ctx, conn, step, false); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, step := range m.steps {
+			if _, ok := applied[step.Version]; !ok && step.Version <= version {
+				if err := m.run(ctx, conn, step, true); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status возвращает список всех миграций с отметкой о применении.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	result := []Status{}
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, step := range m.steps {
+			status := Status{Version: step.Version, Name: step.Name}
+			if appliedAt, ok := applied[step.Version]; ok {
+				at := appliedAt
+				status.Applied = true
+				status.AppliedAt = &at
+			}
+			result = append(result, status)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (m *Migrator) known(version uint) bool {
+	for _, step := range m.steps {
+		if step.Version == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withLock выполняет fn на выделенном соединении, удерживая advisory lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get connection for migration")
+	}
+	defer conn.Close()
+
+	m.log.Debug("acquiring migration lock", zap.Int64("key", lockKey))
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return errors.Wrap(err, "acquire migration lock")
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey); err != nil {
+			m.log.Error("release migration lock", zap.Error(err))
+		}
+	}()
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %v (
+			version BIGINT PRIMARY KEY NOT NULL,
+			name TEXT NOT NULL,
+			applied_at timestamptz NOT NULL
+		)`,
+		MigrationTable,
+	)
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return errors.Wrapf(err, "create table %v", MigrationTable)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[uint]time.Time, error) {
+	query := fmt.Sprintf(`SELECT version, applied_at FROM %v`, MigrationTable)
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "query applied migrations")
+	}
+	defer rows.Close()
+
+	applied := map[uint]time.Time{}
+	for rows.Next() {
+		var (
+			version   uint
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, errors.Wrap(err, "scan applied migration")
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, errors.Wrap(rows.Err(), "read applied migrations")
+}
+
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, step Step, up bool) (err error) {
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+	m.log.Info("running migration",
+		zap.Uint("version", step.Version),
+		zap.String("name", step.Name),
+		zap.String("direction", direction),
+	)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin migration transaction")
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if up {
+		err = step.Up(ctx, tx)
+	} else {
+		err = step.Down(ctx, tx)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "migration %v %v (%v)", direction, step.Version, step.Name)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx,
+			fmt.Sprintf(`INSERT INTO %v (version, name, applied_at) VALUES ($1, $2, $3)`, MigrationTable),
+			step.Version, step.Name, time.Now(),
+		)
+	} else {
+		_, err = tx.ExecContext(ctx,
+			fmt.Sprintf(`DELETE FROM %v WHERE version = $1`, MigrationTable),
+			step.Version,
+		)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "record migration %v", step.Version)
+	}
+
+	return errors.Wrapf(tx.Commit(), "commit migration %v", step.Version)
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (user.Principal, error)
+}
+
+// TokenVerifierFunc позволяет использовать функцию как TokenVerifier.
+type TokenVerifierFunc func(ctx context.Context, token string) (user.Principal, error)
+
+func (f TokenVerifierFunc) Verify(ctx context.Context, token string) (user.Principal, error) {
+	return f(ctx, token)
+}
+
+type principalKey struct{}
+
+var (
+	errMissingToken      = apperr.Unauthorized("missing_token", "authorization header with bearer token is required")
+	errInsufficientScope = apperr.Forbidden("insufficient_scope", "token does not grant the required scope")
+)
+
+// authenticate пропускает только запросы с действительным токеном и кладёт
+// пользователя и его scopes в контекст запроса.
+func authenticate(verifier TokenVerifier, log *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="notes"`)
+				writeError(w, r, log, errMissingToken)
+				return
+			}
+
+			principal, err := verifier.Verify(r.Context(), token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="notes", error="invalid_token", error_description="the access token is invalid or expired"`)
+				writeError(w, r, log, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), principalKey{}, principal)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requireScope отвечает 403, если у токена нет scope, нужного маршруту.
+// Используется после authenticate.
+func requireScope(scope string, log *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !principalFromContext(r.Context()).HasScope(scope) {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="notes", error="insufficient_scope", scope=%q`, scope))
+				writeError(w, r, log, errInsufficientScope.WithMessage(fmt.Sprintf("token does not grant scope %q", scope)))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
+
+// principalFromContext пользователь и scopes, проверенные authenticate.
+func principalFromContext(ctx context.Context) user.Principal {
+	p, _ := ctx.Value(principalKey{}).(user.Principal)
+	return p
+}
+
+// userIDFromContext ID пользователя, проверенный authenticate.
+func userIDFromContext(ctx context.Context) uuid.UUID {
+	return principalFromContext(ctx).UserID
+}
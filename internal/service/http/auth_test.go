@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+func TestAuthenticate(t *testing.T) {
+	userID := uuid.FromStringOrNil("0190f1f4-6d0e-7c2c-8f4b-000000000001")
+	verifier := TokenVerifierFunc(func(ctx context.Context, token string) (user.Principal, error) {
+		if token != "good" {
+			return user.Principal{}, users.ErrInvalidToken
+		}
+		return user.Principal{UserID: userID, Scopes: []string{user.ScopeNotesRead}}, nil
+	})
+
+	var got user.Principal
+	protected := func(scope string) http.Handler {
+		return authenticate(verifier, zap.NewNop())(
+			requireScope(scope, zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = principalFromContext(r.Context())
+				w.WriteHeader(http.StatusNoContent)
+			})),
+		)
+	}
+	handler, readOnly := protected(user.ScopeNotesWrite), protected(user.ScopeNotesRead)
+
+	tests := []struct {
+		name          string
+		handler       http.Handler
+		authorization string
+		status        int
+		code          string
+		challenge     string
+	}{
+		{
+			name:      "MissingHeader",
+			handler:   handler,
+			status:    http.StatusUnauthorized,
+			code:      "missing_token",
+			challenge: `Bearer realm="notes"`,
+		},
+		{
+			name:          "NotBearer",
+			handler:       handler,
+			authorization: "Basic Z29vZA==",
+			status:        http.StatusUnauthorized,
+			code:          "missing_token",
+			challenge:     `Bearer realm="notes"`,
+		},
+		{
+			name:          "InvalidToken",
+			handler:       handler,
+			authorization: "Bearer bad",
+			status:        http.StatusUnauthorized,
+			code:          "invalid_token",
+			challenge:     `Bearer realm="notes", error="invalid_token", error_description="the access token is invalid or expired"`,
+		},
+		{
+			name:          "InsufficientScope",
+			handler:       handler,
+			authorization: "Bearer good",
+			status:        http.StatusForbidden,
+			code:          "insufficient_scope",
+			challenge:     `Bearer realm="notes", error="insufficient_scope", scope="notes:write"`,
+		},
+		{
+			name:          "Granted",
+			handler:       readOnly,
+			authorization: "bearer  good ",
+			status:        http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got = user.Principal{}
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/note", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %v, want %v", w.Code, tt.status)
+			}
+			if challenge := w.Header().Get("WWW-Authenticate"); challenge != tt.challenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", challenge, tt.challenge)
+			}
+			if tt.code == "" {
+				if got.UserID != userID {
+					t.Errorf("principal = %+v, want user %v", got, userID)
+				}
+				return
+			}
+
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			if problem.Code != tt.code || problem.Status != tt.status {
+				t.Errorf("problem = %+v, want code %v", problem, tt.code)
+			}
+			if got.UserID != uuid.Nil {
+				t.Errorf("handler called with %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type BatchAction interface {
+	Do(ctx context.Context, args notes.BatchArgs) (notes.BatchOutcome, error)
+}
+
+type BatchNotesRequest struct {
+	Operations []notes.BatchOperation `json:"operations"`
+	// ContinueOnError сохранить удавшиеся операции, даже если другие не удались.
+	ContinueOnError bool `json:"continueOnError"`
+}
+
+type BatchNotesResponse struct {
+	// Committed изменения сохранены. Без continueOnError false означает, что
+	// не сохранено ничего.
+	Committed bool                  `json:"committed"`
+	Results   []BatchResultResponse `json:"results"`
+}
+
+// BatchResultResponse итог операции с тем же индексом в запросе.
+type BatchResultResponse struct {
+	Index int           `json:"index"`
+	Op    notes.BatchOp `json:"op"`
+	ID    uuid.UUID     `json:"id,omitempty"`
+	// Status HTTP-статус, который получила бы операция отдельным запросом.
+	Status int        `json:"status"`
+	Note   *note.Note `json:"note,omitempty"`
+	Error  *Problem   `json:"error,omitempty"`
+}
+
+type BatchNotesHandler struct {
+	action BatchAction
+	log    *zap.Logger
+}
+
+func NewBatchNotesHandler(action BatchAction, log *zap.Logger) *BatchNotesHandler {
+	return &BatchNotesHandler{action: action, log: log}
+}
+
+func (h *BatchNotesHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var requestParams BatchNotesRequest
+	if err := readJSON(r, &requestParams); err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	outcome, err := h.action.Do(ctx, notes.BatchArgs{
+		UserID:          userIDFromContext(ctx),
+		Operations:      requestParams.Operations,
+		ContinueOnError: requestParams.ContinueOnError,
+	})
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	response := BatchNotesResponse{
+		Committed: outcome.Committed,
+		Results:   make([]BatchResultResponse, len(outcome.Results)),
+	}
+	for i, result := range outcome.Results {
+		item := BatchResultResponse{Index: i, Op: result.Op, ID: result.ID, Note: result.Note}
+		switch {
+		case result.Err != nil:
+			problem := problemFor(r, h.log, result.Err)
+			item.Status, item.Error = problem.Status, &problem
+		case result.Op == notes.BatchOpCreate:
+			item.Status = http.StatusCreated
+		case result.Op == notes.BatchOpDelete:
+			item.Status = http.StatusNoContent
+		default:
+			item.Status = http.StatusOK
+		}
+		response.Results[i] = item
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, response)
+}
@@ -2,8 +2,6 @@ package http
 
 import (
 	"context"
-	"encoding/json"
-	"io"
 	"net/http"
 
 	"github.com/victor8titov/rest-api-notes/internal/action/notes"
@@ -25,52 +23,23 @@ func NewCreateNoteHandler(action CreateAction, log *zap.Logger) *CreateNoteHandl
 }
 
 func (h *CreateNoteHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	contentType := r.Header.Get("Content-Type")
-	if contentType != "application/json" {
-		h.log.Debug("invalid Content-Type header", zap.Any("contentType", contentType))
-		http.Error(w, "invalid request header", http.StatusBadRequest)
-		return
-	}
-
-	body, err := io.ReadAll(r.Body)
-	defer r.Body.Close()
-
-	if err != nil {
-		h.log.Debug("invalid request body", zap.Any("body", body), zap.Error(err))
-		http.Error(w, "invalid request params", http.StatusBadRequest)
-		return
-	}
-
 	var requestParams notes.CreateArgs
-	err = json.Unmarshal(body, &requestParams)
+	err := readJSON(r, &requestParams)
 	if err != nil {
-		h.log.Debug("failed unmarshal request body", zap.Any("body", body), zap.Error(err))
-		http.Error(w, "invalid request params", http.StatusBadRequest)
+		writeError(w, r, h.log, err)
 		return
 	}
 
 	ctx := r.Context()
+	requestParams.OwnerID = userIDFromContext(ctx)
 	newNote, err := h.action.Do(ctx, requestParams)
 	if err != nil {
-		h.log.Debug("failed create action", zap.Error(err))
-		http.Error(w, "failed during creating", http.StatusInternalServerError)
+		writeError(w, r, h.log, err)
 		return
 	}
 
-	res, err := json.Marshal(newNote)
-	if err != nil {
-		h.log.Debug("failed marshal new note", zap.Any("new note", newNote), zap.Error(err))
-		http.Error(w, "failed during creating", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	_, err = w.Write(res)
-	if err != nil {
-		h.log.Debug("failed during write response", zap.Error(err))
-		http.Error(w, "failed during creating", http.StatusInternalServerError)
-		return
-	}
+	setNoteHeaders(w, newNote)
+	writeJSON(w, r, h.log, http.StatusOK, newNote)
 
 	h.log.Debug("Handled create note", zap.Any("newNote", newNote))
 }
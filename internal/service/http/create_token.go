@@ -0,0 +1,41 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+type CreateTokenAction interface {
+	Do(ctx context.Context, principal user.Principal, args tokens.CreateArgs) (user.IssuedAPIToken, error)
+}
+
+type CreateTokenHandler struct {
+	action CreateTokenAction
+	log    *zap.Logger
+}
+
+func NewCreateTokenHandler(action CreateTokenAction, log *zap.Logger) *CreateTokenHandler {
+	return &CreateTokenHandler{action: action, log: log}
+}
+
+func (h *CreateTokenHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var requestParams tokens.CreateArgs
+	err := readJSON(r, &requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	token, err := h.action.Do(ctx, principalFromContext(ctx), requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusCreated, token)
+}
@@ -2,20 +2,22 @@ package http
 
 import (
 	"context"
-	"encoding/json"
-	"io"
+	"fmt"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
 	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
 	"go.uber.org/zap"
 )
 
 type DeleteAction interface {
-	Do(ctx context.Context, noteIDs []uuid.UUID) error
+	Do(ctx context.Context, ownerID uuid.UUID, targets []notes.DeleteTarget) error
 }
 
 type DeleteRequest struct {
-	NoteID []uuid.UUID `json:"noteId"`
+	// Notes заметки с версиями из их ETag, версия обязательна, как If-Match.
+	Notes []notes.DeleteTarget `json:"notes"`
 }
 
 type DeleteNoteByIDHandler struct {
@@ -31,41 +33,68 @@ func NewDeleteNoteByIDHandler(action DeleteAction, log *zap.Logger) *DeleteNoteB
 }
 
 func (h *DeleteNoteByIDHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	contentType := r.Header.Get("Content-Type")
-	if contentType != "application/json" {
-		h.log.Debug("invalid Content-Type header", zap.Any("contentType", contentType))
-		http.Error(w, "invalid request header", http.StatusBadRequest)
+	var requestParams DeleteRequest
+	err := readJSON(r, &requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
 		return
 	}
 
-	body, err := io.ReadAll(r.Body)
-	defer r.Body.Close()
+	if len(requestParams.Notes) == 0 {
+		writeError(w, r, h.log, errInvalidParams.WithField("notes", "is required"))
+		return
+	}
+	for i, target := range requestParams.Notes {
+		if target.Version == 0 {
+			writeError(w, r, h.log, errInvalidParams.WithField(fmt.Sprintf("notes[%v].version", i), "is required"))
+			return
+		}
+	}
 
+	ctx := r.Context()
+	err = h.action.Do(ctx, userIDFromContext(ctx), requestParams.Notes)
 	if err != nil {
-		h.log.Debug("invalid request body", zap.Any("body", body), zap.Error(err))
-		http.Error(w, "invalid request params", http.StatusBadRequest)
+		writeError(w, r, h.log, err)
 		return
 	}
+}
 
-	var requestParams DeleteRequest
-	err = json.Unmarshal(body, &requestParams)
+type DeleteByIDAction interface {
+	Do(ctx context.Context, ownerID, noteID uuid.UUID, version uint) error
+}
+
+// DeleteNoteHandler удаляет одну заметку, требует If-Match с её ETag.
+type DeleteNoteHandler struct {
+	action DeleteByIDAction
+	log    *zap.Logger
+}
+
+func NewDeleteNoteHandler(action DeleteByIDAction, log *zap.Logger) *DeleteNoteHandler {
+	return &DeleteNoteHandler{
+		action: action,
+		log:    log,
+	}
+}
+
+func (h *DeleteNoteHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
 	if err != nil {
-		h.log.Debug("failed unmarshal request body", zap.Any("body", body), zap.Error(err))
-		http.Error(w, "invalid request params", http.StatusBadRequest)
+		writeError(w, r, h.log, err)
 		return
 	}
 
-	if len(requestParams.NoteID) == 0 {
-		h.log.Debug("failed unmarshal request body", zap.Any("body", body), zap.Error(err))
-		http.Error(w, "invalid request params", http.StatusBadRequest)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, h.log, err)
 		return
 	}
 
 	ctx := r.Context()
-	err = h.action.Do(ctx, requestParams.NoteID)
+	err = h.action.Do(ctx, userIDFromContext(ctx), id, version)
 	if err != nil {
-		h.log.Debug("failed deleting action", zap.Error(err))
-		http.Error(w, "failed during deleting", http.StatusInternalServerError)
+		writeError(w, r, h.log, err)
 		return
 	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
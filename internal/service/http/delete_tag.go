@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	uuid "github.com/satori/go.uuid"
+	"go.uber.org/zap"
+)
+
+type DeleteTagAction interface {
+	Do(ctx context.Context, ownerID uuid.UUID, tag string) error
+}
+
+type DeleteTagHandler struct {
+	action DeleteTagAction
+	log    *zap.Logger
+}
+
+func NewDeleteTagHandler(action DeleteTagAction, log *zap.Logger) *DeleteTagHandler {
+	return &DeleteTagHandler{action: action, log: log}
+}
+
+func (h *DeleteTagHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	tag, err := tagFromPath(r)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	err = h.action.Do(ctx, userIDFromContext(ctx), tag)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tagFromPath тег из пути запроса, в пути он может быть закодирован.
+func tagFromPath(r *http.Request) (string, error) {
+	tag, err := url.PathUnescape(chi.URLParam(r, "tag"))
+	if err != nil {
+		return "", errInvalidParams.WithField("tag", "is not a valid path segment").WithCause(err)
+	}
+
+	return tag, nil
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"go.uber.org/zap"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem ответ с ошибкой по RFC 7807. Code стабилен, клиенты могут на него опираться.
+type Problem struct {
+	Type          string              `json:"type"`
+	Title         string              `json:"title"`
+	Status        int                 `json:"status"`
+	Detail        string              `json:"detail,omitempty"`
+	Instance      string              `json:"instance,omitempty"`
+	Code          string              `json:"code"`
+	RequestID     string              `json:"requestId,omitempty"`
+	InvalidParams []apperr.FieldError `json:"invalidParams,omitempty"`
+}
+
+var (
+	errInvalidContentType = apperr.Validation("invalid_content_type", "Content-Type must be application/json")
+	errInvalidBody        = apperr.Validation("invalid_body", "request body is not valid JSON")
+	errInvalidParams      = apperr.Validation("invalid_params", "invalid request params")
+	errInternal           = apperr.New(apperr.KindInternal, "internal", "failed during inner process")
+)
+
+var kindStatus = map[apperr.Kind]int{
+	apperr.KindValidation:           http.StatusBadRequest,
+	apperr.KindNotFound:             http.StatusNotFound,
+	apperr.KindConflict:             http.StatusConflict,
+	apperr.KindUnauthorized:         http.StatusUnauthorized,
+	apperr.KindForbidden:            http.StatusForbidden,
+	apperr.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperr.KindPreconditionRequired: http.StatusPreconditionRequired,
+	apperr.KindUnprocessable:        http.StatusUnprocessableEntity,
+	apperr.KindTooLarge:             http.StatusRequestEntityTooLarge,
+	apperr.KindInternal:             http.StatusInternalServerError,
+}
+
+// writeError единая точка превращения ошибки в ответ. Ошибки, не являющиеся
+// apperr.Error, считаются внутренними: клиент видит только общий текст.
+func writeError(w http.ResponseWriter, r *http.Request, log *zap.Logger, err error) {
+	problem := problemFor(r, log, err)
+	status := problem.Status
+
+	res, err := json.Marshal(problem)
+	if err != nil {
+		log.Error("failed marshal problem", zap.Error(err))
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(status)
+	w.Write(res)
+}
+
+// problemFor описывает ошибку для клиента, его же использует пакетный
+// запрос для ошибок отдельных операций.
+func problemFor(r *http.Request, log *zap.Logger, err error) Problem {
+	appErr, ok := apperr.As(err)
+	if !ok {
+		log.Error("failed during inner process", zap.Error(err), zap.String("requestId", middleware.GetReqID(r.Context())))
+		appErr = errInternal
+	} else {
+		log.Debug("request failed", zap.Error(err))
+	}
+
+	status, ok := kindStatus[appErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	return Problem{
+		Type:          "urn:notes:problem:" + appErr.Code,
+		Title:         http.StatusText(status),
+		Status:        status,
+		Detail:        appErr.Message,
+		Instance:      r.URL.Path,
+		Code:          appErr.Code,
+		RequestID:     middleware.GetReqID(r.Context()),
+		InvalidParams: appErr.Fields,
+	}
+}
+
+// requestIDHeader возвращает клиенту X-Request-Id, выданный middleware.RequestID.
+func requestIDHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(middleware.RequestIDHeader, id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
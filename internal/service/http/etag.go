@@ -0,0 +1,73 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+)
+
+var (
+	errIfMatchRequired = apperr.PreconditionRequired("if_match_required", "If-Match header with the note ETag is required")
+	errInvalidIfMatch  = apperr.Validation("invalid_if_match", "If-Match must contain a single ETag or *")
+)
+
+// noteETag сильный ETag заметки, меняется вместе с её версией.
+func noteETag(n note.Note) string {
+	return fmt.Sprintf(`"%v"`, n.Version)
+}
+
+// setNoteHeaders заголовки валидации ответа с одной заметкой.
+func setNoteHeaders(w http.ResponseWriter, n note.Note) {
+	w.Header().Set("ETag", noteETag(n))
+	w.Header().Set("Last-Modified", n.UpdatedAt.UTC().Format(http.TimeFormat))
+}
+
+// ifMatchVersion версия заметки из If-Match. Для "*" возвращает 0: подходит
+// любая версия существующей заметки.
+func ifMatchVersion(r *http.Request) (uint, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, errIfMatchRequired
+	}
+	if header == "*" {
+		return 0, nil
+	}
+	if strings.Contains(header, ",") {
+		return 0, errInvalidIfMatch
+	}
+
+	// If-Match сравнивает теги строго, слабый тег не совпадает никогда.
+	unquoted, err := strconv.Unquote(header)
+	if err != nil || strings.HasPrefix(header, "W/") {
+		return 0, notes.ErrVersionMismatch
+	}
+	version, err := strconv.ParseUint(unquoted, 10, 32)
+	if err != nil || version == 0 {
+		return 0, notes.ErrVersionMismatch
+	}
+
+	return uint(version), nil
+}
+
+// noneMatch проверяет If-None-Match слабым сравнением: true, если у клиента
+// уже есть представление с тегом etag.
+func noneMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
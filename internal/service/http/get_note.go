@@ -2,29 +2,37 @@ package http
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
+	"io"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	uuid "github.com/satori/go.uuid"
-	"github.com/victor8titov/rest-api-notes/internal/action/notes"
 	"github.com/victor8titov/rest-api-notes/internal/entity/note"
 	"go.uber.org/zap"
 )
 
 type GetByIDAction interface {
-	Do(ctx context.Context, noteID uuid.UUID) (note.Note, error)
+	Do(ctx context.Context, ownerID, noteID uuid.UUID) (note.Note, error)
 }
 
+type RenderNoteAction interface {
+	Do(ctx context.Context, userID, noteID uuid.UUID) (note.RenderedNote, error)
+}
+
+// renderedNoteCSP запрещает всё, кроме картинок, на случай если очистка HTML
+// что-то пропустит.
+const renderedNoteCSP = "default-src 'none'; img-src https: data:"
+
 type GetByIDHandler struct {
 	action GetByIDAction
+	render RenderNoteAction
 	log    *zap.Logger
 }
 
-func NewGetByIDHandler(action GetByIDAction, log *zap.Logger) *GetByIDHandler {
+func NewGetByIDHandler(action GetByIDAction, render RenderNoteAction, log *zap.Logger) *GetByIDHandler {
 	return &GetByIDHandler{
 		action: action,
+		render: render,
 		log:    log,
 	}
 }
@@ -34,38 +42,76 @@ func (h *GetByIDHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	h.log.Debug("hadle get note by id", zap.Any("note id", noteID))
 
-	id, err := uuid.FromString(noteID)
+	id, err := parseNoteID(noteID)
 	if err != nil {
-		h.log.Debug("failed to covert type string to uuid")
-		http.Error(w, "invalid request params", http.StatusBadRequest)
+		writeError(w, r, h.log, err)
 		return
 	}
 
-	ctx := r.Context()
-	note, err := h.action.Do(ctx, id)
-	if err != nil && (errors.Is(err, notes.NotFound) || err.Error() == notes.NotFound.Error()) {
-		http.Error(w, "Not Found", http.StatusNotFound)
+	query := newQueryParser(r.URL.Query())
+	render := query.enum("render", "", formatHTML)
+	if err := query.err(); err != nil {
+		writeError(w, r, h.log, err)
 		return
 	}
 
-	if err != nil {
-		h.log.Debug("failed during action doing", zap.Error(err))
-		http.Error(w, "failed during inner process", http.StatusInternalServerError)
+	w.Header().Set("Vary", "Accept")
+	if htmlOnly := render == "" && acceptedFormat(r) == formatHTML; htmlOnly || render == formatHTML {
+		h.handleRendered(w, r, id, htmlOnly)
 		return
 	}
 
-	res, err := json.Marshal(note)
+	ctx := r.Context()
+	note, err := h.action.Do(ctx, userIDFromContext(ctx), id)
 	if err != nil {
-		h.log.Debug("failed marshal note", zap.Any("note", note), zap.Error(err))
-		http.Error(w, "failed during inner process", http.StatusInternalServerError)
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	setNoteHeaders(w, note)
+	if noneMatch(r, noteETag(note)) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_, err = w.Write(res)
+	writeJSON(w, r, h.log, http.StatusOK, note)
+}
+
+// handleRendered отвечает заметкой с HTML и оглавлением (?render=html) или,
+// если htmlOnly, только HTML текста заметки (Accept: text/html).
+func (h *GetByIDHandler) handleRendered(w http.ResponseWriter, r *http.Request, id uuid.UUID, htmlOnly bool) {
+	ctx := r.Context()
+	rendered, err := h.render.Do(ctx, userIDFromContext(ctx), id)
 	if err != nil {
-		h.log.Debug("failed during write response", zap.Error(err))
-		http.Error(w, "failed during creating", http.StatusInternalServerError)
+		writeError(w, r, h.log, err)
 		return
 	}
+
+	setNoteHeaders(w, rendered.Note)
+	if noneMatch(r, noteETag(rendered.Note)) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if !htmlOnly {
+		writeJSON(w, r, h.log, http.StatusOK, rendered)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", renderedNoteCSP)
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.WriteString(w, rendered.HTML); err != nil {
+		h.log.Debug("failed during write response", zap.Error(err))
+	}
+}
+
+// parseNoteID разбирает ID заметки из пути запроса.
+func parseNoteID(value string) (uuid.UUID, error) {
+	id, err := uuid.FromString(value)
+	if err != nil {
+		return uuid.Nil, errInvalidParams.WithField("noteID", "must be a UUID").WithCause(err)
+	}
+
+	return id, nil
 }
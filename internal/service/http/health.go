@@ -0,0 +1,32 @@
+package http
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+type HealthHandler struct {
+	ready *atomic.Bool
+}
+
+func NewHealthHandler(ready *atomic.Bool) *HealthHandler {
+	return &HealthHandler{ready: ready}
+}
+
+// Live процесс запущен и отвечает.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// Ready сервис готов принимать трафик. После SIGTERM отвечает 503,
+// чтобы балансировщик перестал направлять сюда запросы.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if !h.ready.Load() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ready"))
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"go.uber.org/zap"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader отмечает ответ, повторённый из сохранённого.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// replayedHeaders заголовки ответа, которые сохраняются вместе с телом.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag", "Last-Modified"}
+
+var errBodyTooLarge = apperr.New(apperr.KindTooLarge, "body_too_large", "request body is too large")
+
+type IdempotencyKeeper interface {
+	Begin(ctx context.Context, userID uuid.UUID, key string, requestHash []byte) (*idempotency.Response, error)
+	Complete(ctx context.Context, userID uuid.UUID, key string, response idempotency.Response) error
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+}
+
+// idempotencyKeys повторяет первый ответ на запрос с тем же Idempotency-Key
+// вместо повторного выполнения. Запросы без заголовка проходят как есть.
+// Ответы 5xx, ответы больше maxBodySize и прерванные паникой не сохраняются,
+// чтобы запрос можно было повторить. Тело запроса больше maxBodySize даёт 413.
+// Используется после authenticate: ключи у каждого пользователя свои.
+func idempotencyKeys(keeper IdempotencyKeeper, maxBodySize int64, log *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+			r.Body.Close()
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, r, log, errBodyTooLarge.WithCause(err))
+				return
+			}
+			if err != nil {
+				writeError(w, r, log, errInvalidBody.WithCause(err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			userID := userIDFromContext(r.Context())
+			stored, err := keeper.Begin(r.Context(), userID, key, requestHash(r, body))
+			if err != nil {
+				writeError(w, r, log, err)
+				return
+			}
+			if stored != nil {
+				replay(w, *stored)
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK, limit: int(maxBodySize)}
+			completed := false
+			// Клиент мог уже отключиться, а обработчик упасть с паникой, но ключ
+			// всё равно нужно освободить или заполнить, иначе повтор получит 409
+			// до истечения ttl.
+			defer func() {
+				ctx := context.Background()
+				if !completed || recorder.truncated || recorder.status >= http.StatusInternalServerError {
+					if err := keeper.Release(ctx, userID, key); err != nil {
+						log.Error("failed release idempotency key", zap.Error(err))
+					}
+					return
+				}
+
+				response := idempotency.Response{Status: recorder.status, Header: map[string]string{}, Body: recorder.body.Bytes()}
+				for _, name := range replayedHeaders {
+					if value := w.Header().Get(name); value != "" {
+						response.Header[name] = value
+					}
+				}
+				if err := keeper.Complete(ctx, userID, key, response); err != nil {
+					log.Error("failed save idempotent response", zap.Error(err))
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+			completed = true
+		})
+	}
+}
+
+// requestHash отпечаток запроса: тот же ключ с другим методом, адресом или
+// телом считается другим запросом.
+func requestHash(r *http.Request, body []byte) []byte {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+
+	return hash.Sum(nil)
+}
+
+func replay(w http.ResponseWriter, response idempotency.Response) {
+	for name, value := range response.Header {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(response.Status)
+	w.Write(response.Body)
+}
+
+// responseRecorder передаёт ответ клиенту и запоминает статус и тело.
+// Тело больше limit не запоминается, отмечается truncated.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	limit       int
+	truncated   bool
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	if !r.truncated {
+		if r.body.Len()+len(data) > r.limit {
+			r.truncated = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(data)
+		}
+	}
+
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,275 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency"
+	"github.com/victor8titov/rest-api-notes/internal/adaptor"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+var (
+	alice = uuid.FromStringOrNil("0190f1f4-6d0e-7c2c-8f4b-000000000001")
+	bob   = uuid.FromStringOrNil("0190f1f4-6d0e-7c2c-8f4b-000000000002")
+)
+
+const testMaxBodySize = 1024
+
+// idempotencyServer middleware с хранилищем в памяти перед обработчиком next.
+func idempotencyServer(next http.HandlerFunc) http.Handler {
+	keeper := idempotency.NewKeeper(adaptor.NewMemoryIdempotencyStore(zap.NewNop()), time.Hour, zap.NewNop())
+	return idempotencyKeys(keeper, testMaxBodySize, zap.NewNop())(next)
+}
+
+func keyedRequest(userID uuid.UUID, path, key, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), principalKey{}, user.Principal{UserID: userID}))
+}
+
+func serve(handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w
+}
+
+func assertProblem(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+
+	if w.Code != status {
+		t.Fatalf("status = %v, want %v: %v", w.Code, status, w.Body)
+	}
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem.Code != code {
+		t.Errorf("code = %v, want %v", problem.Code, code)
+	}
+}
+
+// created отвечает 201 с номером вызова, чтобы повтор можно было отличить от нового выполнения.
+func created(calls *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/api/v1/note/1")
+		w.Header().Set("X-Not-Stored", "1")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"call": n, "body": string(body)})
+	}
+}
+
+func TestIdempotencyKeysReplay(t *testing.T) {
+	var calls int32
+	handler := idempotencyServer(created(&calls))
+
+	first := serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{"label":"a"}`))
+	if first.Code != http.StatusCreated || first.Header().Get(idempotentReplayedHeader) != "" {
+		t.Fatalf("first: status %v, replayed %q", first.Code, first.Header().Get(idempotentReplayedHeader))
+	}
+
+	second := serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{"label":"a"}`))
+	if second.Code != http.StatusCreated {
+		t.Fatalf("replay: status %v, want %v", second.Code, http.StatusCreated)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("replay body = %q, want %q", second.Body, first.Body)
+	}
+	if second.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("replay %v = %q, want true", idempotentReplayedHeader, second.Header().Get(idempotentReplayedHeader))
+	}
+	for _, name := range []string{"Content-Type", "Location"} {
+		if second.Header().Get(name) != first.Header().Get(name) {
+			t.Errorf("replay %v = %q, want %q", name, second.Header().Get(name), first.Header().Get(name))
+		}
+	}
+	if second.Header().Get("X-Not-Stored") != "" {
+		t.Errorf("replay X-Not-Stored = %q, want empty", second.Header().Get("X-Not-Stored"))
+	}
+
+	// Ключи у каждого пользователя свои, запрос без ключа выполняется всегда.
+	serve(handler, keyedRequest(bob, "/api/v1/note", "key-1", `{"label":"a"}`))
+	serve(handler, keyedRequest(alice, "/api/v1/note", "", `{"label":"a"}`))
+	if calls != 3 {
+		t.Errorf("handler called %v times, want 3", calls)
+	}
+}
+
+func TestIdempotencyKeysReused(t *testing.T) {
+	var calls int32
+	handler := idempotencyServer(created(&calls))
+
+	serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{"label":"a"}`))
+	assertProblem(t, serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{"label":"b"}`)),
+		http.StatusUnprocessableEntity, "idempotency_key_reused")
+	assertProblem(t, serve(handler, keyedRequest(alice, "/api/v1/note/batch", "key-1", `{"label":"a"}`)),
+		http.StatusUnprocessableEntity, "idempotency_key_reused")
+	if calls != 1 {
+		t.Errorf("handler called %v times, want 1", calls)
+	}
+}
+
+func TestIdempotencyKeysInvalid(t *testing.T) {
+	var calls int32
+	handler := idempotencyServer(created(&calls))
+
+	assertProblem(t, serve(handler, keyedRequest(alice, "/api/v1/note", strings.Repeat("k", 256), `{}`)),
+		http.StatusBadRequest, "invalid_idempotency_key")
+	if calls != 0 {
+		t.Errorf("handler called %v times, want 0", calls)
+	}
+}
+
+func TestIdempotencyKeysInProgress(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	var calls int32
+	handler := idempotencyServer(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		created(&calls)(w, r)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{}`))
+	}()
+	<-entered
+
+	assertProblem(t, serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{}`)),
+		http.StatusConflict, "idempotency_key_in_progress")
+
+	close(release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Fatalf("first: status %v, want %v", first.Code, http.StatusCreated)
+	}
+
+	replayed := serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{}`))
+	if replayed.Code != http.StatusCreated || replayed.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("after completion: status %v, replayed %q", replayed.Code, replayed.Header().Get(idempotentReplayedHeader))
+	}
+	if calls != 1 {
+		t.Errorf("handler called %v times, want 1", calls)
+	}
+}
+
+func TestIdempotencyKeysServerError(t *testing.T) {
+	var calls int32
+	handler := idempotencyServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			writeError(w, r, zap.NewNop(), io.ErrUnexpectedEOF)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	assertProblem(t, serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{}`)),
+		http.StatusInternalServerError, "internal")
+
+	retried := serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{}`))
+	if retried.Code != http.StatusCreated || retried.Header().Get(idempotentReplayedHeader) != "" {
+		t.Errorf("retry: status %v, replayed %q, want new execution", retried.Code, retried.Header().Get(idempotentReplayedHeader))
+	}
+	if calls != 2 {
+		t.Errorf("handler called %v times, want 2", calls)
+	}
+}
+
+func TestIdempotencyKeysClientError(t *testing.T) {
+	var calls int32
+	handler := idempotencyServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeError(w, r, zap.NewNop(), errInvalidBody)
+	})
+
+	serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{`))
+	replayed := serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{`))
+	assertProblem(t, replayed, http.StatusBadRequest, "invalid_body")
+	if replayed.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("%v = %q, want true", idempotentReplayedHeader, replayed.Header().Get(idempotentReplayedHeader))
+	}
+	if calls != 1 {
+		t.Errorf("handler called %v times, want 1", calls)
+	}
+}
+
+func TestIdempotencyKeysRequestTooLarge(t *testing.T) {
+	var calls int32
+	handler := idempotencyServer(created(&calls))
+
+	assertProblem(t, serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", strings.Repeat("a", testMaxBodySize+1))),
+		http.StatusRequestEntityTooLarge, "body_too_large")
+	if calls != 0 {
+		t.Errorf("handler called %v times, want 0", calls)
+	}
+
+	// Ключ не занят отклонённым запросом.
+	if w := serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{}`)); w.Code != http.StatusCreated {
+		t.Errorf("after too large: status %v, want %v", w.Code, http.StatusCreated)
+	}
+}
+
+func TestIdempotencyKeysResponseTooLarge(t *testing.T) {
+	var calls int32
+	handler := idempotencyServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(strings.Repeat("a", testMaxBodySize)))
+		w.Write([]byte("a"))
+	})
+
+	first := serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{}`))
+	if first.Code != http.StatusCreated || first.Body.Len() != testMaxBodySize+1 {
+		t.Fatalf("first: status %v, body %v bytes", first.Code, first.Body.Len())
+	}
+
+	retried := serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{}`))
+	if retried.Header().Get(idempotentReplayedHeader) != "" {
+		t.Errorf("retry replayed a response larger than the limit")
+	}
+	if calls != 2 {
+		t.Errorf("handler called %v times, want 2", calls)
+	}
+}
+
+func TestIdempotencyKeysPanic(t *testing.T) {
+	var calls int32
+	handler := idempotencyServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			panic("handler failed")
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("panic did not reach the caller")
+			}
+		}()
+		serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{}`))
+	}()
+
+	retried := serve(handler, keyedRequest(alice, "/api/v1/note", "key-1", `{}`))
+	if retried.Code != http.StatusCreated || retried.Header().Get(idempotentReplayedHeader) != "" {
+		t.Errorf("retry after panic: status %v, replayed %q, want new execution", retried.Code, retried.Header().Get(idempotentReplayedHeader))
+	}
+	if calls != 2 {
+		t.Errorf("handler called %v times, want 2", calls)
+	}
+}
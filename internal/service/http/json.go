@@ -0,0 +1,47 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// readJSON проверяет Content-Type и разбирает тело запроса в dest.
+func readJSON(r *http.Request, dest any) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return errInvalidContentType
+	}
+
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return errInvalidBody.WithCause(err)
+	}
+
+	if err := json.Unmarshal(body, dest); err != nil {
+		return errInvalidBody.WithCause(err)
+	}
+
+	return nil
+}
+
+// writeJSON отправляет v со статусом status.
+func writeJSON(w http.ResponseWriter, r *http.Request, log *zap.Logger, status int, v any) {
+	res, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, r, log, errors.Wrap(err, "marshal response"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(res)
+	if err != nil {
+		log.Debug("failed during write response", zap.Error(err))
+	}
+}
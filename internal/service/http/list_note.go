@@ -2,11 +2,11 @@ package http
 
 import (
 	"context"
-	"encoding/json"
-	"io"
 	"net/http"
 
+	uuid "github.com/satori/go.uuid"
 	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/config"
 	"github.com/victor8titov/rest-api-notes/internal/entity/note"
 	"go.uber.org/zap"
 )
@@ -15,73 +15,114 @@ type ListAction interface {
 	Do(ctx context.Context, args notes.ListArgs) (note.ListNotes, error)
 }
 
-type RequestListNotes struct {
-	SortBy        string `json:"sortBy"`
-	SortDirection uint   `json:"direction"`
-	Offset        uint   `json:"offset"`
-	Limit         uint   `json:"limit"`
-}
+const (
+	directionAsc  = "asc"
+	directionDesc = "desc"
+)
 
 type ListNotesHandler struct {
-	action ListAction
-	log    *zap.Logger
+	action     ListAction
+	pagination config.PaginationConfig
+	// trash список корзины вместо обычных заметок.
+	trash bool
+	log   *zap.Logger
+}
+
+func NewListNotesHandler(action ListAction, pagination config.PaginationConfig, log *zap.Logger) *ListNotesHandler {
+	return &ListNotesHandler{action: action, pagination: pagination, log: log}
 }
 
-func NewListNotesHandler(action ListAction, log *zap.Logger) *ListNotesHandler {
-	return &ListNotesHandler{action: action, log: log}
+// NewListTrashHandler список заметок в корзине с теми же параметрами, что и
+// обычный список, кроме shared.
+func NewListTrashHandler(action ListAction, pagination config.PaginationConfig, log *zap.Logger) *ListNotesHandler {
+	return &ListNotesHandler{action: action, pagination: pagination, trash: true, log: log}
 }
 
 func (h *ListNotesHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	contentType := r.Header.Get("Content-Type")
-	if contentType != "application/json" {
-		h.log.Debug("invalid Content-Type header", zap.Any("contentType", contentType))
-		http.Error(w, "invalid request header", http.StatusBadRequest)
+	ctx := r.Context()
+	args, err := h.parse(newQueryParser(r.URL.Query()), userIDFromContext(ctx))
+	if err != nil {
+		writeError(w, r, h.log, err)
 		return
 	}
 
-	body, err := io.ReadAll(r.Body)
-	defer r.Body.Close()
-
+	list, err := h.action.Do(ctx, args)
 	if err != nil {
-		h.log.Debug("invalid request body", zap.Any("body", body), zap.Error(err))
-		http.Error(w, "invalid request params", http.StatusBadRequest)
+		writeError(w, r, h.log, err)
 		return
 	}
 
-	var requestParams RequestListNotes
-	err = json.Unmarshal(body, &requestParams)
-	if err != nil {
-		h.log.Debug("failed unmarshal request body", zap.Any("body", body), zap.Error(err))
-		http.Error(w, "invalid request params", http.StatusBadRequest)
-		return
+	writeJSON(w, r, h.log, http.StatusOK, list)
+}
+
+// parse разбирает параметры списка. shared=true вместо заметок userID
+// возвращает заметки, которыми с ним поделились. notebookId ограничивает
+// список блокнотом, recursive=true добавляет вложенные в него блокноты.
+// Архивные заметки попадают в список только с archived=true, в корзине
+// показываются все.
+func (h *ListNotesHandler) parse(query *queryParser, userID uuid.UUID) (notes.ListArgs, error) {
+	allowed := []string{"sortBy", "direction", "limit", "offset", "cursor", "withTotal", "tagsAll", "tagsAny", "tagsNone",
+		"modifiedSince", "createdBefore", "notebookId", "recursive", "pinned", "archived", "favorite"}
+	if !h.trash {
+		allowed = append(allowed, "shared")
 	}
+	query.allow(allowed...)
 
-	ctx := r.Context()
 	args := notes.ListArgs{
-		SortBy:        notes.SortField(requestParams.SortBy),
-		SortDirection: notes.SortDirection(requestParams.SortDirection),
-		Offset:        requestParams.Offset,
-		Limit:         requestParams.Limit,
+		Filter: notes.Filter{
+			TagsAll:       query.list("tagsAll"),
+			TagsAny:       query.list("tagsAny"),
+			TagsNone:      query.list("tagsNone"),
+			ModifiedSince: query.time("modifiedSince"),
+			CreatedBefore: query.time("createdBefore"),
+			Trashed:       h.trash,
+			NotebookID:    query.uuid("notebookId"),
+			Pinned:        query.bool("pinned"),
+			Archived:      query.bool("archived"),
+			Favorite:      query.bool("favorite"),
+		},
+		SortBy: notes.SortField(query.enum("sortBy", string(notes.SortFieldLabel),
+			string(notes.SortFieldLabel), string(notes.SortFieldDate), string(notes.SortFieldUpdated),
+		)),
+		Limit:  query.uint("limit", h.pagination.DefaultLimit, 1, h.pagination.MaxLimit),
+		Offset: query.uint("offset", 0, 0, maxOffset),
+		Cursor: query.string("cursor"),
 	}
-	list, err := h.action.Do(ctx, args)
-	if err != nil {
-		h.log.Debug("failed during action doing", zap.Error(err))
-		http.Error(w, "failed during inner process", http.StatusInternalServerError)
-		return
+
+	if query.enum("direction", directionAsc, directionAsc, directionDesc) == directionDesc {
+		args.SortDirection = notes.SortDirectionDesc
 	}
 
-	res, err := json.Marshal(list)
-	if err != nil {
-		h.log.Debug("failed marshal list note", zap.Any("list", list), zap.Error(err))
-		http.Error(w, "failed during inner process", http.StatusInternalServerError)
-		return
+	if args.Archived == nil && !h.trash {
+		archived := false
+		args.Archived = &archived
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_, err = w.Write(res)
-	if err != nil {
-		h.log.Debug("failed during write response", zap.Error(err))
-		http.Error(w, "failed during creating", http.StatusInternalServerError)
-		return
+	args.WithTotal = args.Cursor == ""
+	if withTotal := query.bool("withTotal"); withTotal != nil {
+		args.WithTotal = *withTotal
 	}
+
+	if recursive := query.bool("recursive"); recursive != nil {
+		args.Recursive = *recursive
+		if args.Recursive && args.NotebookID == uuid.Nil {
+			query.fail("recursive", "requires notebookId")
+		}
+	}
+
+	args.OwnerID = userID
+	if !h.trash {
+		if shared := query.bool("shared"); shared != nil && *shared {
+			args.OwnerID, args.SharedWith = uuid.Nil, userID
+			if args.NotebookID != uuid.Nil {
+				query.fail("notebookId", "can not be combined with shared")
+			}
+		}
+	}
+
+	if args.Cursor != "" && args.Offset > 0 {
+		query.fail("offset", "can not be combined with cursor")
+	}
+
+	return args, query.err()
 }
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type ListTagsAction interface {
+	Do(ctx context.Context, ownerID uuid.UUID) ([]note.Tag, error)
+}
+
+type ListTagsHandler struct {
+	action ListTagsAction
+	log    *zap.Logger
+}
+
+func NewListTagsHandler(action ListTagsAction, log *zap.Logger) *ListTagsHandler {
+	return &ListTagsHandler{action: action, log: log}
+}
+
+func (h *ListTagsHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tags, err := h.action.Do(ctx, userIDFromContext(ctx))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, tags)
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+type ListTokensAction interface {
+	Do(ctx context.Context, userID uuid.UUID) ([]user.APIToken, error)
+}
+
+type ListTokensHandler struct {
+	action ListTokensAction
+	log    *zap.Logger
+}
+
+func NewListTokensHandler(action ListTokensAction, log *zap.Logger) *ListTokensHandler {
+	return &ListTokensHandler{action: action, log: log}
+}
+
+func (h *ListTokensHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	list, err := h.action.Do(ctx, userIDFromContext(ctx))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, list)
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+type LoginAction interface {
+	Do(ctx context.Context, args users.LoginArgs) (user.Token, error)
+}
+
+type LoginHandler struct {
+	action LoginAction
+	log    *zap.Logger
+}
+
+func NewLoginHandler(action LoginAction, log *zap.Logger) *LoginHandler {
+	return &LoginHandler{action: action, log: log}
+}
+
+func (h *LoginHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var requestParams users.LoginArgs
+	err := readJSON(r, &requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	token, err := h.action.Do(ctx, requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, token)
+}
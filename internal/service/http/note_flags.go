@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type SetFlagAction interface {
+	Do(ctx context.Context, args notes.SetFlagArgs) (note.Note, error)
+}
+
+// NoteFlagHandler включает или выключает признак заметки из пути запроса,
+// требует If-Match с её ETag.
+type NoteFlagHandler struct {
+	action SetFlagAction
+	value  bool
+	log    *zap.Logger
+}
+
+// NewSetNoteFlagHandler включает признак: PUT /note/{noteID}/{flag}.
+func NewSetNoteFlagHandler(action SetFlagAction, log *zap.Logger) *NoteFlagHandler {
+	return &NoteFlagHandler{action: action, value: true, log: log}
+}
+
+// NewUnsetNoteFlagHandler выключает признак: DELETE /note/{noteID}/{flag}.
+func NewUnsetNoteFlagHandler(action SetFlagAction, log *zap.Logger) *NoteFlagHandler {
+	return &NoteFlagHandler{action: action, value: false, log: log}
+}
+
+func (h *NoteFlagHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	flag, err := parseFlag(chi.URLParam(r, "flag"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	flagged, err := h.action.Do(ctx, notes.SetFlagArgs{
+		OwnerID: userIDFromContext(ctx),
+		ID:      id,
+		Flag:    flag,
+		Value:   h.value,
+		Version: version,
+	})
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	setNoteHeaders(w, flagged)
+	writeJSON(w, r, h.log, http.StatusOK, flagged)
+}
+
+func parseFlag(value string) (notes.Flag, error) {
+	names := make([]string, len(notes.Flags))
+	for i, flag := range notes.Flags {
+		if string(flag) == value {
+			return flag, nil
+		}
+		names[i] = string(flag)
+	}
+
+	return "", errInvalidParams.WithField("flag", "must be one of "+strings.Join(names, ", "))
+}
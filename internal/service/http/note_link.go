@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+// NoteLink публичная ссылка с готовым путём для открытия заметки.
+type NoteLink struct {
+	note.Link
+	URL string `json:"url"`
+}
+
+func newNoteLink(link note.Link) NoteLink {
+	return NoteLink{Link: link, URL: "/api/v1/public/" + link.Token}
+}
+
+type CreateLinkAction interface {
+	Do(ctx context.Context, ownerID, noteID uuid.UUID, args notes.CreateLinkArgs) (note.Link, error)
+}
+
+type CreateLinkHandler struct {
+	action CreateLinkAction
+	log    *zap.Logger
+}
+
+func NewCreateLinkHandler(action CreateLinkAction, log *zap.Logger) *CreateLinkHandler {
+	return &CreateLinkHandler{action: action, log: log}
+}
+
+func (h *CreateLinkHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	// Тело необязательно: без него ссылка создаётся без срока.
+	var requestParams notes.CreateLinkArgs
+	if r.ContentLength != 0 {
+		err = readJSON(r, &requestParams)
+		if err != nil {
+			writeError(w, r, h.log, err)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	link, err := h.action.Do(ctx, userIDFromContext(ctx), id, requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusCreated, newNoteLink(link))
+}
+
+type GetLinkAction interface {
+	Do(ctx context.Context, ownerID, noteID uuid.UUID) (note.Link, error)
+}
+
+type GetLinkHandler struct {
+	action GetLinkAction
+	log    *zap.Logger
+}
+
+func NewGetLinkHandler(action GetLinkAction, log *zap.Logger) *GetLinkHandler {
+	return &GetLinkHandler{action: action, log: log}
+}
+
+func (h *GetLinkHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	link, err := h.action.Do(ctx, userIDFromContext(ctx), id)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, newNoteLink(link))
+}
+
+type DeleteLinkAction interface {
+	Do(ctx context.Context, ownerID, noteID uuid.UUID) error
+}
+
+type DeleteLinkHandler struct {
+	action DeleteLinkAction
+	log    *zap.Logger
+}
+
+func NewDeleteLinkHandler(action DeleteLinkAction, log *zap.Logger) *DeleteLinkHandler {
+	return &DeleteLinkHandler{action: action, log: log}
+}
+
+func (h *DeleteLinkHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	err = h.action.Do(ctx, userIDFromContext(ctx), id)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,244 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type CreateNotebookAction interface {
+	Do(ctx context.Context, args notes.CreateNotebookArgs) (note.Notebook, error)
+}
+
+type RequestNotebook struct {
+	Name string `json:"name"`
+	// ParentID родительский блокнот, без него блокнот лежит в корне.
+	ParentID *uuid.UUID `json:"parentId"`
+}
+
+type CreateNotebookHandler struct {
+	action CreateNotebookAction
+	log    *zap.Logger
+}
+
+func NewCreateNotebookHandler(action CreateNotebookAction, log *zap.Logger) *CreateNotebookHandler {
+	return &CreateNotebookHandler{action: action, log: log}
+}
+
+func (h *CreateNotebookHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var requestParams RequestNotebook
+	if err := readJSON(r, &requestParams); err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	notebook, err := h.action.Do(ctx, notes.CreateNotebookArgs{
+		OwnerID:  userIDFromContext(ctx),
+		Name:     requestParams.Name,
+		ParentID: requestParams.ParentID,
+	})
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusCreated, notebook)
+}
+
+type ListNotebooksAction interface {
+	Do(ctx context.Context, ownerID uuid.UUID) ([]note.Notebook, error)
+}
+
+type ListNotebooksHandler struct {
+	action ListNotebooksAction
+	log    *zap.Logger
+}
+
+func NewListNotebooksHandler(action ListNotebooksAction, log *zap.Logger) *ListNotebooksHandler {
+	return &ListNotebooksHandler{action: action, log: log}
+}
+
+func (h *ListNotebooksHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	list, err := h.action.Do(ctx, userIDFromContext(ctx))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, list)
+}
+
+type GetNotebookAction interface {
+	Do(ctx context.Context, ownerID, id uuid.UUID) (note.Notebook, error)
+}
+
+type GetNotebookHandler struct {
+	action GetNotebookAction
+	log    *zap.Logger
+}
+
+func NewGetNotebookHandler(action GetNotebookAction, log *zap.Logger) *GetNotebookHandler {
+	return &GetNotebookHandler{action: action, log: log}
+}
+
+func (h *GetNotebookHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNotebookID(chi.URLParam(r, "notebookID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	notebook, err := h.action.Do(ctx, userIDFromContext(ctx), id)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, notebook)
+}
+
+type UpdateNotebookAction interface {
+	Do(ctx context.Context, args notes.UpdateNotebookArgs) (note.Notebook, error)
+}
+
+type UpdateNotebookHandler struct {
+	action UpdateNotebookAction
+	log    *zap.Logger
+}
+
+func NewUpdateNotebookHandler(action UpdateNotebookAction, log *zap.Logger) *UpdateNotebookHandler {
+	return &UpdateNotebookHandler{action: action, log: log}
+}
+
+func (h *UpdateNotebookHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNotebookID(chi.URLParam(r, "notebookID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	var requestParams RequestNotebook
+	if err := readJSON(r, &requestParams); err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	notebook, err := h.action.Do(ctx, notes.UpdateNotebookArgs{
+		OwnerID:  userIDFromContext(ctx),
+		ID:       id,
+		Name:     requestParams.Name,
+		ParentID: requestParams.ParentID,
+	})
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, notebook)
+}
+
+type DeleteNotebookAction interface {
+	Do(ctx context.Context, ownerID, id uuid.UUID, policy notes.NotebookDeletePolicy) error
+}
+
+type DeleteNotebookHandler struct {
+	action DeleteNotebookAction
+	log    *zap.Logger
+}
+
+func NewDeleteNotebookHandler(action DeleteNotebookAction, log *zap.Logger) *DeleteNotebookHandler {
+	return &DeleteNotebookHandler{action: action, log: log}
+}
+
+func (h *DeleteNotebookHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNotebookID(chi.URLParam(r, "notebookID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	query := newQueryParser(r.URL.Query())
+	query.allow("notes")
+	policy := query.enum("notes", string(notes.NotebookDeleteMove),
+		string(notes.NotebookDeleteMove), string(notes.NotebookDeleteTrash))
+	if err := query.err(); err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.action.Do(ctx, userIDFromContext(ctx), id, notes.NotebookDeletePolicy(policy)); err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type MoveNotesAction interface {
+	Do(ctx context.Context, args notes.MoveNotesArgs) (uint, error)
+}
+
+type MoveNotesRequest struct {
+	NoteID []uuid.UUID `json:"noteId"`
+	// NotebookID блокнот назначения, null переносит заметки в корень.
+	NotebookID *uuid.UUID `json:"notebookId"`
+}
+
+type MoveNotesResponse struct {
+	// Moved число заметок, которые сменили блокнот.
+	Moved uint `json:"moved"`
+}
+
+type MoveNotesHandler struct {
+	action MoveNotesAction
+	log    *zap.Logger
+}
+
+func NewMoveNotesHandler(action MoveNotesAction, log *zap.Logger) *MoveNotesHandler {
+	return &MoveNotesHandler{action: action, log: log}
+}
+
+func (h *MoveNotesHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var requestParams MoveNotesRequest
+	if err := readJSON(r, &requestParams); err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	if len(requestParams.NoteID) == 0 {
+		writeError(w, r, h.log, errInvalidParams.WithField("noteId", "is required"))
+		return
+	}
+
+	ctx := r.Context()
+	moved, err := h.action.Do(ctx, notes.MoveNotesArgs{
+		OwnerID:    userIDFromContext(ctx),
+		NoteIDs:    requestParams.NoteID,
+		NotebookID: requestParams.NotebookID,
+	})
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, MoveNotesResponse{Moved: moved})
+}
+
+func parseNotebookID(value string) (uuid.UUID, error) {
+	id, err := uuid.FromString(value)
+	if err != nil {
+		return uuid.Nil, errInvalidParams.WithField("notebookID", "must be a UUID").WithCause(err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+type PatchAction interface {
+	Do(ctx context.Context, args notes.PatchArgs) (note.Note, error)
+}
+
+// RequestPatchNote merge patch (RFC 7396): отсутствующее поле не меняется,
+// null очищает его.
+type RequestPatchNote struct {
+	Label *string  `json:"label,omitempty"`
+	Body  *string  `json:"body,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type PatchNoteHandler struct {
+	action PatchAction
+	log    *zap.Logger
+}
+
+func NewPatchNoteHandler(action PatchAction, log *zap.Logger) *PatchNoteHandler {
+	return &PatchNoteHandler{action: action, log: log}
+}
+
+func (h *PatchNoteHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	args := notes.PatchArgs{
+		UserID:  userIDFromContext(ctx),
+		ID:      id,
+		Version: version,
+	}
+
+	if err := readPatch(r, &args); err != nil {
+		w.Header().Set("Accept-Patch", mergePatchContentType+", "+jsonPatchContentType)
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	patched, err := h.action.Do(ctx, args)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	setNoteHeaders(w, patched)
+	writeJSON(w, r, h.log, http.StatusOK, patched)
+}
+
+// readPatch разбирает тело по Content-Type: merge patch (также application/json)
+// заполняет поля args, JSON Patch - args.Operations.
+func readPatch(r *http.Request, args *notes.PatchArgs) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = ""
+	}
+
+	switch mediaType {
+	case mergePatchContentType, "application/json", jsonPatchContentType:
+	default:
+		return errInvalidContentType.WithMessage("Content-Type must be " + mergePatchContentType + " or " + jsonPatchContentType)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return errInvalidBody.WithCause(err)
+	}
+
+	if mediaType == jsonPatchContentType {
+		if err := json.Unmarshal(body, &args.Operations); err != nil {
+			return errInvalidBody.WithCause(err)
+		}
+		if args.Operations == nil {
+			return errInvalidBody.WithMessage("JSON Patch must be an array of operations")
+		}
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return errInvalidBody.WithMessage("merge patch must be a JSON object")
+	}
+
+	var invalidParams *apperr.Error
+	invalid := func(name, message string) {
+		if invalidParams == nil {
+			invalidParams = errInvalidParams
+		}
+		invalidParams = invalidParams.WithField(name, message)
+	}
+
+	for name, raw := range fields {
+		null := string(raw) == "null"
+		switch name {
+		case "label", "body":
+			var value string
+			if !null && json.Unmarshal(raw, &value) != nil {
+				invalid(name, "must be a string or null")
+				continue
+			}
+			if name == "label" {
+				args.Label = &value
+			} else {
+				args.Body = &value
+			}
+		case "tags":
+			var tags []string
+			if !null && json.Unmarshal(raw, &tags) != nil {
+				invalid(name, "must be an array of strings or null")
+				continue
+			}
+			args.Tags = &tags
+		default:
+			invalid(name, "can not be patched")
+		}
+	}
+
+	if invalidParams != nil {
+		return invalidParams
+	}
+
+	return nil
+}
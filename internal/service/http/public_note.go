@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"html/template"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+// PublicNote заметка, открытая по публичной ссылке. Владелец не раскрывается.
+type PublicNote struct {
+	ID        uuid.UUID `json:"id"`
+	Label     string    `json:"label"`
+	Body      string    `json:"body"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var publicNoteTemplate = template.Must(template.New("note").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>{{.Label}}</title>
+</head>
+<body>
+<article>
+<h1>{{.Label}}</h1>
+{{if .Tags}}<p>{{range $i, $tag := .Tags}}{{if $i}}, {{end}}#{{$tag}}{{end}}</p>{{end}}
+<pre style="white-space: pre-wrap">{{.Body}}</pre>
+<footer><time datetime="{{.CreatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{.CreatedAt.Format "2006-01-02"}}</time></footer>
+</article>
+</body>
+</html>
+`))
+
+type GetByLinkAction interface {
+	Do(ctx context.Context, token string) (note.Note, error)
+}
+
+type PublicNoteHandler struct {
+	action GetByLinkAction
+	log    *zap.Logger
+}
+
+func NewPublicNoteHandler(action GetByLinkAction, log *zap.Logger) *PublicNoteHandler {
+	return &PublicNoteHandler{action: action, log: log}
+}
+
+func (h *PublicNoteHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	format, err := publicFormat(r)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	n, err := h.action.Do(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	// Отозванная ссылка должна перестать работать сразу, без кеша.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Vary", "Accept")
+
+	public := PublicNote{ID: n.ID, Label: n.Label, Body: n.Body, Tags: n.Tags, CreatedAt: n.CreatedAt}
+	if format == formatJSON {
+		writeJSON(w, r, h.log, http.StatusOK, public)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	if err := publicNoteTemplate.Execute(w, public); err != nil {
+		h.log.Debug("failed during write response", zap.Error(err))
+	}
+}
+
+const (
+	formatJSON = "json"
+	formatHTML = "html"
+)
+
+// publicFormat формат ответа: параметр format, иначе HTML, если клиент
+// принимает text/html (браузер), иначе JSON.
+func publicFormat(r *http.Request) (string, error) {
+	query := newQueryParser(r.URL.Query())
+	query.allow("format")
+	format := query.enum("format", "", formatJSON, formatHTML)
+	if err := query.err(); err != nil {
+		return "", err
+	}
+	if format != "" {
+		return format, nil
+	}
+
+	return acceptedFormat(r), nil
+}
+
+// acceptedFormat formatHTML, если в Accept text/html стоит раньше
+// application/json, иначе formatJSON.
+func acceptedFormat(r *http.Request) string {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/html":
+			return formatHTML
+		case "application/json":
+			return formatJSON
+		}
+	}
+
+	return formatJSON
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/apperr"
+)
+
+// maxOffset верхняя граница offset, защищает от переполнения в запросах к хранилищу.
+const maxOffset = 1 << 31
+
+// queryParser разбирает параметры строки запроса и копит ошибки по всем полям,
+// чтобы клиент получил их одним ответом.
+type queryParser struct {
+	values url.Values
+	errors []apperr.FieldError
+}
+
+func newQueryParser(values url.Values) *queryParser {
+	return &queryParser{values: values}
+}
+
+// err ошибка со всеми накопленными полями или nil.
+func (p *queryParser) err() error {
+	if len(p.errors) == 0 {
+		return nil
+	}
+
+	return apperr.Validation(errInvalidParams.Code, "invalid query params", p.errors...)
+}
+
+func (p *queryParser) fail(field, format string, args ...any) {
+	p.errors = append(p.errors, apperr.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// allow отмечает ошибкой все параметры, которых нет в списке.
+func (p *queryParser) allow(names ...string) {
+	known := map[string]bool{}
+	for _, name := range names {
+		known[name] = true
+	}
+
+	unknown := []string{}
+	for name := range p.values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+
+	for _, name := range unknown {
+		p.fail(name, "unknown parameter")
+	}
+}
+
+func (p *queryParser) single(name string) (string, bool) {
+	values, ok := p.values[name]
+	if !ok {
+		return "", false
+	}
+	if len(values) > 1 {
+		p.fail(name, "must be specified once")
+		return "", false
+	}
+
+	return values[0], true
+}
+
+func (p *queryParser) string(name string) string {
+	value, _ := p.single(name)
+	return value
+}
+
+// uint возвращает def, если параметра нет, и проверяет попадание в [min, max].
+func (p *queryParser) uint(name string, def, min, max uint) uint {
+	value, ok := p.single(name)
+	if !ok {
+		return def
+	}
+
+	parsed, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		p.fail(name, "must be a non-negative integer")
+		return def
+	}
+	if uint(parsed) < min || uint(parsed) > max {
+		p.fail(name, "must be between %v and %v", min, max)
+		return def
+	}
+
+	return uint(parsed)
+}
+
+func (p *queryParser) bool(name string) *bool {
+	value, ok := p.single(name)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		p.fail(name, "must be true or false")
+		return nil
+	}
+
+	return &parsed
+}
+
+// time разбирает дату и время в формате RFC 3339. Если параметра нет, возвращает нулевое время.
+func (p *queryParser) time(name string) time.Time {
+	value, ok := p.single(name)
+	if !ok {
+		return time.Time{}
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		p.fail(name, "must be a RFC 3339 date-time")
+		return time.Time{}
+	}
+
+	return parsed
+}
+
+// uuid возвращает uuid.Nil, если параметра нет.
+func (p *queryParser) uuid(name string) uuid.UUID {
+	value, ok := p.single(name)
+	if !ok {
+		return uuid.Nil
+	}
+
+	parsed, err := uuid.FromString(value)
+	if err != nil {
+		p.fail(name, "must be a UUID")
+		return uuid.Nil
+	}
+
+	return parsed
+}
+
+// enum возвращает def, если параметра нет, иначе значение из allowed.
+func (p *queryParser) enum(name, def string, allowed ...string) string {
+	value, ok := p.single(name)
+	if !ok {
+		return def
+	}
+
+	for _, a := range allowed {
+		if value == a {
+			return value
+		}
+	}
+	p.fail(name, "must be one of %v", strings.Join(allowed, ", "))
+
+	return def
+}
+
+// list принимает как повторяющийся параметр, так и значения через запятую.
+func (p *queryParser) list(name string) []string {
+	result := []string{}
+	for _, value := range p.values[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
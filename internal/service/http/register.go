@@ -0,0 +1,41 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
+)
+
+type RegisterAction interface {
+	Do(ctx context.Context, args users.RegisterArgs) (user.User, error)
+}
+
+type RegisterHandler struct {
+	action RegisterAction
+	log    *zap.Logger
+}
+
+func NewRegisterHandler(action RegisterAction, log *zap.Logger) *RegisterHandler {
+	return &RegisterHandler{action: action, log: log}
+}
+
+func (h *RegisterHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var requestParams users.RegisterArgs
+	err := readJSON(r, &requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	newUser, err := h.action.Do(ctx, requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusCreated, newUser)
+}
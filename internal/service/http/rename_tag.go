@@ -0,0 +1,55 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type RenameTagAction interface {
+	Do(ctx context.Context, args notes.RenameTagArgs) (note.Tag, error)
+}
+
+type RequestRenameTag struct {
+	Name string `json:"name"`
+}
+
+type RenameTagHandler struct {
+	action RenameTagAction
+	log    *zap.Logger
+}
+
+func NewRenameTagHandler(action RenameTagAction, log *zap.Logger) *RenameTagHandler {
+	return &RenameTagHandler{action: action, log: log}
+}
+
+func (h *RenameTagHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	tag, err := tagFromPath(r)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	var requestParams RequestRenameTag
+	err = readJSON(r, &requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	renamed, err := h.action.Do(ctx, notes.RenameTagArgs{
+		OwnerID: userIDFromContext(ctx),
+		From:    tag,
+		To:      requestParams.Name,
+	})
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, renamed)
+}
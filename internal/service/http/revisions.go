@@ -0,0 +1,130 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+// maxRevision верхняя граница номера ревизии в параметрах запроса.
+const maxRevision = 1<<31 - 1
+
+type ListRevisionsAction interface {
+	Do(ctx context.Context, userID, noteID uuid.UUID) ([]note.Revision, error)
+}
+
+type ListRevisionsHandler struct {
+	action ListRevisionsAction
+	log    *zap.Logger
+}
+
+func NewListRevisionsHandler(action ListRevisionsAction, log *zap.Logger) *ListRevisionsHandler {
+	return &ListRevisionsHandler{action: action, log: log}
+}
+
+func (h *ListRevisionsHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	revisions, err := h.action.Do(ctx, userIDFromContext(ctx), id)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, revisions)
+}
+
+type DiffRevisionsAction interface {
+	Do(ctx context.Context, userID, noteID uuid.UUID, from, to uint) (string, error)
+}
+
+type DiffRevisionsHandler struct {
+	action DiffRevisionsAction
+	log    *zap.Logger
+}
+
+func NewDiffRevisionsHandler(action DiffRevisionsAction, log *zap.Logger) *DiffRevisionsHandler {
+	return &DiffRevisionsHandler{action: action, log: log}
+}
+
+func (h *DiffRevisionsHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	query := newQueryParser(r.URL.Query())
+	query.allow("from", "to")
+	for _, name := range []string{"from", "to"} {
+		if _, ok := query.values[name]; !ok {
+			query.fail(name, "is required")
+		}
+	}
+	from := query.uint("from", 0, 1, maxRevision)
+	to := query.uint("to", 0, 1, maxRevision)
+	if err := query.err(); err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	diff, err := h.action.Do(ctx, userIDFromContext(ctx), id, from, to)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(diff)); err != nil {
+		h.log.Debug("write diff", zap.Error(err))
+	}
+}
+
+type RestoreRevisionAction interface {
+	Do(ctx context.Context, userID, noteID uuid.UUID, rev uint) (note.Note, error)
+}
+
+type RestoreRevisionHandler struct {
+	action RestoreRevisionAction
+	log    *zap.Logger
+}
+
+func NewRestoreRevisionHandler(action RestoreRevisionAction, log *zap.Logger) *RestoreRevisionHandler {
+	return &RestoreRevisionHandler{action: action, log: log}
+}
+
+func (h *RestoreRevisionHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	rev, err := strconv.ParseUint(chi.URLParam(r, "rev"), 10, 32)
+	if err != nil || rev == 0 {
+		writeError(w, r, h.log, errInvalidParams.WithField("rev", "must be a positive integer").WithCause(err))
+		return
+	}
+
+	ctx := r.Context()
+	restored, err := h.action.Do(ctx, userIDFromContext(ctx), id, uint(rev))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	setNoteHeaders(w, restored)
+	writeJSON(w, r, h.log, http.StatusOK, restored)
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	uuid "github.com/satori/go.uuid"
+	"go.uber.org/zap"
+)
+
+type RevokeTokenAction interface {
+	Do(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type RevokeTokenHandler struct {
+	action RevokeTokenAction
+	log    *zap.Logger
+}
+
+func NewRevokeTokenHandler(action RevokeTokenAction, log *zap.Logger) *RevokeTokenHandler {
+	return &RevokeTokenHandler{action: action, log: log}
+}
+
+func (h *RevokeTokenHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := uuid.FromString(chi.URLParam(r, "tokenID"))
+	if err != nil {
+		writeError(w, r, h.log, errInvalidParams.WithField("tokenID", "must be a UUID").WithCause(err))
+		return
+	}
+
+	ctx := r.Context()
+	err = h.action.Do(ctx, userIDFromContext(ctx), tokenID)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
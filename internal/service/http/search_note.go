@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/config"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type SearchAction interface {
+	Do(ctx context.Context, args notes.SearchArgs) (note.SearchNotes, error)
+}
+
+type SearchNotesHandler struct {
+	action     SearchAction
+	pagination config.PaginationConfig
+	log        *zap.Logger
+}
+
+func NewSearchNotesHandler(action SearchAction, pagination config.PaginationConfig, log *zap.Logger) *SearchNotesHandler {
+	return &SearchNotesHandler{action: action, pagination: pagination, log: log}
+}
+
+func (h *SearchNotesHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r.URL.Query())
+	query.allow("q", "offset", "limit")
+
+	args := notes.SearchArgs{
+		Query:  query.string("q"),
+		Offset: query.uint("offset", 0, 0, maxOffset),
+		Limit:  query.uint("limit", h.pagination.DefaultLimit, 1, h.pagination.MaxLimit),
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		query.fail("q", "is required")
+	}
+	if err := query.err(); err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	args.OwnerID = userIDFromContext(ctx)
+	result, err := h.action.Do(ctx, args)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, result)
+}
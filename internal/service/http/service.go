@@ -1,25 +1,37 @@
 package http
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/pkg/errors"
+	"github.com/victor8titov/rest-api-notes/internal/action/idempotency"
 	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/action/tokens"
+	"github.com/victor8titov/rest-api-notes/internal/action/users"
 	"github.com/victor8titov/rest-api-notes/internal/adaptor"
-	"github.com/victor8titov/rest-api-notes/internal/migrations"
+	"github.com/victor8titov/rest-api-notes/internal/config"
+	"github.com/victor8titov/rest-api-notes/internal/entity/user"
+	"go.uber.org/zap"
 
-	_ "github.com/victor8titov/rest-api-notes/docs"
+	"github.com/victor8titov/rest-api-notes/docs"
 
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
 type Service struct {
-	di    *adaptor.DIContainer
-	route *chi.Mux
+	di     *adaptor.DIContainer
+	config config.HTTPConfig
+	route  *chi.Mux
+	server *http.Server
+	ready  atomic.Bool
 }
 
 // @title REST API Notes API
@@ -34,9 +46,23 @@ type Service struct {
 
 // @host localhost:3000
 // @BasePath /api/v1
-func NewService(di *adaptor.DIContainer) *Service {
-	httpService := &Service{di: di}
+
+// @securityDefinitions.apikey	BearerAuth
+// @in							header
+// @name						Authorization
+// @description				Access token from /auth/login as "Bearer <token>".
+func NewService(di *adaptor.DIContainer, cfg config.HTTPConfig) *Service {
+	docs.SwaggerInfo.Host = cfg.SwaggerHost
+
+	httpService := &Service{di: di, config: cfg}
 	httpService.newRouter()
+	httpService.server = &http.Server{
+		Addr:         ":" + strconv.Itoa(cfg.Port),
+		Handler:      httpService.route,
+		ReadTimeout:  cfg.ReadTimeout.Duration,
+		WriteTimeout: cfg.WriteTimeout.Duration,
+		IdleTimeout:  cfg.IdleTimeout.Duration,
+	}
 
 	return httpService
 }
@@ -44,52 +70,162 @@ func NewService(di *adaptor.DIContainer) *Service {
 func (hs *Service) newRouter() {
 	root := chi.NewRouter()
 
+	root.Use(middleware.RequestID)
+	root.Use(requestIDHeader)
 	root.Use(middleware.Logger)
 	// Basic CORS
 	// for more ideas, see: https://developer.github.com/v3/#cross-origin-resource-sharing
 	root.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:300/*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedOrigins:   hs.config.CORSOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-None-Match", "X-CSRF-Token", idempotencyKeyHeader, middleware.RequestIDHeader},
+		ExposedHeaders:   []string{"ETag", "Link", idempotentReplayedHeader, middleware.RequestIDHeader},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
 
+	health := NewHealthHandler(&hs.ready)
+	root.Get("/healthz", health.Live)
+	root.Get("/readyz", health.Ready)
+
 	root.Get("/api/v1/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL("http://localhost:3000/api/v1/swagger/doc.json"),
+		httpSwagger.URL(fmt.Sprintf("http://%v/api/v1/swagger/doc.json", hs.config.SwaggerHost)),
 	))
 
-	root.Route("/api/v1/migration", func(router chi.Router) {
-		router.Get("/01", hs.handleMigration01)
+	root.Route("/api/v1/auth", func(router chi.Router) {
+		router.Post("/register", hs.handleRegister)
+		router.Post("/login", hs.handleLogin)
 	})
 
-	root.Route("/api/v1/note", func(router chi.Router) {
-		router.Post("/", hs.handleCreateNote)
-		router.Get("/", hs.handleGetListNotes)
-		router.Get("/{noteID}", hs.handleGetNoteByID)
-		router.Delete("/", hs.handleDeleteNote)
-		router.Put("/{noteID}", hs.handleUpdateNote)
+	root.Get("/api/v1/public/{token}", hs.handlePublicNote)
+
+	root.Group(func(router chi.Router) {
+		log := hs.di.GetLogger()
+		read := requireScope(user.ScopeNotesRead, log)
+		write := requireScope(user.ScopeNotesWrite, log)
+		manage := requireScope(user.ScopeTokensManage, log)
+		keys := idempotencyKeys(idempotency.NewKeeper(
+			hs.di.GetIdempotencyAdaptor(context.Background()),
+			hs.di.GetConfig().Idempotency.TTL.Duration,
+			log,
+		), int64(hs.di.GetConfig().Idempotency.MaxBodySize), log)
+
+		router.Use(authenticate(TokenVerifierFunc(hs.verifyToken), log))
+
+		router.Route("/api/v1/note", func(router chi.Router) {
+			router.With(write, keys).Post("/", hs.handleCreateNote)
+			router.With(read).Get("/", hs.handleGetListNotes)
+			router.With(read).Get("/search", hs.handleSearchNotes)
+			router.With(read).Get("/{noteID}", hs.handleGetNoteByID)
+			router.With(write).Delete("/", hs.handleDeleteNote)
+			router.With(write).Post("/move", hs.handleMoveNotes)
+			router.With(write, keys).Post("/batch", hs.handleBatchNotes)
+			router.With(write).Delete("/{noteID}", hs.handleDeleteNoteByID)
+			router.With(write).Put("/{noteID}", hs.handleUpdateNote)
+			router.With(write).Patch("/{noteID}", hs.handlePatchNote)
+			router.With(write).Post("/{noteID}/shares", hs.handleShareNote)
+			router.With(read).Get("/{noteID}/shares", hs.handleListShares)
+			router.With(write).Delete("/{noteID}/shares/{userID}", hs.handleUnshareNote)
+			router.With(write).Post("/{noteID}/link", hs.handleCreateLink)
+			router.With(read).Get("/{noteID}/link", hs.handleGetLink)
+			router.With(write).Delete("/{noteID}/link", hs.handleDeleteLink)
+			router.With(write).Put("/{noteID}/{flag}", hs.handleSetNoteFlag)
+			router.With(write).Delete("/{noteID}/{flag}", hs.handleUnsetNoteFlag)
+			router.With(read).Get("/{noteID}/revisions", hs.handleListRevisions)
+			router.With(read).Get("/{noteID}/revisions/diff", hs.handleDiffRevisions)
+			router.With(write).Post("/{noteID}/revisions/{rev}/restore", hs.handleRestoreRevision)
+		})
+
+		router.Route("/api/v1/trash", func(router chi.Router) {
+			router.With(read).Get("/", hs.handleListTrash)
+			router.With(write).Post("/{noteID}/restore", hs.handleRestoreNote)
+			router.With(write).Delete("/{noteID}", hs.handlePurgeNote)
+		})
+
+		router.Route("/api/v1/notebooks", func(router chi.Router) {
+			router.With(write).Post("/", hs.handleCreateNotebook)
+			router.With(read).Get("/", hs.handleListNotebooks)
+			router.With(read).Get("/{notebookID}", hs.handleGetNotebook)
+			router.With(write).Put("/{notebookID}", hs.handleUpdateNotebook)
+			router.With(write).Delete("/{notebookID}", hs.handleDeleteNotebook)
+		})
+
+		router.Route("/api/v1/tags", func(router chi.Router) {
+			router.With(read).Get("/", hs.handleListTags)
+			router.With(write).Post("/{tag}/rename", hs.handleRenameTag)
+			router.With(write).Delete("/{tag}", hs.handleDeleteTag)
+		})
+
+		router.Route("/api/v1/tokens", func(router chi.Router) {
+			router.With(manage).Post("/", hs.handleCreateToken)
+			router.With(manage).Get("/", hs.handleListTokens)
+			router.With(manage).Delete("/{tokenID}", hs.handleRevokeToken)
+		})
 	})
 
 	hs.route = root
 }
 
-func (hs *Service) ListenAndServe(port int) error {
-	err := http.ListenAndServe(":"+strconv.Itoa(port), hs.route)
+// verifyToken проверяет API-токен, если у него есть префикс, иначе - JWT.
+func (hs *Service) verifyToken(ctx context.Context, token string) (user.Principal, error) {
+	if strings.HasPrefix(token, user.APITokenPrefix) {
+		action := tokens.NewVerifyAction(hs.di.GetAPITokenAdaptor(ctx), hs.di.GetLogger())
+		return action.Do(ctx, token)
+	}
+
+	return hs.di.GetTokenVerifier().Verify(token)
+}
+
+// ListenAndServe блокирует до остановки сервера. После Shutdown возвращает nil.
+func (hs *Service) ListenAndServe() error {
+	hs.ready.Store(true)
+
+	err := hs.server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
 	return errors.WithMessage(err, "Failed listen and serve http service")
 }
 
+// SetNotReady переводит /readyz в 503, не прерывая обработку запросов.
+func (hs *Service) SetNotReady() {
+	hs.ready.Store(false)
+}
+
+// Shutdown дожидается завершения активных запросов и закрывает пул соединений с базой.
+func (hs *Service) Shutdown(ctx context.Context) error {
+	hs.SetNotReady()
+
+	err := hs.server.Shutdown(ctx)
+	if err != nil {
+		hs.di.GetLogger().Error("shutdown http server", zap.Error(err))
+	}
+
+	if closeErr := hs.di.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+
+	return errors.WithMessage(err, "Failed shutdown http service")
+}
+
 // handleCreateNote
 //
 //	@Summary	Create note.
 //	@Accept		json
 //	@Produce	json
 //	@Param		note	body	notes.CreateArgs	true	"fields for new note"
+//	@Param		Idempotency-Key	header	string	false	"retries with the same key replay the first response"
 //	@Success	200	{object}	note.Note	"Ok"
-//	@Failure		400		{string}	string	"invalid request params"
-//	@Failure		404		{string}	string	"not found"
-//	@Failure		500		{string}	string	"failed during inner process"
+//	@Failure		400		{object}	Problem	"invalid request params"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope"
+//	@Failure		404		{object}	Problem	"notebook not found"
+//	@Failure		409		{object}	Problem	"note already exists or request with this key is in progress"
+//	@Failure		413		{object}	Problem	"body with idempotency key is too large"
+//	@Failure		422		{object}	Problem	"idempotency key reused with another request"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
 //	@Router			/note  [post]
 func (hs *Service) handleCreateNote(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -104,13 +240,24 @@ func (hs *Service) handleCreateNote(w http.ResponseWriter, r *http.Request) {
 
 // handleGetNoteByID
 //
-//	@Summary	Get note by ID.
-//	@Produce	json
-//	@Param		noteID	path	string	true	"ID of note that you want getting"
-//	@Success	200	{object}	note.Note	"Ok"
-//	@Failure		400		{string}	string	"invalid request params"
-//	@Failure		404		{string}	string	"not found"
-//	@Failure		500		{string}	string	"failed during inner process"
+//	@Summary		Get note by ID.
+//	@Description	Returns the note version in ETag and the time of the last change in Last-Modified.
+//	@Description	With matching If-None-Match responds 304 without body.
+//	@Description	With render=html the body is rendered from Markdown (CommonMark and GFM) to sanitized HTML with a table of contents.
+//	@Description	Accept: text/html returns only the rendered HTML.
+//	@Produce		json
+//	@Produce		html
+//	@Param			noteID			path	string	true	"ID of note that you want getting"
+//	@Param			render			query	string	false	"render body to HTML"	Enums(html)
+//	@Param			If-None-Match	header	string	false	"ETag of the cached note"
+//	@Success		200	{object}	note.RenderedNote	"Ok, html and toc only with render=html"
+//	@Success		304	"Not Modified"
+//	@Failure		400		{object}	Problem	"invalid request params"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope"
+//	@Failure		404		{object}	Problem	"not found"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
 //	@Router			/note/{noteID}  [get]
 func (hs *Service) handleGetNoteByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -118,21 +265,29 @@ func (hs *Service) handleGetNoteByID(w http.ResponseWriter, r *http.Request) {
 	store := hs.di.GetNoteAdaptor(ctx)
 
 	action := notes.NewGetByIDAction(store, log)
-	handler := NewGetByIDHandler(action, log)
+	render := notes.NewRenderAction(store, hs.di.GetMarkdownRenderer(), log)
+	handler := NewGetByIDHandler(action, render, log)
 
 	handler.Handle(w, r)
 }
 
 // handleUpdateNote
 //
-//	@Summary	Update note.
-//	@Produce	json
-//	@Param		noteID	path	string	true	"ID of note that you want updating"
-//	@Param		fields	body	RequestUpdateNote	true	"fields for updating note"
-//	@Success	200	{object}	note.Note	"Updated note"
-//	@Failure		400		{string}	string	"invalid request params"
-//	@Failure		404		{string}	string	"not found"
-//	@Failure		500		{string}	string	"failed during inner process"
+//	@Summary		Update note.
+//	@Description	Requires If-Match with the ETag from the last read, * skips the version check.
+//	@Produce		json
+//	@Param			noteID		path	string				true	"ID of note that you want updating"
+//	@Param			If-Match	header	string				true	"ETag of the note"
+//	@Param			fields		body	RequestUpdateNote	true	"fields for updating note"
+//	@Success		200	{object}	note.Note	"Updated note"
+//	@Failure		400		{object}	Problem	"invalid request params"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope or note permission"
+//	@Failure		404		{object}	Problem	"not found"
+//	@Failure		412		{object}	Problem	"note was modified"
+//	@Failure		428		{object}	Problem	"If-Match is missing"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
 //	@Router		/note/{noteID} [put]
 func (hs *Service) handleUpdateNote(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -145,15 +300,52 @@ func (hs *Service) handleUpdateNote(w http.ResponseWriter, r *http.Request) {
 	handler.Handle(w, r)
 }
 
+// handlePatchNote
+//
+//	@Summary		Patch note.
+//	@Description	Changes only the given fields. Accepts a merge patch (RFC 7396) or a JSON Patch (RFC 6902) over {label, body, tags}. Requires If-Match, * skips the version check.
+//	@Accept			application/merge-patch+json
+//	@Accept			application/json-patch+json
+//	@Produce		json
+//	@Param			noteID		path	string				true	"ID of note that you want patching"
+//	@Param			If-Match	header	string				true	"ETag of the note"
+//	@Param			patch		body	RequestPatchNote	true	"merge patch or array of JSON Patch operations"
+//	@Success		200	{object}	note.Note	"Patched note"
+//	@Failure		400		{object}	Problem	"invalid patch or patched note"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope or note permission"
+//	@Failure		404		{object}	Problem	"not found"
+//	@Failure		412		{object}	Problem	"note was modified"
+//	@Failure		422		{object}	Problem	"JSON Patch can not be applied"
+//	@Failure		428		{object}	Problem	"If-Match is missing"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router		/note/{noteID} [patch]
+func (hs *Service) handlePatchNote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewPatchAction(store, log)
+	handler := NewPatchNoteHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
 // handleDeleteNote
 //
-//	@Summary	Delete note by ID.
-//	@Param	noteID	path	string	true	"ID of note that you want to delete"
-//	@Success	200	{string}	string	"Success deleting"
-//	@Failure		400		{string}	string	"invalid request params"
-//	@Failure		404		{string}	string	"not found"
-//	@Failure		500		{string}	string	"failed during inner process"
-//	@Router		/note/{noteID} [delete]
+//	@Summary		Delete notes by IDs.
+//	@Description	Moves several notes to the trash at once, unknown IDs are skipped. Each note needs the version from its ETag; if any of them was modified, nothing is deleted.
+//	@Accept			json
+//	@Param			notes	body	DeleteRequest	true	"notes that you want to delete with their versions"
+//	@Success		200	{string}	string	"Success deleting"
+//	@Failure		400		{object}	Problem	"invalid request params"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope or note permission"
+//	@Failure		412		{object}	Problem	"note was modified"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note [delete]
 func (hs *Service) handleDeleteNote(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := hs.di.GetLogger()
@@ -165,36 +357,872 @@ func (hs *Service) handleDeleteNote(w http.ResponseWriter, r *http.Request) {
 	handler.Handle(w, r)
 }
 
+// handleDeleteNoteByID
+//
+//	@Summary		Delete note by ID.
+//	@Description	Moves the note to the trash. Requires If-Match with the ETag from the last read, * skips the version check.
+//	@Param			noteID		path	string	true	"ID of note that you want to delete"
+//	@Param			If-Match	header	string	true	"ETag of the note"
+//	@Success		204	"No Content"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope or note permission"
+//	@Failure		404	{object}	Problem	"not found"
+//	@Failure		412	{object}	Problem	"note was modified"
+//	@Failure		428	{object}	Problem	"If-Match is missing"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/{noteID} [delete]
+func (hs *Service) handleDeleteNoteByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewDeleteByIDAction(store, log)
+	handler := NewDeleteNoteHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
 // handleGetListNotes
 //
 //	@Summary		Getting list of notes.
-//	@Description	Getting list with pagination.
-//	@Accept			json
+//	@Description	Getting list with offset or cursor pagination. Pass nextCursor or prevCursor from the previous page as cursor.
+//	@Description	Pinned notes go first with any sort order, archived notes are listed only with archived=true.
+//	@Description	Tag filters accept repeated parameters or comma separated values.
 //	@Produce		json
-//	@Param	pagination	body	RequestListNotes true	"params for pagination"
-//	@Success		200		{object}	note.ListNotes			"ok"
-//	@Failure		400		{string}	string	"invalid request params"
-//	@Failure		404		{string}	string	"not found"
-//	@Failure		500		{string}	string	"failed during inner process"
+//	@Param			sortBy		query		string		false	"sort field"	Enums(label, created_at, updated_at)	default(label)
+//	@Param			direction	query		string		false	"sort direction"	Enums(asc, desc)	default(asc)
+//	@Param			limit		query		int			false	"page size, capped by pagination.maxLimit"	minimum(1)	default(20)
+//	@Param			offset		query		int			false	"number of notes to skip, can not be combined with cursor"	minimum(0)
+//	@Param			cursor		query		string		false	"cursor of the neighbour page"
+//	@Param			withTotal	query		bool		false	"count total number of notes, default true without cursor"
+//	@Param			tagsAll		query		[]string	false	"notes having all of the tags"	collectionFormat(multi)
+//	@Param			tagsAny		query		[]string	false	"notes having any of the tags"	collectionFormat(multi)
+//	@Param			tagsNone	query		[]string	false	"notes having none of the tags"	collectionFormat(multi)
+//	@Param			shared		query		bool		false	"notes shared with the current user instead of own notes"
+//	@Param			modifiedSince	query	string	false	"notes updated at or after the time, RFC 3339"	format(date-time)
+//	@Param			createdBefore	query	string	false	"notes created before the time, RFC 3339"	format(date-time)
+//	@Param			notebookId	query	string	false	"notes of the notebook"	format(uuid)
+//	@Param			recursive	query	bool	false	"with notebookId, include notes of nested notebooks"
+//	@Param			pinned		query	bool	false	"only pinned or only not pinned notes"
+//	@Param			archived	query	bool	false	"archived notes instead of the rest"	default(false)
+//	@Param			favorite	query	bool	false	"only favorite or only not favorite notes"
+//	@Success		200			{object}	note.ListNotes			"ok"
+//	@Failure		400		{object}	Problem	"invalid request params"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope"
+//	@Failure		404		{object}	Problem	"notebook not found"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
 //	@Router			/note  [get]
 func (hs *Service) handleGetListNotes(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := hs.di.GetLogger()
 	store := hs.di.GetNoteAdaptor(ctx)
 
-	action := notes.NewListAction(store, log)
-	handler := NewListNotesHandler(action, log)
+	action := notes.NewListAction(store, hs.di.GetCursorCodec(), log)
+	handler := NewListNotesHandler(action, hs.di.GetConfig().Pagination, log)
+
+	handler.Handle(w, r)
+}
+
+// handleSearchNotes
+//
+//	@Summary		Full-text search of notes.
+//	@Description	Searching by label and body, results are ordered by relevance. Snippet is HTML: note text is escaped, matches are wrapped in <b>.
+//	@Produce		json
+//	@Param			q		query		string	true	"search query, supports quoted phrases, OR and -exclusion"
+//	@Param			offset	query		int		false	"number of results to skip"	minimum(0)
+//	@Param			limit	query		int		false	"page size, capped by pagination.maxLimit"	minimum(1)	default(20)
+//	@Success		200		{object}	note.SearchNotes	"ok"
+//	@Failure		400		{object}	Problem	"invalid request params"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router			/note/search  [get]
+func (hs *Service) handleSearchNotes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewSearchAction(store, log)
+	handler := NewSearchNotesHandler(action, hs.di.GetConfig().Pagination, log)
+
+	handler.Handle(w, r)
+}
+
+// handleListTags
+//
+//	@Summary	List of all tags with number of notes.
+//	@Produce	json
+//	@Success	200	{array}		note.Tag	"ok"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router		/tags  [get]
+func (hs *Service) handleListTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewListTagsAction(store, log)
+	handler := NewListTagsHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleRenameTag
+//
+//	@Summary		Rename tag in all notes.
+//	@Description	If a note already has the new tag, the old one is removed without duplicating.
+//	@Accept			json
+//	@Produce		json
+//	@Param			tag		path		string				true	"current tag name"
+//	@Param			fields	body		RequestRenameTag	true	"new tag name"
+//	@Success		200		{object}	note.Tag			"renamed tag and number of changed notes"
+//	@Failure		400		{object}	Problem	"invalid request params"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope"
+//	@Failure		404		{object}	Problem	"not found"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router			/tags/{tag}/rename  [post]
+func (hs *Service) handleRenameTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewRenameTagAction(store, log)
+	handler := NewRenameTagHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleDeleteTag
+//
+//	@Summary	Remove tag from all notes.
+//	@Param		tag	path	string	true	"tag name"
+//	@Success	204	"Success deleting"
+//	@Failure		400		{object}	Problem	"invalid request params"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope"
+//	@Failure		404		{object}	Problem	"not found"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router		/tags/{tag}  [delete]
+func (hs *Service) handleDeleteTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewDeleteTagAction(store, log)
+	handler := NewDeleteTagHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleRegister
+//
+//	@Summary	Register new user.
+//	@Tags		auth
+//	@Accept		json
+//	@Produce	json
+//	@Param		credentials	body	users.RegisterArgs	true	"email and password, at least 8 characters"
+//	@Success	201	{object}	user.User	"Created user"
+//	@Failure	400	{object}	Problem	"invalid request params"
+//	@Failure	409	{object}	Problem	"email is taken"
+//	@Failure	500	{object}	Problem	"failed during inner process"
+//	@Router		/auth/register  [post]
+func (hs *Service) handleRegister(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetUserAdaptor(ctx)
+
+	action := users.NewRegisterAction(store, log)
+	handler := NewRegisterHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleLogin
+//
+//	@Summary	Get access token.
+//	@Tags		auth
+//	@Accept		json
+//	@Produce	json
+//	@Param		credentials	body	users.LoginArgs	true	"email and password"
+//	@Success	200	{object}	user.Token	"Access token"
+//	@Failure	400	{object}	Problem	"invalid request params"
+//	@Failure	401	{object}	Problem	"invalid credentials"
+//	@Failure	500	{object}	Problem	"failed during inner process"
+//	@Router		/auth/login  [post]
+func (hs *Service) handleLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetUserAdaptor(ctx)
+
+	action := users.NewLoginAction(store, hs.di.GetTokenIssuer(), log)
+	handler := NewLoginHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleCreateToken
+//
+//	@Summary		Create API token.
+//	@Description	Requires scope tokens:manage, which API tokens never have. The token is returned only once. Scopes default to the scopes of the current token and cannot exceed them.
+//	@Tags			tokens
+//	@Accept			json
+//	@Produce		json
+//	@Param			token	body	tokens.CreateArgs	true	"name, scopes and optional expiry"
+//	@Success		201	{object}	user.IssuedAPIToken	"Created token"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope or scope not granted"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/tokens  [post]
+func (hs *Service) handleCreateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetAPITokenAdaptor(ctx)
+
+	action := tokens.NewCreateAction(store, log)
+	handler := NewCreateTokenHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleListTokens
+//
+//	@Summary	List API tokens.
+//	@Tags		tokens
+//	@Produce	json
+//	@Success	200	{array}		user.APIToken	"Ok"
+//	@Failure	401	{object}	Problem	"unauthorized"
+//	@Failure	403	{object}	Problem	"insufficient scope"
+//	@Failure	500	{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router		/tokens  [get]
+func (hs *Service) handleListTokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetAPITokenAdaptor(ctx)
+
+	action := tokens.NewListAction(store, log)
+	handler := NewListTokensHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleRevokeToken
+//
+//	@Summary	Revoke API token.
+//	@Tags		tokens
+//	@Param		tokenID	path	string	true	"ID of token"
+//	@Success	204	"No Content"
+//	@Failure	400	{object}	Problem	"invalid request params"
+//	@Failure	401	{object}	Problem	"unauthorized"
+//	@Failure	403	{object}	Problem	"insufficient scope"
+//	@Failure	404	{object}	Problem	"not found"
+//	@Failure	500	{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router		/tokens/{tokenID}  [delete]
+func (hs *Service) handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetAPITokenAdaptor(ctx)
+
+	action := tokens.NewRevokeAction(store, log)
+	handler := NewRevokeTokenHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleShareNote
+//
+//	@Summary		Share note with another user.
+//	@Description	Only the owner can share a note. Sharing again with the same user changes the permission.
+//	@Accept			json
+//	@Produce		json
+//	@Param			noteID	path	string				true	"ID of note"
+//	@Param			share	body	notes.ShareArgs		true	"email of the user and permission"
+//	@Success		201	{object}	note.Share	"Created"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"not the owner of the note"
+//	@Failure		404	{object}	Problem	"not found"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/{noteID}/shares  [post]
+func (hs *Service) handleShareNote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewShareAction(store, hs.di.GetUserAdaptor(ctx), log)
+	handler := NewShareNoteHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleListShares
+//
+//	@Summary	List users the note is shared with.
+//	@Produce	json
+//	@Param		noteID	path	string	true	"ID of note"
+//	@Success	200	{array}		note.Share	"Ok"
+//	@Failure	400	{object}	Problem	"invalid request params"
+//	@Failure	401	{object}	Problem	"unauthorized"
+//	@Failure	403	{object}	Problem	"not the owner of the note"
+//	@Failure	404	{object}	Problem	"not found"
+//	@Failure	500	{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router		/note/{noteID}/shares  [get]
+func (hs *Service) handleListShares(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewListSharesAction(store, hs.di.GetUserAdaptor(ctx), log)
+	handler := NewListSharesHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleUnshareNote
+//
+//	@Summary		Revoke access to note.
+//	@Description	The owner can revoke any share, a user can remove only their own access.
+//	@Param			noteID	path	string	true	"ID of note"
+//	@Param			userID	path	string	true	"ID of the user to revoke access from"
+//	@Success		204	"No Content"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"not the owner of the note"
+//	@Failure		404	{object}	Problem	"not found"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/{noteID}/shares/{userID}  [delete]
+func (hs *Service) handleUnshareNote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewUnshareAction(store, log)
+	handler := NewUnshareNoteHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleCreateLink
+//
+//	@Summary		Create public read-only link to note.
+//	@Description	Only the owner can create a link. A new link replaces the previous one.
+//	@Accept			json
+//	@Produce		json
+//	@Param			noteID	path	string					true	"ID of note"
+//	@Param			link	body	notes.CreateLinkArgs	false	"optional expiry"
+//	@Success		201	{object}	NoteLink	"Created"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"not the owner of the note"
+//	@Failure		404	{object}	Problem	"not found"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/{noteID}/link  [post]
+func (hs *Service) handleCreateLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewCreateLinkAction(store, log)
+	handler := NewCreateLinkHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleGetLink
+//
+//	@Summary	Get public link to note.
+//	@Produce	json
+//	@Param		noteID	path	string	true	"ID of note"
+//	@Success	200	{object}	NoteLink	"Ok"
+//	@Failure	400	{object}	Problem	"invalid request params"
+//	@Failure	401	{object}	Problem	"unauthorized"
+//	@Failure	403	{object}	Problem	"not the owner of the note"
+//	@Failure	404	{object}	Problem	"not found"
+//	@Failure	500	{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router		/note/{noteID}/link  [get]
+func (hs *Service) handleGetLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewGetLinkAction(store, log)
+	handler := NewGetLinkHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleDeleteLink
+//
+//	@Summary	Revoke public link to note.
+//	@Param		noteID	path	string	true	"ID of note"
+//	@Success	204	"No Content"
+//	@Failure	400	{object}	Problem	"invalid request params"
+//	@Failure	401	{object}	Problem	"unauthorized"
+//	@Failure	403	{object}	Problem	"not the owner of the note"
+//	@Failure	404	{object}	Problem	"not found"
+//	@Failure	500	{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router		/note/{noteID}/link  [delete]
+func (hs *Service) handleDeleteLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewDeleteLinkAction(store, log)
+	handler := NewDeleteLinkHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleListRevisions
+//
+//	@Summary		List note revisions.
+//	@Description	Every create and update of the note stores a revision, newest first.
+//	@Produce		json
+//	@Param			noteID	path	string	true	"ID of note"
+//	@Success		200	{array}		note.Revision	"Ok"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope"
+//	@Failure		404	{object}	Problem	"not found"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/{noteID}/revisions  [get]
+func (hs *Service) handleListRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewListRevisionsAction(store, hs.di.GetUserAdaptor(ctx), log)
+	handler := NewListRevisionsHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleDiffRevisions
+//
+//	@Summary		Unified diff between two revisions.
+//	@Description	Compares label, tags and body line by line. Empty body if revisions are equal.
+//	@Produce		plain
+//	@Param			noteID	path	string	true	"ID of note"
+//	@Param			from	query	int		true	"old revision"
+//	@Param			to		query	int		true	"new revision"
+//	@Success		200	{string}	string	"Ok"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope"
+//	@Failure		404	{object}	Problem	"not found"
+//	@Failure		422	{object}	Problem	"revisions are too large to compare"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/{noteID}/revisions/diff  [get]
+func (hs *Service) handleDiffRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewDiffRevisionsAction(store, log)
+	handler := NewDiffRevisionsHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleRestoreRevision
+//
+//	@Summary		Restore note from revision.
+//	@Description	Copies the old revision into the note and stores it as a new revision.
+//	@Produce		json
+//	@Param			noteID	path	string	true	"ID of note"
+//	@Param			rev		path	int		true	"revision to restore"
+//	@Success		200	{object}	note.Note	"Ok"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope or note permission"
+//	@Failure		404	{object}	Problem	"not found"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/{noteID}/revisions/{rev}/restore  [post]
+func (hs *Service) handleRestoreRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewRestoreRevisionAction(store, log)
+	handler := NewRestoreRevisionHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleListTrash
+//
+//	@Summary		Getting list of notes in the trash.
+//	@Description	Accepts the same parameters as the list of notes except shared. Notes are purged after trash.retention.
+//	@Produce		json
+//	@Param			sortBy		query		string		false	"sort field"	Enums(label, created_at, updated_at)	default(label)
+//	@Param			direction	query		string		false	"sort direction"	Enums(asc, desc)	default(asc)
+//	@Param			limit		query		int			false	"page size, capped by pagination.maxLimit"	minimum(1)	default(20)
+//	@Param			offset		query		int			false	"number of notes to skip, can not be combined with cursor"	minimum(0)
+//	@Param			cursor		query		string		false	"cursor of the neighbour page"
+//	@Param			withTotal	query		bool		false	"count total number of notes, default true without cursor"
+//	@Param			tagsAll		query		[]string	false	"notes having all of the tags"	collectionFormat(multi)
+//	@Param			tagsAny		query		[]string	false	"notes having any of the tags"	collectionFormat(multi)
+//	@Param			tagsNone	query		[]string	false	"notes having none of the tags"	collectionFormat(multi)
+//	@Param			modifiedSince	query	string	false	"notes updated at or after the time, RFC 3339"	format(date-time)
+//	@Param			createdBefore	query	string	false	"notes created before the time, RFC 3339"	format(date-time)
+//	@Param			notebookId	query	string	false	"notes of the notebook"	format(uuid)
+//	@Param			recursive	query	bool	false	"with notebookId, include notes of nested notebooks"
+//	@Param			pinned		query	bool	false	"only pinned or only not pinned notes"
+//	@Param			archived	query	bool	false	"only archived or only not archived notes"
+//	@Param			favorite	query	bool	false	"only favorite or only not favorite notes"
+//	@Success		200			{object}	note.ListNotes			"ok"
+//	@Failure		400		{object}	Problem	"invalid request params"
+//	@Failure		401		{object}	Problem	"unauthorized"
+//	@Failure		403		{object}	Problem	"insufficient scope"
+//	@Failure		404		{object}	Problem	"notebook not found"
+//	@Failure		500		{object}	Problem	"failed during inner process"
+//	@Security	BearerAuth
+//	@Router			/trash  [get]
+func (hs *Service) handleListTrash(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewListAction(store, hs.di.GetCursorCodec(), log)
+	handler := NewListTrashHandler(action, hs.di.GetConfig().Pagination, log)
+
+	handler.Handle(w, r)
+}
+
+// handleSetNoteFlag
+//
+//	@Summary		Pin, archive or add note to favorites.
+//	@Description	Only the owner can change flags. The version of the note grows, the revision history does not. Requires If-Match with the ETag from the last read, * skips the version check.
+//	@Produce		json
+//	@Param			noteID		path	string	true	"ID of note"
+//	@Param			flag		path	string	true	"flag of note"	Enums(pinned, archived, favorite)
+//	@Param			If-Match	header	string	true	"ETag of the note"
+//	@Success		200	{object}	note.Note	"Ok"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope or not the owner"
+//	@Failure		404	{object}	Problem	"not found"
+//	@Failure		412	{object}	Problem	"note was modified"
+//	@Failure		428	{object}	Problem	"If-Match is missing"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/{noteID}/{flag}  [put]
+func (hs *Service) handleSetNoteFlag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewSetFlagAction(store, log)
+	handler := NewSetNoteFlagHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleUnsetNoteFlag
+//
+//	@Summary		Unpin, unarchive or remove note from favorites.
+//	@Description	Only the owner can change flags. The version of the note grows, the revision history does not. Requires If-Match with the ETag from the last read, * skips the version check.
+//	@Produce		json
+//	@Param			noteID		path	string	true	"ID of note"
+//	@Param			flag		path	string	true	"flag of note"	Enums(pinned, archived, favorite)
+//	@Param			If-Match	header	string	true	"ETag of the note"
+//	@Success		200	{object}	note.Note	"Ok"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope or not the owner"
+//	@Failure		404	{object}	Problem	"not found"
+//	@Failure		412	{object}	Problem	"note was modified"
+//	@Failure		428	{object}	Problem	"If-Match is missing"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/{noteID}/{flag}  [delete]
+func (hs *Service) handleUnsetNoteFlag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewSetFlagAction(store, log)
+	handler := NewUnsetNoteFlagHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleRestoreNote
+//
+//	@Summary		Restore note from the trash.
+//	@Description	Shares, public link and revision history come back together with the note.
+//	@Produce		json
+//	@Param			noteID	path	string	true	"ID of note in the trash"
+//	@Success		200	{object}	note.Note	"Ok"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope"
+//	@Failure		404	{object}	Problem	"note is not in the trash"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/trash/{noteID}/restore  [post]
+func (hs *Service) handleRestoreNote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewRestoreAction(store, log)
+	handler := NewRestoreNoteHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handlePurgeNote
+//
+//	@Summary		Delete note permanently.
+//	@Description	Only notes in the trash can be purged. The note can not be restored afterwards.
+//	@Param			noteID	path	string	true	"ID of note in the trash"
+//	@Success		204	"No Content"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope"
+//	@Failure		404	{object}	Problem	"note is not in the trash"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/trash/{noteID}  [delete]
+func (hs *Service) handlePurgeNote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewPurgeAction(store, log)
+	handler := NewPurgeNoteHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleMoveNotes
+//
+//	@Summary		Move notes to a notebook.
+//	@Description	Moves several own notes at once, notebookId null moves them to the root. Unknown IDs and notes already in the notebook are skipped.
+//	@Accept			json
+//	@Produce		json
+//	@Param			move	body	MoveNotesRequest	true	"IDs of notes and the target notebook"
+//	@Success		200	{object}	MoveNotesResponse	"Ok"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope or note permission"
+//	@Failure		404	{object}	Problem	"notebook not found"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/move  [post]
+func (hs *Service) handleMoveNotes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewMoveNotesAction(store, log)
+	handler := NewMoveNotesHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleBatchNotes
+//
+//	@Summary		Apply several note operations at once.
+//	@Description	Runs create, update, delete, tag-add and tag-remove operations in one transaction. By default nothing is saved if any operation fails; with continueOnError the successful operations are saved. Every operation gets its own status and error in results.
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch	body	BatchNotesRequest	true	"Operations applied in order"
+//	@Param			Idempotency-Key	header	string	false	"retries with the same key replay the first response"
+//	@Success		200	{object}	BatchNotesResponse	"Outcome of every operation"
+//	@Failure		400	{object}	Problem	"empty batch, too many operations or invalid body"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope"
+//	@Failure		409	{object}	Problem	"request with this idempotency key is in progress"
+//	@Failure		413	{object}	Problem	"body with idempotency key is too large"
+//	@Failure		422	{object}	Problem	"idempotency key reused with another request"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/note/batch  [post]
+func (hs *Service) handleBatchNotes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewBatchAction(store, hs.di.GetConfig().Batch.MaxOperations, log)
+	handler := NewBatchNotesHandler(action, log)
 
 	handler.Handle(w, r)
 }
 
-func (hs *Service) handleMigration01(w http.ResponseWriter, r *http.Request) {
+// handleCreateNotebook
+//
+//	@Summary		Create notebook.
+//	@Tags			notebooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			notebook	body	RequestNotebook	true	"name and optional parent"
+//	@Success		201	{object}	note.Notebook	"Created"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope"
+//	@Failure		404	{object}	Problem	"parent notebook not found"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/notebooks  [post]
+func (hs *Service) handleCreateNotebook(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewCreateNotebookAction(store, log)
+	handler := NewCreateNotebookHandler(action, log)
 
-	migration := migrations.NewMigration01(ctx, hs.di.GetNoteAdaptor(ctx))
+	handler.Handle(w, r)
+}
+
+// handleListNotebooks
+//
+//	@Summary		Getting list of notebooks.
+//	@Description	All notebooks of the user ordered by name, the tree is built by parentId.
+//	@Tags			notebooks
+//	@Produce		json
+//	@Success		200	{array}		note.Notebook	"Ok"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/notebooks  [get]
+func (hs *Service) handleListNotebooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewListNotebooksAction(store, log)
+	handler := NewListNotebooksHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleGetNotebook
+//
+//	@Summary		Get notebook.
+//	@Tags			notebooks
+//	@Produce		json
+//	@Param			notebookID	path	string	true	"ID of notebook"
+//	@Success		200	{object}	note.Notebook	"Ok"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope"
+//	@Failure		404	{object}	Problem	"notebook not found"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/notebooks/{notebookID}  [get]
+func (hs *Service) handleGetNotebook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewGetNotebookAction(store, log)
+	handler := NewGetNotebookHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleUpdateNotebook
+//
+//	@Summary		Rename or move notebook.
+//	@Description	parentId null moves the notebook to the root. A notebook can not be moved inside itself.
+//	@Tags			notebooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			notebookID	path	string			true	"ID of notebook"
+//	@Param			notebook	body	RequestNotebook	true	"new name and parent"
+//	@Success		200	{object}	note.Notebook	"Ok"
+//	@Failure		400	{object}	Problem	"invalid request params or cycle"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope"
+//	@Failure		404	{object}	Problem	"notebook not found"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/notebooks/{notebookID}  [put]
+func (hs *Service) handleUpdateNotebook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewUpdateNotebookAction(store, log)
+	handler := NewUpdateNotebookHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handleDeleteNotebook
+//
+//	@Summary		Delete notebook.
+//	@Description	notes=move (default) moves nested notebooks and notes to the parent of the deleted notebook.
+//	@Description	notes=trash deletes nested notebooks too and moves all their notes to the trash.
+//	@Tags			notebooks
+//	@Param			notebookID	path	string	true	"ID of notebook"
+//	@Param			notes		query	string	false	"what to do with the content"	Enums(move, trash)	default(move)
+//	@Success		204	"No Content"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		401	{object}	Problem	"unauthorized"
+//	@Failure		403	{object}	Problem	"insufficient scope"
+//	@Failure		404	{object}	Problem	"notebook not found"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Security		BearerAuth
+//	@Router			/notebooks/{notebookID}  [delete]
+func (hs *Service) handleDeleteNotebook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
+
+	action := notes.NewDeleteNotebookAction(store, log)
+	handler := NewDeleteNotebookHandler(action, log)
+
+	handler.Handle(w, r)
+}
+
+// handlePublicNote
+//
+//	@Summary		Open note by public link.
+//	@Description	No authentication. Returns HTML for browsers (Accept: text/html) and JSON otherwise.
+//	@Tags			public
+//	@Produce		json
+//	@Produce		html
+//	@Param			token	path	string	true	"link token"
+//	@Param			format	query	string	false	"response format, overrides Accept"	Enums(json, html)
+//	@Success		200	{object}	PublicNote	"Ok"
+//	@Failure		400	{object}	Problem	"invalid request params"
+//	@Failure		404	{object}	Problem	"link not found or expired"
+//	@Failure		500	{object}	Problem	"failed during inner process"
+//	@Router			/public/{token}  [get]
+func (hs *Service) handlePublicNote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := hs.di.GetLogger()
+	store := hs.di.GetNoteAdaptor(ctx)
 
-	handler := NewMigration01Handler(migration, log)
+	action := notes.NewGetByLinkAction(store, log)
+	handler := NewPublicNoteHandler(action, log)
 
 	handler.Handle(w, r)
 }
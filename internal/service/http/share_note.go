@@ -0,0 +1,115 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/action/notes"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type ShareNoteAction interface {
+	Do(ctx context.Context, ownerID, noteID uuid.UUID, args notes.ShareArgs) (note.Share, error)
+}
+
+type ShareNoteHandler struct {
+	action ShareNoteAction
+	log    *zap.Logger
+}
+
+func NewShareNoteHandler(action ShareNoteAction, log *zap.Logger) *ShareNoteHandler {
+	return &ShareNoteHandler{action: action, log: log}
+}
+
+func (h *ShareNoteHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	var requestParams notes.ShareArgs
+	err = readJSON(r, &requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	share, err := h.action.Do(ctx, userIDFromContext(ctx), id, requestParams)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusCreated, share)
+}
+
+type ListSharesAction interface {
+	Do(ctx context.Context, ownerID, noteID uuid.UUID) ([]note.Share, error)
+}
+
+type ListSharesHandler struct {
+	action ListSharesAction
+	log    *zap.Logger
+}
+
+func NewListSharesHandler(action ListSharesAction, log *zap.Logger) *ListSharesHandler {
+	return &ListSharesHandler{action: action, log: log}
+}
+
+func (h *ListSharesHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	shares, err := h.action.Do(ctx, userIDFromContext(ctx), id)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	writeJSON(w, r, h.log, http.StatusOK, shares)
+}
+
+type UnshareNoteAction interface {
+	Do(ctx context.Context, userID, noteID, granteeID uuid.UUID) error
+}
+
+type UnshareNoteHandler struct {
+	action UnshareNoteAction
+	log    *zap.Logger
+}
+
+func NewUnshareNoteHandler(action UnshareNoteAction, log *zap.Logger) *UnshareNoteHandler {
+	return &UnshareNoteHandler{action: action, log: log}
+}
+
+func (h *UnshareNoteHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	granteeID, err := uuid.FromString(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeError(w, r, h.log, errInvalidParams.WithField("userID", "must be a UUID").WithCause(err))
+		return
+	}
+
+	ctx := r.Context()
+	err = h.action.Do(ctx, userIDFromContext(ctx), id, granteeID)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
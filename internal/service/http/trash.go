@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	uuid "github.com/satori/go.uuid"
+	"github.com/victor8titov/rest-api-notes/internal/entity/note"
+	"go.uber.org/zap"
+)
+
+type RestoreAction interface {
+	Do(ctx context.Context, ownerID, noteID uuid.UUID) (note.Note, error)
+}
+
+type RestoreNoteHandler struct {
+	action RestoreAction
+	log    *zap.Logger
+}
+
+func NewRestoreNoteHandler(action RestoreAction, log *zap.Logger) *RestoreNoteHandler {
+	return &RestoreNoteHandler{action: action, log: log}
+}
+
+func (h *RestoreNoteHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	restored, err := h.action.Do(ctx, userIDFromContext(ctx), id)
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	setNoteHeaders(w, restored)
+	writeJSON(w, r, h.log, http.StatusOK, restored)
+}
+
+type PurgeAction interface {
+	Do(ctx context.Context, ownerID, noteID uuid.UUID) error
+}
+
+type PurgeNoteHandler struct {
+	action PurgeAction
+	log    *zap.Logger
+}
+
+func NewPurgeNoteHandler(action PurgeAction, log *zap.Logger) *PurgeNoteHandler {
+	return &PurgeNoteHandler{action: action, log: log}
+}
+
+func (h *PurgeNoteHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	id, err := parseNoteID(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.action.Do(ctx, userIDFromContext(ctx), id); err != nil {
+		writeError(w, r, h.log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
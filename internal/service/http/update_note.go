@@ -2,12 +2,9 @@ package http
 
 import (
 	"context"
-	"encoding/json"
-	"io"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
-	uuid "github.com/satori/go.uuid"
 	"github.com/victor8titov/rest-api-notes/internal/action/notes"
 	"github.com/victor8titov/rest-api-notes/internal/entity/note"
 	"go.uber.org/zap"
@@ -40,64 +37,41 @@ func (h *UpdateNoteHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	h.log.Debug("hading update note by id", zap.Any("note id", noteID))
 
-	contentType := r.Header.Get("Content-Type")
-	if contentType != "application/json" {
-		h.log.Debug("invalid Content-Type header", zap.Any("contentType", contentType))
-		http.Error(w, "invalid request header", http.StatusBadRequest)
-		return
-	}
-
-	body, err := io.ReadAll(r.Body)
-	defer r.Body.Close()
-
+	var requestParams RequestUpdateNote
+	err := readJSON(r, &requestParams)
 	if err != nil {
-		h.log.Debug("invalid request body", zap.Any("body", body), zap.Error(err))
-		http.Error(w, "invalid request params", http.StatusBadRequest)
+		writeError(w, r, h.log, err)
 		return
 	}
 
-	var requestParams RequestUpdateNote
-	err = json.Unmarshal(body, &requestParams)
+	id, err := parseNoteID(noteID)
 	if err != nil {
-		h.log.Debug("failed unmarshal request body", zap.Any("body", body), zap.Error(err))
-		http.Error(w, "invalid request params", http.StatusBadRequest)
+		writeError(w, r, h.log, err)
 		return
 	}
 
-	id, err := uuid.FromString(noteID)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		h.log.Debug("invalid note id", zap.Any("noteID", noteID), zap.Error(err))
-		http.Error(w, "invalid request params", http.StatusBadRequest)
+		writeError(w, r, h.log, err)
 		return
 	}
 
+	ctx := r.Context()
 	args := notes.UpdateArgs{
-		ID:    id,
-		Label: requestParams.Label,
-		Body:  requestParams.Body,
-		Tags:  requestParams.Tags,
+		UserID:  userIDFromContext(ctx),
+		ID:      id,
+		Label:   requestParams.Label,
+		Body:    requestParams.Body,
+		Tags:    requestParams.Tags,
+		Version: version,
 	}
 
-	ctx := r.Context()
 	updatedNote, err := h.action.Do(ctx, args)
 	if err != nil {
-		h.log.Debug("failed during action doing", zap.Error(err))
-		http.Error(w, "failed during inner process", http.StatusInternalServerError)
+		writeError(w, r, h.log, err)
 		return
 	}
 
-	res, err := json.Marshal(updatedNote)
-	if err != nil {
-		h.log.Debug("failed marshal note", zap.Any("note", updatedNote), zap.Error(err))
-		http.Error(w, "failed during inner process", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	_, err = w.Write(res)
-	if err != nil {
-		h.log.Debug("failed during write response", zap.Error(err))
-		http.Error(w, "failed during creating", http.StatusInternalServerError)
-		return
-	}
+	setNoteHeaders(w, updatedNote)
+	writeJSON(w, r, h.log, http.StatusOK, updatedNote)
 }